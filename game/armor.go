@@ -0,0 +1,37 @@
+package game
+
+import "fmt"
+
+// armorDefense is how much damage armor found or dropped on a level blocks
+// per hit; deeper levels have better armor
+func armorDefense(level int) int {
+	return 1 + level/3
+}
+
+// mitigate reduces damage to a player by the armor they're wearing. Armor
+// never blocks a hit completely: every hit still does at least 1 damage.
+func mitigate(player *Entity, dmg int) int {
+	if player.Defense <= 0 {
+		return dmg
+	}
+	return max(dmg-player.Defense, 1)
+}
+
+// pickUpArmor equips armor the player walks over if it beats what they're
+// wearing. Worse armor is left where it lies. In co-op each player wears
+// their own.
+func (gs *GameState) pickUpArmor(x, y int) {
+	for i, armor := range gs.Armor {
+		if armor.X != x || armor.Y != y {
+			continue
+		}
+		if armor.Defense <= gs.Player.Defense {
+			gs.SetMessage(fmt.Sprintf("Some armor (-%d damage). No better than yours, so you leave it.", armor.Defense))
+			return
+		}
+		gs.Armor = append(gs.Armor[:i], gs.Armor[i+1:]...)
+		gs.Player.Defense = armor.Defense
+		gs.SetMessage(fmt.Sprintf("You put on armor! Hits now do %d less damage.", armor.Defense))
+		return
+	}
+}
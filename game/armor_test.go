@@ -0,0 +1,80 @@
+package game
+
+import "testing"
+
+func TestArmorReducesEnemyDamage(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Enemies = []*Entity{NewMonolith(2, 2)} // 3 damage
+
+	gs.enemyAttacks()
+	unarmored := 20 - gs.Player.HP
+
+	gs.Player.HP = 20
+	gs.Player.Defense = 2
+	gs.enemyAttacks()
+	armored := 20 - gs.Player.HP
+
+	if unarmored != 3 || armored != 1 {
+		t.Errorf("Expected 3 damage without armor and 1 with 2 armor, got %d and %d", unarmored, armored)
+	}
+}
+
+func TestArmorNeverBlocksAllDamage(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.Defense = 5
+	gs.Enemies = []*Entity{NewBug(2, 2)}
+
+	gs.enemyAttacks()
+	if gs.Player.HP != 19 {
+		t.Errorf("Every hit should do at least 1 damage, HP is %d", gs.Player.HP)
+	}
+	for _, dmg := range []int{1, 2, 5, 6} {
+		if got := mitigate(gs.Player, dmg); got < 1 {
+			t.Errorf("mitigate(%d) = %d, below the minimum of 1", dmg, got)
+		}
+	}
+}
+
+func TestArmorReducesMergeConflictDamage(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.Defense = 1
+	gs.MergeMarkerX, gs.MergeMarkerY = 5, 5
+
+	gs.triggerMergeConflict()
+	if gs.Player.HP != 19 {
+		t.Errorf("Armor should cut the merge conflict's 2 damage to 1, HP is %d", gs.Player.HP)
+	}
+}
+
+func TestPickingUpBetterArmorEquipsIt(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Armor = []*Entity{NewArmor(2, 1, 2), NewArmor(3, 1, 1)}
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.Defense != 2 || len(gs.Armor) != 1 {
+		t.Fatalf("Expected to equip the 2-defense armor, got %d with %d left", gs.Player.Defense, len(gs.Armor))
+	}
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.Defense != 2 || len(gs.Armor) != 1 {
+		t.Errorf("Worse armor should be left on the floor, equipped %d with %d left", gs.Player.Defense, len(gs.Armor))
+	}
+}
+
+func TestCoopPlayersWearTheirOwnArmor(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player2 = NewPlayer(1, 3)
+	gs.Armor = []*Entity{NewArmor(2, 3, 2)}
+
+	gs.MovePlayer2(1, 0)
+	if gs.Player2.Defense != 2 || gs.Player.Defense != 0 {
+		t.Fatalf("Expected only player 2 to wear the armor, got %d and %d", gs.Player.Defense, gs.Player2.Defense)
+	}
+
+	gs.Enemies = []*Entity{NewMonolith(2, 2)} // 3 damage, next to both players
+	gs.enemyHits(gs.Enemies[0], gs.Player, "")
+	gs.enemyHits(gs.Enemies[0], gs.Player2, "")
+	if gs.Player.HP != 17 || gs.Player2.HP != 19 {
+		t.Errorf("Expected player 1 to take 3 and armored player 2 to take 1, HP %d and %d", gs.Player.HP, gs.Player2.HP)
+	}
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ParseAvatar validates a custom player symbol, which must be a single printable character
+func ParseAvatar(s string) (rune, error) {
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("avatar must be a single character, got %q", s)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+		return 0, fmt.Errorf("avatar must be a printable character, got %q", s)
+	}
+	return r, nil
+}
+
+// ParseColor validates a player color given as a name (e.g. "green") or hex value (e.g. "#ff8800")
+func ParseColor(s string) (tcell.Color, error) {
+	color := tcell.GetColor(s)
+	if color == tcell.ColorDefault {
+		return color, fmt.Errorf("unknown color %q", s)
+	}
+	return color, nil
+}
+
+// newPlayer creates the player, applying any custom avatar
+func (gs *GameState) newPlayer(x, y int) *Entity {
+	player := NewPlayer(x, y)
+	player.HP = gs.Difficulty.rules().playerHP
+	player.MaxHP = player.HP
+	if gs.Avatar != 0 {
+		player.Symbol = gs.Avatar
+	}
+	return player
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestAvatarFlowsIntoPlayer(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.Avatar = 'λ'
+	})
+	if gs.Player.Symbol != 'λ' {
+		t.Errorf("Expected player symbol 'λ', got %q", gs.Player.Symbol)
+	}
+
+	tutorial := NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.Avatar = 'λ'
+		gs.Tutorial = true
+	})
+	if tutorial.Player.Symbol != 'λ' {
+		t.Errorf("Expected tutorial player symbol 'λ', got %q", tutorial.Player.Symbol)
+	}
+}
+
+func TestDefaultAvatar(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	if gs.Player.Symbol != '@' {
+		t.Errorf("Expected default player symbol '@', got %q", gs.Player.Symbol)
+	}
+}
+
+func TestParseAvatar(t *testing.T) {
+	for _, valid := range []string{"@", "λ", "☺"} {
+		if _, err := ParseAvatar(valid); err != nil {
+			t.Errorf("ParseAvatar(%q) returned error: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "ab", "@@", " ", "\t", "\x07"} {
+		if _, err := ParseAvatar(invalid); err == nil {
+			t.Errorf("ParseAvatar(%q) should be rejected", invalid)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	if c, err := ParseColor("green"); err != nil || c != tcell.ColorGreen {
+		t.Errorf("ParseColor(green) = %v, %v", c, err)
+	}
+	if _, err := ParseColor("#ff8800"); err != nil {
+		t.Errorf("ParseColor(#ff8800) returned error: %v", err)
+	}
+	if _, err := ParseColor("not-a-color"); err == nil {
+		t.Error("Unknown color names should be rejected")
+	}
+}
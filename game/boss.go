@@ -0,0 +1,94 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Boss retreat tuning
+const (
+	BossFleeTurns    = 4 // Turns a badly hurt boss spends running away
+	StackFrameTTL    = 6 // Turns a stack frame stays on the floor
+	StackFrameDamage = 1 // Damage for standing on a stack frame
+)
+
+// StackFrame is one tile of the damaging trail a fleeing boss leaves behind
+type StackFrame struct {
+	X, Y int
+	TTL  int // Turns left before the frame fades
+}
+
+// updateBossFlight starts a boss's one-time retreat once it drops to half
+// health and moves it while fleeing. It reports whether the enemy fled this turn.
+func (gs *GameState) updateBossFlight(enemy *Entity) bool {
+	if enemy.Type != EntityMonolith {
+		return false
+	}
+	if !enemy.HasFled && enemy.HP*2 <= enemy.MaxHP {
+		enemy.HasFled = true
+		enemy.FleeTurns = BossFleeTurns
+		gs.SetMessage("The legacy monolith retreats, spewing a stack trace!")
+	}
+	if enemy.FleeTurns == 0 {
+		return false
+	}
+
+	enemy.FleeTurns--
+	gs.fleeFromPlayer(enemy)
+	return true
+}
+
+// fleeFromPlayer steps an enemy to the neighboring tile farthest from the
+// player, leaving a stack frame where it stood
+func (gs *GameState) fleeFromPlayer(enemy *Entity) {
+	directions := [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+	bestX, bestY := enemy.X, enemy.Y
+	bestDist := enemy.DistanceTo(gs.Player)
+	for _, dir := range directions {
+		if dir[0] != 0 && dir[1] != 0 {
+			if gs.NoDiagonals || gs.cornerBlocked(enemy.X, enemy.Y, dir[0], dir[1]) {
+				continue
+			}
+		}
+		x, y := enemy.X+dir[0], enemy.Y+dir[1]
+		if !gs.canEnemyMoveTo(x, y, enemy) {
+			continue
+		}
+		if dist := max(abs(x-gs.Player.X), abs(y-gs.Player.Y)); dist > bestDist {
+			bestX, bestY, bestDist = x, y, dist
+		}
+	}
+
+	if bestX == enemy.X && bestY == enemy.Y {
+		return
+	}
+	gs.StackTrace = append(gs.StackTrace, StackFrame{X: enemy.X, Y: enemy.Y, TTL: StackFrameTTL})
+	enemy.X, enemy.Y = bestX, bestY
+}
+
+// updateStackTrace burns the player for standing on a stack frame, then ages
+// the trail and drops frames that have faded
+func (gs *GameState) updateStackTrace() {
+	for _, frame := range gs.StackTrace {
+		if frame.X == gs.Player.X && frame.Y == gs.Player.Y && !gs.Invulnerable {
+			gs.hurtPlayer(gs.Player, StackFrameDamage)
+			gs.SetAlert(fmt.Sprintf("You trip over a stack frame - %d HP damage", StackFrameDamage),
+				tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true))
+			if !gs.Player.IsAlive() {
+				gs.KilledBy = "stack_trace"
+			}
+			break
+		}
+	}
+
+	trail := gs.StackTrace[:0]
+	for _, frame := range gs.StackTrace {
+		frame.TTL--
+		if frame.TTL > 0 {
+			trail = append(trail, frame)
+		}
+	}
+	gs.StackTrace = trail
+}
@@ -0,0 +1,98 @@
+package game
+
+import "testing"
+
+func TestBossFleesLeavingStackTrace(t *testing.T) {
+	gs := newTestState(30, 10)
+	gs.Player.X, gs.Player.Y = 5, 5
+	boss := NewMonolith(7, 5)
+	gs.Enemies = []*Entity{boss}
+
+	// Healthy bosses fight
+	gs.moveEnemies()
+	if len(gs.StackTrace) != 0 {
+		t.Fatal("A healthy boss should not flee")
+	}
+
+	boss.X, boss.Y = 7, 5
+	boss.HP = boss.MaxHP / 2
+	gs.moveEnemies()
+
+	if boss.X != 8 {
+		t.Errorf("Hurt boss should flee away from the player, got x=%d", boss.X)
+	}
+	if len(gs.StackTrace) != 1 || gs.StackTrace[0].X != 7 || gs.StackTrace[0].Y != 5 {
+		t.Fatalf("Fleeing should leave a stack frame where the boss stood, got %v", gs.StackTrace)
+	}
+
+	// The retreat lasts BossFleeTurns turns, then it turns to fight
+	for i := 1; i < BossFleeTurns; i++ {
+		gs.moveEnemies()
+	}
+	if len(gs.StackTrace) != BossFleeTurns {
+		t.Errorf("Expected %d stack frames, got %d", BossFleeTurns, len(gs.StackTrace))
+	}
+	x := boss.X
+	gs.moveEnemies()
+	if boss.X != x-1 {
+		t.Errorf("After fleeing the boss should chase the player again, moved from x=%d to x=%d", x, boss.X)
+	}
+	if !boss.HasFled || boss.FleeTurns != 0 {
+		t.Error("The boss should only flee once")
+	}
+}
+
+func TestStackTraceExpires(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.StackTrace = []StackFrame{{X: 10, Y: 5, TTL: 2}, {X: 11, Y: 5, TTL: StackFrameTTL}}
+
+	gs.updateStackTrace()
+	if len(gs.StackTrace) != 2 {
+		t.Fatalf("Frame with TTL 2 should survive one turn, got %v", gs.StackTrace)
+	}
+	gs.updateStackTrace()
+	if len(gs.StackTrace) != 1 || gs.StackTrace[0].X != 11 {
+		t.Fatalf("Expired frame should be removed, got %v", gs.StackTrace)
+	}
+	for i := 2; i < StackFrameTTL; i++ {
+		gs.updateStackTrace()
+	}
+	if len(gs.StackTrace) != 0 {
+		t.Errorf("All frames should expire after StackFrameTTL turns, got %v", gs.StackTrace)
+	}
+}
+
+func TestStackTraceDamagesPlayer(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.StackTrace = []StackFrame{{X: 2, Y: 1, TTL: StackFrameTTL}}
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.HP != gs.Player.MaxHP-StackFrameDamage {
+		t.Errorf("Stepping on a stack frame should deal %d damage, HP is %d", StackFrameDamage, gs.Player.HP)
+	}
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.HP != gs.Player.MaxHP-StackFrameDamage {
+		t.Errorf("Stepping off the trail should not deal more damage, HP is %d", gs.Player.HP)
+	}
+}
+
+func TestBossGuardsFinalLevel(t *testing.T) {
+	bosses := func(gs *GameState) int {
+		n := 0
+		for _, e := range gs.Enemies {
+			if e.Type == EntityMonolith {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := bosses(NewGameState(nil, 12345, 80, 40)); n != 0 {
+		t.Errorf("Level 1 should have no boss, got %d", n)
+	}
+	final := NewGameState(nil, 12345, 80, 40, func(gs *GameState) { gs.Level = gs.MaxLevel })
+	if n := bosses(final); n != 1 {
+		t.Errorf("Final level should have one boss, got %d", n)
+	}
+}
@@ -0,0 +1,61 @@
+package game
+
+import "fmt"
+
+// Browser tests fight from a distance. One that can see a player within
+// BrowserTestRange takes a shot at them every turn, though
+// BrowserTestMissChance of its shots miss. It keeps BrowserTestKeepAway tiles
+// between itself and the player, backing off when they close in.
+const (
+	BrowserTestChance     = 0.05 // Chance per enemy slot, taken from bugs
+	BrowserTestRange      = 4
+	BrowserTestKeepAway   = 2
+	BrowserTestMissChance = 0.4
+)
+
+// kiteBrowserTest keeps a browser test at shooting distance from the target
+// it can see: it backs away from a target that has come too close and holds
+// its ground once in range. It reports false when the target is out of range,
+// so the browser test closes in like any other enemy.
+func (gs *GameState) kiteBrowserTest(enemy, target *Entity) bool {
+	dist := enemy.DistanceTo(target)
+	if dist > BrowserTestRange {
+		return false
+	}
+	if dist >= BrowserTestKeepAway {
+		return true
+	}
+
+	// Back off to whichever free tile is farthest from the target
+	bestX, bestY := enemy.X, enemy.Y
+	for _, dir := range [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+		if dir[0] != 0 && dir[1] != 0 {
+			if gs.NoDiagonals || gs.cornerBlocked(enemy.X, enemy.Y, dir[0], dir[1]) {
+				continue
+			}
+		}
+		x, y := enemy.X+dir[0], enemy.Y+dir[1]
+		if !gs.canEnemyMoveTo(x, y, enemy) {
+			continue
+		}
+		if d := (&Entity{X: x, Y: y}).DistanceTo(target); d > dist {
+			bestX, bestY, dist = x, y, d
+		}
+	}
+	enemy.X, enemy.Y = bestX, bestY
+	return true
+}
+
+// browserTestShoots has a browser test fire at the nearest player it can see
+// within range. Flaky as it is, the shot may miss.
+func (gs *GameState) browserTestShoots(enemy *Entity) {
+	target := gs.chaseTarget(enemy)
+	if target == nil || enemy.DistanceTo(target) > BrowserTestRange {
+		return
+	}
+	if gs.RNG.Float32() < BrowserTestMissChance {
+		gs.SetMessage("A browser test fired at you and timed out. Works on my machine!")
+		return
+	}
+	gs.enemyHits(enemy, target, fmt.Sprintf("A %s failed on you from afar", enemy.Name()))
+}
@@ -0,0 +1,68 @@
+package game
+
+import "testing"
+
+func TestBrowserTestShootsFromTwoTilesAway(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 5, 5
+	browser := NewBrowserTest(7, 5)
+	gs.Enemies = []*Entity{browser}
+
+	hits, misses := 0, 0
+	for turn := 0; turn < 20; turn++ {
+		before := gs.Player.HP
+		gs.enemyAttacks()
+		if gs.Player.HP < before {
+			hits++
+		} else {
+			misses++
+		}
+		gs.Player.HP = gs.Player.MaxHP
+	}
+	if hits == 0 {
+		t.Fatal("A browser test two tiles away along a clear line should hit the player")
+	}
+	if misses == 0 {
+		t.Error("A browser test should miss some of its shots")
+	}
+	if gs.KilledBy != "" {
+		t.Errorf("The player shouldn't have died, killed by %q", gs.KilledBy)
+	}
+}
+
+func TestBrowserTestCantShootThroughWalls(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 5, 5
+	gs.Dungeon.Tiles[5][6] = TileWall
+	gs.Enemies = []*Entity{NewBrowserTest(7, 5)}
+
+	for turn := 0; turn < 20; turn++ {
+		gs.enemyAttacks()
+	}
+	if gs.Player.HP != gs.Player.MaxHP {
+		t.Errorf("A browser test behind a wall shouldn't hit the player, HP %d of %d", gs.Player.HP, gs.Player.MaxHP)
+	}
+}
+
+func TestBrowserTestKeepsItsDistance(t *testing.T) {
+	cases := []struct {
+		name     string
+		startX   int
+		wantDist int
+	}{
+		{"out of range closes in", 15, 9},
+		{"in range holds its ground", 8, 3},
+		{"too close backs off", 6, BrowserTestKeepAway},
+	}
+	for _, c := range cases {
+		gs := newTestState(20, 10)
+		gs.Player.X, gs.Player.Y = 5, 5
+		browser := NewBrowserTest(c.startX, 5)
+		gs.Enemies = []*Entity{browser}
+
+		gs.moveEnemies()
+		if dist := browser.DistanceTo(gs.Player); dist != c.wantDist {
+			t.Errorf("%s: browser test ended %d tiles from the player, want %d", c.name, dist, c.wantDist)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package game
+
+import "github.com/gdamore/tcell/v2"
+
+// CI trap: a one-shot tile that reruns the pipeline when stepped on. Every
+// enemy is shuffled to a new spot on the level and the build logs scrolling
+// past leave the player half blind for a moment.
+const (
+	CITrapChance       = 0.3 // Chance a level has a CI trap
+	CIBlindTurns       = 1   // Turns the player's vision stays cut after springing the trap
+	CIBlindRadius      = 1   // How far the player sees while blinded
+	CIMinEnemyDistance = 3   // Shuffled enemies never land closer than this to a player
+)
+
+// placeCITrap hides a CI trap on a random floor tile of some levels, never
+// under a player or on the stairs. With CI traps off it draws nothing from
+// the RNG, so the rest of the level is the same as it always was.
+func (gs *GameState) placeCITrap() {
+	if !gs.CITraps || gs.RNG.Float64() >= CITrapChance {
+		return
+	}
+	x, y := gs.randomFloorTile()
+	if gs.Dungeon.Tiles[y][x] == TileFloor && gs.canPlaceAt(x, y) {
+		gs.Dungeon.Tiles[y][x] = TileCI
+	}
+}
+
+// triggerCI springs the CI trap at (x, y): it turns back into floor, every
+// enemy is shuffled and the player is blinded
+func (gs *GameState) triggerCI(x, y int) {
+	gs.Dungeon.Tiles[y][x] = TileFloor
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() {
+			gs.shuffleEnemy(enemy)
+		}
+	}
+	gs.BlindTurns = CIBlindTurns
+	gs.SetAlert("CI rerun! The enemies have been reshuffled and the build logs blind you.",
+		tcell.StyleDefault.Foreground(tcell.ColorAqua).Background(tcell.ColorBlack).Bold(true))
+}
+
+// shuffleEnemy moves an enemy to a random free floor tile it isn't already
+// on, at least CIMinEnemyDistance from every player. It stays put if no such
+// tile turns up.
+func (gs *GameState) shuffleEnemy(enemy *Entity) {
+	for attempts := 0; attempts < 100; attempts++ {
+		x, y := gs.randomFloorTile()
+		if (x == enemy.X && y == enemy.Y) || !gs.canPlaceAt(x, y) || gs.enemyAt(x, y) != nil {
+			continue
+		}
+		if gs.nearPlayer(x, y, CIMinEnemyDistance) {
+			continue
+		}
+		enemy.X, enemy.Y = x, y
+		return
+	}
+}
+
+// nearPlayer reports whether (x, y) is within distance tiles of a living player
+func (gs *GameState) nearPlayer(x, y, distance int) bool {
+	for _, p := range gs.Players() {
+		if p.IsAlive() && max(abs(p.X-x), abs(p.Y-y)) < distance {
+			return true
+		}
+	}
+	return false
+}
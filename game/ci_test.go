@@ -0,0 +1,106 @@
+package game
+
+import "testing"
+
+// newCIState builds a level with two rooms for the enemies to be shuffled around
+func newCIState() *GameState {
+	gs := newTestState(30, 12)
+	gs.Dungeon.Rooms = []*Room{{X: 1, Y: 1, W: 12, H: 10}, {X: 16, Y: 1, W: 12, H: 10}}
+	gs.Dungeon.Tiles[1][2] = TileCI
+	return gs
+}
+
+func TestCITrapShufflesEnemies(t *testing.T) {
+	gs := newCIState()
+	gs.Enemies = []*Entity{NewBug(20, 5), NewScopeCreep(24, 8), NewFlakyTest(5, 9)}
+	var before [][2]int
+	for _, enemy := range gs.Enemies {
+		before = append(before, [2]int{enemy.X, enemy.Y})
+	}
+
+	gs.triggerCI(2, 1)
+	seen := make(map[[2]int]bool)
+	for i, enemy := range gs.Enemies {
+		pos := [2]int{enemy.X, enemy.Y}
+		if pos == before[i] {
+			t.Errorf("Enemy %d wasn't moved from (%d, %d)", i, enemy.X, enemy.Y)
+		}
+		if !gs.Dungeon.IsWalkable(enemy.X, enemy.Y) {
+			t.Errorf("Enemy %d landed on an unwalkable tile at (%d, %d)", i, enemy.X, enemy.Y)
+		}
+		if seen[pos] {
+			t.Errorf("Two enemies landed on (%d, %d)", enemy.X, enemy.Y)
+		}
+		seen[pos] = true
+		if enemy.DistanceTo(gs.Player) < CIMinEnemyDistance {
+			t.Errorf("Enemy %d landed %d tiles from the player", i, enemy.DistanceTo(gs.Player))
+		}
+	}
+}
+
+func TestCITrapBlindsPlayerForATurn(t *testing.T) {
+	gs := newCIState()
+	gs.MovePlayer(1, 0)
+
+	if gs.Dungeon.Tiles[1][2] != TileFloor {
+		t.Error("The CI trap should be used up once sprung")
+	}
+	if !gs.Visible[1][2+CIBlindRadius] {
+		t.Error("The player should still see within the blind radius")
+	}
+	if gs.Visible[1][2+CIBlindRadius+1] {
+		t.Error("Vision should be cut to the blind radius on the turn the trap is sprung")
+	}
+
+	gs.MovePlayer(1, 0)
+	if !gs.Visible[1][3+DefaultVisionRadius] {
+		t.Error("Vision should return to normal the turn after")
+	}
+}
+
+func TestCITrapsOnlyWithOption(t *testing.T) {
+	traps := 0
+	for seed := int64(1); seed <= 30; seed++ {
+		if countCITraps(NewGameState(nil, seed, 80, 40).Dungeon) > 0 {
+			t.Fatalf("Seed %d hid a CI trap without --ci-traps", seed)
+		}
+
+		gs := NewGameState(nil, seed, 80, 40, func(gs *GameState) { gs.CITraps = true })
+		traps += countCITraps(gs.Dungeon)
+		if gs.Dungeon.Tiles[gs.Player.Y][gs.Player.X] == TileCI {
+			t.Errorf("Seed %d hid the CI trap on the player's start tile", seed)
+		}
+		if gs.Dungeon.Tiles[gs.DoorY][gs.DoorX] != TileDoor {
+			t.Errorf("Seed %d covered the stairs", seed)
+		}
+	}
+	if traps == 0 {
+		t.Error("Expected some CI traps across 30 seeds with --ci-traps")
+	}
+}
+
+func TestCITrapAvoidsStartAndStairs(t *testing.T) {
+	gs := newTestState(5, 5)
+	gs.CITraps = true
+	gs.Dungeon.Rooms = []*Room{{X: 1, Y: 1, W: 1, H: 1}}
+	gs.DoorX, gs.DoorY = 2, 2 // Where randomFloorTile falls back to once the room is full
+	for range 20 {
+		gs.placeCITrap()
+	}
+	if n := countCITraps(gs.Dungeon); n != 0 {
+		t.Errorf("With only the start tile and the stairs free, no CI trap should be hidden, got %d", n)
+	}
+}
+
+// countCITraps counts the CI trap tiles on a level
+func countCITraps(d *Dungeon) int {
+	count := 0
+	for y := range d.Tiles {
+		for x := range d.Tiles[y] {
+			if d.Tiles[y][x] == TileCI {
+				count++
+			}
+		}
+	}
+	return count
+}
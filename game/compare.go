@@ -0,0 +1,63 @@
+package game
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// CompareGap is the number of blank columns between the two dungeons in a comparison
+const CompareGap = 3
+
+// Default output size for --compare: two headless screens side by side, plus a label row
+const (
+	CompareWidth  = 2*HeadlessWidth + CompareGap
+	CompareHeight = HeadlessHeight + 1
+)
+
+// comparePanels splits width into two equal panels separated by CompareGap,
+// returning each panel's width and the column the right panel starts at
+func comparePanels(width int) (panelWidth, rightX int) {
+	panelWidth = max((width-CompareGap)/2, 0)
+	return panelWidth, panelWidth + CompareGap
+}
+
+// CompareSeeds renders the first level of two seeds side by side, with the
+// whole map revealed, and writes it to w as plain text. Each dungeon is drawn
+// by the game's own renderer into its panel, under a label with its seed.
+func CompareSeeds(w io.Writer, seedA, seedB int64, width, height int, opts ...StateOption) error {
+	panelWidth, rightX := comparePanels(width)
+	panelHeight := height - 1 // The top row holds the labels
+	if panelWidth < MinDungeonWidth || panelHeight-3 < MinDungeonHeight {
+		return fmt.Errorf("comparing needs at least %dx%d to fit two dungeons, got %dx%d",
+			2*MinDungeonWidth+CompareGap, MinDungeonHeight+4, width, height)
+	}
+
+	frame := newFrameBuffer(width, height)
+	for i, seed := range []int64{seedA, seedB} {
+		left := 0
+		if i == 1 {
+			left = rightX
+		}
+
+		gs := NewGameState(nil, seed, panelWidth, panelHeight, opts...)
+		gs.RevealMap = true
+		gs.updateVisibility()
+		g := &Game{state: gs, frame: newFrameBuffer(panelWidth, panelHeight)}
+		g.draw(panelWidth, panelHeight)
+
+		for j, ch := range fmt.Sprintf("Seed %d", seed) {
+			if j < panelWidth {
+				frame.SetContent(left+j, 0, ch, nil, tcell.StyleDefault)
+			}
+		}
+		for y := 0; y < panelHeight; y++ {
+			for x := 0; x < panelWidth; x++ {
+				ch, _, style, _ := g.frame.GetContent(x, y)
+				frame.SetContent(left+x, y+1, ch, nil, style)
+			}
+		}
+	}
+	return writeFrame(w, frame)
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestComparePanelsFitAndDontOverlap(t *testing.T) {
+	for _, width := range []int{83, 100, CompareWidth, 200, 201} {
+		panelWidth, rightX := comparePanels(width)
+		if rightX < panelWidth+CompareGap {
+			t.Errorf("width %d: right panel at %d overlaps the left panel (width %d) and gap", width, rightX, panelWidth)
+		}
+		if rightX+panelWidth > width {
+			t.Errorf("width %d: right panel ends at %d, past the terminal", width, rightX+panelWidth)
+		}
+	}
+}
+
+func TestCompareSeedsDrawsBothDungeons(t *testing.T) {
+	var out bytes.Buffer
+	if err := CompareSeeds(&out, 1, 2, CompareWidth, CompareHeight); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) > CompareHeight {
+		t.Errorf("Expected at most %d lines, got %d", CompareHeight, len(lines))
+	}
+	_, rightX := comparePanels(CompareWidth)
+	if !strings.HasPrefix(lines[0], "Seed 1") || !strings.HasPrefix(lines[0][rightX:], "Seed 2") {
+		t.Errorf("Expected each panel labelled with its seed, got %q", lines[0])
+	}
+	for i, line := range lines {
+		if n := utf8.RuneCountInString(line); n > CompareWidth {
+			t.Errorf("Line %d is %d wide, past the %d column output", i, n, CompareWidth)
+		}
+	}
+	if strings.Count(out.String(), "@") != 2 {
+		t.Error("Expected a player in each dungeon")
+	}
+}
+
+func TestCompareSeedsRejectsNarrowTerminal(t *testing.T) {
+	var out bytes.Buffer
+	if err := CompareSeeds(&out, 1, 2, HeadlessWidth, CompareHeight); err == nil {
+		t.Error("Expected an error when two dungeons can't fit side by side")
+	}
+}
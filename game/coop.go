@@ -0,0 +1,151 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Player2Symbol is how the second player is drawn in pair programming mode
+const Player2Symbol = '&'
+
+// player2Keys maps the second player's keys to directions
+var player2Keys = map[rune][2]int{'w': {0, -1}, 'a': {-1, 0}, 's': {0, 1}, 'd': {1, 0}}
+
+// Players returns the player and, in pair programming mode, the second player
+func (gs *GameState) Players() []*Entity {
+	if gs.Player2 == nil {
+		return []*Entity{gs.Player}
+	}
+	return []*Entity{gs.Player, gs.Player2}
+}
+
+// livingPlayers returns the players still standing, so upgrades and heals
+// don't bring a fallen partner back
+func (gs *GameState) livingPlayers() []*Entity {
+	var living []*Entity
+	for _, p := range gs.Players() {
+		if p.IsAlive() {
+			living = append(living, p)
+		}
+	}
+	return living
+}
+
+// playersDown reports whether every player has died, which ends the run
+func (gs *GameState) playersDown() bool {
+	for _, p := range gs.Players() {
+		if p.IsAlive() {
+			return false
+		}
+	}
+	return true
+}
+
+// otherPlayer returns whichever player isn't p, or nil when playing solo
+func (gs *GameState) otherPlayer(p *Entity) *Entity {
+	switch p {
+	case gs.Player:
+		return gs.Player2
+	case gs.Player2:
+		return gs.Player
+	}
+	return nil
+}
+
+// livingPlayerAt returns the living player standing on (x, y), or nil
+func (gs *GameState) livingPlayerAt(x, y int) *Entity {
+	for _, p := range gs.Players() {
+		if p != nil && p.IsAlive() && p.X == x && p.Y == y {
+			return p
+		}
+	}
+	return nil
+}
+
+// placePlayer2 puts the second player next to the first at the start of a level
+func (gs *GameState) placePlayer2() {
+	if gs.Player2 == nil {
+		gs.Player2 = gs.newPlayer(gs.Player.X, gs.Player.Y)
+		gs.Player2.Symbol = Player2Symbol
+	}
+	for _, dir := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {-1, 1}, {1, -1}, {-1, -1}} {
+		x, y := gs.Player.X+dir[0], gs.Player.Y+dir[1]
+		if gs.Dungeon.IsWalkable(x, y) {
+			gs.Player2.X, gs.Player2.Y = x, y
+			return
+		}
+	}
+	gs.Player2.X, gs.Player2.Y = gs.Player.X, gs.Player.Y
+}
+
+// MovePlayer2 moves the second player. Everything a move does (bump attacks,
+// pickups, hazards, the enemy turn) works on gs.Player, so the two players
+// trade places in the state for the length of the move.
+func (gs *GameState) MovePlayer2(dx, dy int) {
+	if gs.Player2 == nil || !gs.Player2.IsAlive() {
+		return
+	}
+	gs.Player, gs.Player2 = gs.Player2, gs.Player
+	defer func() { gs.Player, gs.Player2 = gs.Player2, gs.Player }()
+	gs.MovePlayer(dx, dy)
+}
+
+// meetAtDoor handles the player moving onto their pair at (x, y). The first
+// player to reach the door waits on it; the level is left when the second
+// joins them. Any other step onto the pair is blocked. Returns true if the
+// move was handled.
+func (gs *GameState) meetAtDoor(x, y int) bool {
+	pair := gs.otherPlayer(gs.Player)
+	if pair == nil || !pair.IsAlive() || pair.X != x || pair.Y != y {
+		return false
+	}
+	if x == gs.DoorX && y == gs.DoorY {
+		gs.Player.X, gs.Player.Y = x, y
+		gs.takeDoor()
+	}
+	return true
+}
+
+// waitAtDoor reports whether the player just stepped onto the door ahead of a
+// living pair, who must reach it too before the level can be left
+func (gs *GameState) waitAtDoor() bool {
+	pair := gs.otherPlayer(gs.Player)
+	if pair == nil || !pair.IsAlive() {
+		return false
+	}
+	gs.SetMessage("Waiting at the door for your pair. Both of you need to get here!")
+	return true
+}
+
+// chaseTarget picks the player an enemy goes after: the nearest one it can see
+func (gs *GameState) chaseTarget(enemy *Entity) *Entity {
+	var target *Entity
+	for _, p := range gs.Players() {
+		if !p.IsAlive() || !gs.enemyCanSeePlayer(enemy, p) {
+			continue
+		}
+		if target == nil || enemy.DistanceTo(p) < enemy.DistanceTo(target) {
+			target = p
+		}
+	}
+	return target
+}
+
+// nearestPlayer returns the living player closest to an enemy, falling back
+// to the first player if everyone is down
+func (gs *GameState) nearestPlayer(enemy *Entity) *Entity {
+	nearest := gs.Player
+	for _, p := range gs.Players() {
+		if p.IsAlive() && (!nearest.IsAlive() || enemy.DistanceTo(p) < enemy.DistanceTo(nearest)) {
+			nearest = p
+		}
+	}
+	return nearest
+}
+
+// announcePlayerDown tells the survivor their pair has died
+func (gs *GameState) announcePlayerDown(killer *Entity) {
+	gs.SetAlert(fmt.Sprintf("A %s took down your pair! You're on your own.", killer.Name()),
+		tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true))
+}
@@ -0,0 +1,146 @@
+package game
+
+import "testing"
+
+func newCoopTestState() *GameState {
+	gs := newTestState(20, 10)
+	gs.Coop = true
+	gs.Player2 = NewPlayer(5, 5)
+	gs.Player2.Symbol = Player2Symbol
+	return gs
+}
+
+func TestCoopPlayersMoveIndependently(t *testing.T) {
+	gs := newCoopTestState()
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.X != 2 || gs.Player.Y != 1 {
+		t.Errorf("Player 1 should move to (2,1), got (%d,%d)", gs.Player.X, gs.Player.Y)
+	}
+	if gs.Player2.X != 5 || gs.Player2.Y != 5 {
+		t.Errorf("Player 2 shouldn't move with player 1, got (%d,%d)", gs.Player2.X, gs.Player2.Y)
+	}
+
+	gs.MovePlayer2(0, 1)
+	if gs.Player2.X != 5 || gs.Player2.Y != 6 {
+		t.Errorf("Player 2 should move to (5,6), got (%d,%d)", gs.Player2.X, gs.Player2.Y)
+	}
+	if gs.Player.X != 2 || gs.Player.Y != 1 {
+		t.Errorf("Player 1 shouldn't move with player 2, got (%d,%d)", gs.Player.X, gs.Player.Y)
+	}
+	if gs.Player2.Symbol != Player2Symbol {
+		t.Error("The players should be back in their own slots after player 2's move")
+	}
+}
+
+func TestCoopKeysRouteToEachPlayer(t *testing.T) {
+	g := &Game{state: newCoopTestState()}
+
+	g.handleKey(runeKey('d'))
+	if g.state.Player2.X != 6 || g.state.Player.X != 1 {
+		t.Errorf("d should move only player 2, players at x=%d and x=%d", g.state.Player.X, g.state.Player2.X)
+	}
+	g.handleKey(runeKey('l'))
+	if g.state.Player.X != 2 || g.state.Player2.X != 6 {
+		t.Errorf("l should move only player 1, players at x=%d and x=%d", g.state.Player.X, g.state.Player2.X)
+	}
+}
+
+func TestCoopPlayersBlockEachOther(t *testing.T) {
+	gs := newCoopTestState()
+	gs.Player2.X, gs.Player2.Y = 2, 1
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.X != 1 {
+		t.Errorf("Player 1 shouldn't walk into player 2, got x=%d", gs.Player.X)
+	}
+}
+
+func TestCoopEnemiesChaseNearestPlayer(t *testing.T) {
+	gs := newCoopTestState()
+	gs.Player.X, gs.Player.Y = 1, 1
+	gs.Player2.X, gs.Player2.Y = 15, 5
+	enemy := NewScopeCreep(12, 5)
+	gs.Enemies = []*Entity{enemy}
+
+	gs.moveEnemies()
+	if enemy.X != 13 {
+		t.Errorf("Enemy should step toward the nearer player 2, got (%d,%d)", enemy.X, enemy.Y)
+	}
+}
+
+func TestCoopVisibilityCoversBothPlayers(t *testing.T) {
+	gs := newCoopTestState()
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+	gs.Player.X, gs.Player.Y = 3, 5
+	gs.Player2.X, gs.Player2.Y = 15, 5
+
+	gs.updateVisibility()
+	if !gs.Visible[5][3] || !gs.Visible[5][16] {
+		t.Error("Tiles around both players should be visible")
+	}
+
+	gs.Player2 = nil
+	gs.updateVisibility()
+	if gs.Visible[5][16] {
+		t.Error("Solo, the far side of the wall should be out of sight")
+	}
+}
+
+func TestCoopDeadPlayerSightIsLost(t *testing.T) {
+	gs := newCoopTestState()
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+	gs.Player.X, gs.Player.Y = 3, 5
+	gs.Player2.X, gs.Player2.Y = 15, 5
+	gs.Player2.HP = 0
+
+	gs.updateVisibility()
+	if gs.Visible[5][16] {
+		t.Error("A dead player shouldn't keep lighting up the map")
+	}
+}
+
+func TestCoopRunEndsWhenBothPlayersDie(t *testing.T) {
+	gs := newCoopTestState()
+	gs.Player2.X, gs.Player2.Y = 3, 1
+	gs.Player2.HP = 1
+	gs.Enemies = []*Entity{NewMonolith(3, 2)}
+
+	gs.MovePlayer(0, 1)
+	if gs.Player2.IsAlive() {
+		t.Fatal("Player 2 should have been killed")
+	}
+	if gs.GameOver {
+		t.Fatal("The run should continue while player 1 is alive")
+	}
+
+	gs.Player.HP = 1
+	gs.MovePlayer(1, 0)
+	if !gs.GameOver {
+		t.Error("The run should end once both players are down")
+	}
+}
+
+func TestCoopDoorNeedsBothPlayers(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) { gs.Coop = true })
+	if gs.Player2 == nil {
+		t.Fatal("Co-op should place a second player")
+	}
+	gs.Enemies = nil
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.Player2.X, gs.Player2.Y = gs.DoorX-1, gs.DoorY-1
+
+	gs.MovePlayer(1, 0)
+	if gs.Level != 1 || gs.Player.X != gs.DoorX {
+		t.Fatalf("Player 1 should wait on the door, level %d at (%d,%d)", gs.Level, gs.Player.X, gs.Player.Y)
+	}
+
+	gs.Player2.X, gs.Player2.Y = gs.DoorX-1, gs.DoorY
+	gs.MovePlayer2(1, 0)
+	if gs.Level != 2 {
+		t.Errorf("Both players reaching the door should descend, got level %d", gs.Level)
+	}
+	if !gs.Player2.IsAdjacent(gs.Player) {
+		t.Error("Player 2 should start the new level next to player 1")
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import "unicode"
+
+// Reading code: every ReadingRevealEvery code characters the player walks
+// over reveal the map within ReadingRevealRadius, as if the player now
+// understands that part of the code
+const (
+	ReadingRevealEvery  = 10
+	ReadingRevealRadius = 10
+)
+
+// readCode counts the code character under (x, y) toward the run's reading
+// progress, revealing more of the map each time a threshold is reached.
+// Blank floor and whitespace don't count.
+func (gs *GameState) readCode(x, y int) {
+	if gs.Dungeon.Tiles[y][x] != TileFloor {
+		return
+	}
+	ch := gs.Dungeon.FloorChar(x, y)
+	if ch == '.' || unicode.IsSpace(ch) {
+		return
+	}
+
+	gs.CodeRead++
+	if gs.CodeRead%ReadingRevealEvery == 0 {
+		gs.revealCoverage(x, y)
+		if gs.Message == "" {
+			gs.SetMessage("You read the code. The dungeon around it makes more sense now.")
+		}
+	}
+}
+
+// revealCoverage marks the walkable tiles within ReadingRevealRadius of
+// (x, y) as explored, along with the walls that outline them
+func (gs *GameState) revealCoverage(cx, cy int) {
+	for y := max(cy-ReadingRevealRadius, 0); y <= min(cy+ReadingRevealRadius, gs.Dungeon.Height-1); y++ {
+		for x := max(cx-ReadingRevealRadius, 0); x <= min(cx+ReadingRevealRadius, gs.Dungeon.Width-1); x++ {
+			if gs.Dungeon.IsWalkable(x, y) || gs.bordersWalkable(x, y) {
+				gs.Explored[y][x] = true
+			}
+		}
+	}
+}
+
+// bordersWalkable reports whether any of the 8 tiles around (x, y) is walkable
+func (gs *GameState) bordersWalkable(x, y int) bool {
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if (dx != 0 || dy != 0) && gs.Dungeon.IsWalkable(x+dx, y+dy) {
+				return true
+			}
+		}
+	}
+	return false
+}
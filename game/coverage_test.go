@@ -0,0 +1,47 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func newCodeFloorState(line string) *GameState {
+	gs := newTestState(60, 30)
+	gs.Dungeon.CodeFile = &CodeFile{Path: "main.go", Lines: []string{line}}
+	return gs
+}
+
+func TestReadingCodeRevealsAtThreshold(t *testing.T) {
+	gs := newCodeFloorState(strings.Repeat("x", 40))
+
+	for i := 1; i < ReadingRevealEvery; i++ {
+		gs.MovePlayer(1, 0)
+	}
+	if gs.CodeRead != ReadingRevealEvery-1 {
+		t.Fatalf("Expected %d code characters read, got %d", ReadingRevealEvery-1, gs.CodeRead)
+	}
+	// Beyond the vision radius but within the reveal radius of the next step
+	farX, farY := gs.Player.X+1, gs.Player.Y+ReadingRevealRadius
+	if gs.Explored[farY][farX] {
+		t.Fatal("Tile shouldn't be explored before the reading threshold")
+	}
+
+	gs.MovePlayer(1, 0)
+	if gs.CodeRead != ReadingRevealEvery {
+		t.Errorf("Expected %d code characters read, got %d", ReadingRevealEvery, gs.CodeRead)
+	}
+	if !gs.Explored[farY][farX] {
+		t.Error("Reaching the reading threshold should reveal tiles within the reveal radius")
+	}
+}
+
+func TestReadingSkipsBlankFloor(t *testing.T) {
+	gs := newCodeFloorState("    ")
+
+	for i := 0; i < ReadingRevealEvery; i++ {
+		gs.MovePlayer(1, 0)
+	}
+	if gs.CodeRead != 0 {
+		t.Errorf("Whitespace and empty floor shouldn't count as reading, got %d", gs.CodeRead)
+	}
+}
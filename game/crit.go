@@ -0,0 +1,32 @@
+package game
+
+import "github.com/gdamore/tcell/v2"
+
+// DefaultCritChance is the chance one of the player's melee attacks is a
+// critical hit, dealing double damage
+const DefaultCritChance = 0.1
+
+// CritMessage is shown ahead of the message for an attack that crit
+const CritMessage = "Critical hit!"
+
+// critStyle is how messages about critical hits are drawn
+var critStyle = tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack).Bold(true)
+
+// meleeDamage rolls the damage of one of the player's melee attacks:
+// PlayerDamage, doubled on a critical hit. Reports whether it crit.
+func (gs *GameState) meleeDamage() (int, bool) {
+	dmg := gs.PlayerDamage()
+	if gs.CritChance > 0 && gs.RNG.Float64() < gs.CritChance {
+		return dmg * 2, true
+	}
+	return dmg, false
+}
+
+// setAttackMessage shows the message for an attack, flagged as a critical hit if it crit
+func (gs *GameState) setAttackMessage(msg string, crit bool) {
+	if !crit {
+		gs.SetMessage(msg)
+		return
+	}
+	gs.SetAlert(CritMessage+" "+msg, critStyle)
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCriticalHitDoublesDamage(t *testing.T) {
+	for _, c := range []struct {
+		seed int64
+		crit bool
+	}{
+		{9, true},  // First roll 0.004, under the 10% chance
+		{1, false}, // First roll 0.6
+	} {
+		gs := newTestState(10, 10)
+		gs.RNG = rand.New(rand.NewSource(c.seed))
+		gs.CritChance = DefaultCritChance
+		monolith := NewMonolith(2, 1)
+		hp := monolith.HP
+		gs.Enemies = []*Entity{monolith}
+
+		gs.squashChain(monolith, 1, 0)
+		want := gs.PlayerDamage()
+		if c.crit {
+			want *= 2
+		}
+		if dealt := hp - monolith.HP; dealt != want {
+			t.Errorf("Seed %d: expected %d damage, dealt %d", c.seed, want, dealt)
+		}
+		if got := strings.HasPrefix(gs.Message, CritMessage); got != c.crit {
+			t.Errorf("Seed %d: critical hit message = %v, want %v (%q)", c.seed, got, c.crit, gs.Message)
+		}
+	}
+}
+
+func TestAutoAttackCanCrit(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.RNG = rand.New(zeroSource{})
+	gs.CritChance = DefaultCritChance
+	monolith := NewMonolith(2, 2)
+	hp := monolith.HP
+	gs.Enemies = []*Entity{monolith}
+
+	gs.playerAutoAttack()
+	if dealt := hp - monolith.HP; dealt != 2*gs.PlayerDamage() {
+		t.Errorf("Expected a critical auto-attack to deal %d, dealt %d", 2*gs.PlayerDamage(), dealt)
+	}
+}
@@ -0,0 +1,51 @@
+package game
+
+import "fmt"
+
+const (
+	TechDebtThreshold = 5 // Technical debt per extra tough enemy on each later level
+	MaxDebtEnemies    = 4 // Cap on the extra enemies debt can spawn on one level
+	DebtEnemyHPBonus  = 3 // Extra HP of an enemy spawned by technical debt
+)
+
+// accrueDebt adds one point of technical debt for each living enemy the
+// player leaves behind on the level and returns how much was added
+func (gs *GameState) accrueDebt() int {
+	added := 0
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() {
+			added++
+		}
+	}
+	gs.TechDebt += added
+	return added
+}
+
+// debtEnemyCount is how many extra tough enemies the current debt spawns per level
+func (gs *GameState) debtEnemyCount() int {
+	return min(gs.TechDebt/TechDebtThreshold, MaxDebtEnemies)
+}
+
+// spawnDebtEnemies adds the enemies the player's technical debt has come back as:
+// scope creeps, drawn as 'S', with extra HP and damage
+func (gs *GameState) spawnDebtEnemies() {
+	for range gs.debtEnemyCount() {
+		x, y := gs.randomFloorTile()
+		enemy := NewScopeCreep(x, y)
+		enemy.Symbol = 'S'
+		enemy.HP += DebtEnemyHPBonus
+		enemy.MaxHP += DebtEnemyHPBonus
+		enemy.Damage++
+		enemy.SightRange = gs.rollSightRange()
+		gs.Enemies = append(gs.Enemies, enemy)
+	}
+}
+
+// debtMessage reports debt just taken on, warning when it crosses a threshold
+func (gs *GameState) debtMessage(added int) string {
+	msg := fmt.Sprintf("You left %d enemies behind. Technical debt: %d.", added, gs.TechDebt)
+	if gs.TechDebt/TechDebtThreshold > (gs.TechDebt-added)/TechDebtThreshold {
+		msg += " It's coming back to bite you..."
+	}
+	return msg
+}
@@ -0,0 +1,60 @@
+package game
+
+import "testing"
+
+func TestDescendingPastEnemiesRaisesDebt(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	gs.Enemies = []*Entity{NewBug(1, 1), NewBug(2, 1), NewScopeCreep(3, 1)}
+	gs.Enemies[0].HP = 0
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.MovePlayer(1, 0)
+
+	if gs.Level != 2 {
+		t.Fatalf("Expected to descend to level 2, got %d", gs.Level)
+	}
+	if gs.TechDebt != 2 {
+		t.Errorf("Leaving 2 living enemies behind should add 2 debt, got %d", gs.TechDebt)
+	}
+}
+
+func TestDescendingAfterClearingLevelAddsNoDebt(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	descend(gs)
+
+	if gs.TechDebt != 0 {
+		t.Errorf("A cleared level shouldn't add debt, got %d", gs.TechDebt)
+	}
+}
+
+func TestDebtThresholdToughensNextLevel(t *testing.T) {
+	levelHP := func(debt int) (int, int) {
+		gs := NewGameState(nil, 12345, 80, 40)
+		gs.TechDebt = debt
+		gs.Enemies = []*Entity{NewBug(1, 1)} // One left behind, crossing the threshold when debt is one short
+		gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+		gs.MovePlayer(1, 0)
+		hp := 0
+		for _, enemy := range gs.Enemies {
+			hp += enemy.MaxHP
+		}
+		return len(gs.Enemies), hp
+	}
+
+	baseCount, baseHP := levelHP(0)
+	count, hp := levelHP(TechDebtThreshold - 1)
+	if count != baseCount+1 {
+		t.Errorf("Crossing the debt threshold should add an enemy, got %d vs %d", count, baseCount)
+	}
+	if hp < baseHP+NewScopeCreep(0, 0).MaxHP+DebtEnemyHPBonus {
+		t.Errorf("The debt enemy should be extra tough, total enemy HP %d vs %d", hp, baseHP)
+	}
+}
+
+func TestDebtEnemiesAreCapped(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.TechDebt = TechDebtThreshold * (MaxDebtEnemies + 3)
+	gs.spawnDebtEnemies()
+	if len(gs.Enemies) != MaxDebtEnemies {
+		t.Errorf("Expected at most %d debt enemies, got %d", MaxDebtEnemies, len(gs.Enemies))
+	}
+}
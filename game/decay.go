@@ -0,0 +1,24 @@
+package game
+
+// markSeen records that a tile was in view this turn, for memory decay
+func (gs *GameState) markSeen(x, y int) {
+	if y < len(gs.LastSeen) && x < len(gs.LastSeen[y]) {
+		gs.LastSeen[y][x] = gs.Turns
+	}
+}
+
+// Remembers reports whether the player remembers an explored tile that's out
+// of view. With memory decay on, tiles unseen for more than MemoryDecay turns
+// are forgotten, except the one the player is standing on.
+func (gs *GameState) Remembers(x, y int) bool {
+	if !gs.Explored[y][x] {
+		return false
+	}
+	if gs.MemoryDecay == 0 || gs.Visible[y][x] || y >= len(gs.LastSeen) {
+		return true
+	}
+	if gs.livingPlayerAt(x, y) != nil {
+		return true
+	}
+	return gs.Turns-gs.LastSeen[y][x] <= gs.MemoryDecay
+}
@@ -0,0 +1,63 @@
+package game
+
+import "testing"
+
+// withLastSeen gives a test state the per-tile memory generateLevel sets up
+func withLastSeen(gs *GameState) {
+	gs.LastSeen = make([][]int, gs.Dungeon.Height)
+	for y := range gs.LastSeen {
+		gs.LastSeen[y] = make([]int, gs.Dungeon.Width)
+	}
+}
+
+func TestExploredTilesAreForgottenAfterDecay(t *testing.T) {
+	gs := newTestState(30, 10)
+	withLastSeen(gs)
+	gs.MemoryDecay = 3
+	gs.Explored[1][25] = true // Seen on turn 0, well out of view from (1, 1)
+	gs.updateVisibility()
+
+	g := &Game{state: gs}
+	width, height := 30, 13
+	for turn := 1; turn <= gs.MemoryDecay+1; turn++ {
+		gs.Wait()
+		g.frame = newFrameBuffer(width, height)
+		g.draw(width, height)
+		offsetX, offsetY := g.mapOffset(width, height-3)
+		ch, _, _, _ := g.frame.GetContent(offsetX+25, offsetY+1)
+
+		forgotten := gs.Turns > gs.MemoryDecay
+		if got := gs.Remembers(25, 1); got == forgotten {
+			t.Fatalf("Turn %d: remembers the tile = %v, want %v", gs.Turns, got, !forgotten)
+		}
+		if (ch == ' ') != forgotten {
+			t.Errorf("Turn %d: drew %q for the tile, forgotten = %v", gs.Turns, ch, forgotten)
+		}
+	}
+	if !gs.Remembers(2, 1) {
+		t.Error("Tiles in view should never be forgotten")
+	}
+}
+
+func TestStandingTileIsNeverForgotten(t *testing.T) {
+	gs := newTestState(10, 10)
+	withLastSeen(gs)
+	gs.MemoryDecay = 1
+	gs.Explored[1][1] = true
+	gs.Turns = 10 // Last seen on turn 0, and out of view while blinded
+	gs.Visible[1][1] = false
+
+	if !gs.Remembers(1, 1) {
+		t.Error("The tile the player stands on should never be forgotten")
+	}
+}
+
+func TestMemoryNeverDecaysByDefault(t *testing.T) {
+	gs := newTestState(30, 10)
+	withLastSeen(gs)
+	gs.Explored[1][25] = true
+	gs.Turns = 1000
+	if !gs.Remembers(25, 1) {
+		t.Error("Without --decay explored tiles should be remembered forever")
+	}
+}
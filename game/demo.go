@@ -0,0 +1,100 @@
+package game
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// DemoStepDelay is how long the demo waits between player moves
+const DemoStepDelay = 150 * time.Millisecond
+
+// DemoRestartSteps is how many demo steps the end screen stays up before a new run starts
+const DemoRestartSteps = 20
+
+// DemoTurn plays one turn for the computer-controlled player: it drinks a
+// carried potion once it's down to half health, otherwise makes the move
+// DemoMove picks
+func (gs *GameState) DemoTurn() {
+	if gs.Player.HP*2 <= gs.Player.MaxHP {
+		for i, item := range gs.Inventory {
+			if item.Type == EntityPotion {
+				gs.UseItem(i)
+				return
+			}
+		}
+	}
+	dx, dy := gs.DemoMove()
+	gs.MovePlayer(dx, dy)
+}
+
+// DemoMove decides the next move for the computer-controlled player in demo mode.
+// It fights back when an enemy is adjacent, otherwise heads for the door, and
+// wanders randomly if the door can't be reached.
+func (gs *GameState) DemoMove() (int, int) {
+	// Fight when cornered
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() && gs.Player.IsAdjacent(enemy) {
+			dx, dy := enemy.X-gs.Player.X, enemy.Y-gs.Player.Y
+			if !gs.NoDiagonals || dx == 0 || dy == 0 {
+				if !gs.cornerBlocked(gs.Player.X, gs.Player.Y, dx, dy) {
+					return dx, dy
+				}
+			}
+		}
+	}
+
+	// Head for the door, walking around portals so it doesn't bounce between them forever
+	avoidPortals := func(x, y int) bool {
+		return gs.Dungeon.Tiles[y][x] == TilePortal
+	}
+	if path := gs.pathAround(gs.Player.X, gs.Player.Y, gs.DoorX, gs.DoorY, avoidPortals); len(path) > 0 {
+		return path[0].X - gs.Player.X, path[0].Y - gs.Player.Y
+	}
+
+	// Wander
+	directions := [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}}
+	dir := directions[gs.RNG.Intn(len(directions))]
+	return dir[0], dir[1]
+}
+
+// demoStep advances the demo by one move, restarting with a fresh run after a death or victory
+func (g *Game) demoStep() {
+	if g.state.GameOver || g.state.Victory {
+		g.demoEndSteps++
+		if g.demoEndSteps >= DemoRestartSteps {
+			g.demoEndSteps = 0
+			g.state = g.newState(g.state.RNG.Int63())
+			g.applySettings()
+		}
+		return
+	}
+
+	// Leave the level summary up for one step, then carry on
+	if g.state.LevelSummary != nil {
+		g.state.LevelSummary = nil
+		return
+	}
+
+	// The demo doesn't go shopping
+	if g.state.ShopOpen {
+		g.state.CloseShop()
+		return
+	}
+
+	g.state.DemoTurn()
+}
+
+// runDemoTicker wakes the event loop every DemoStepDelay until done is closed
+func (g *Game) runDemoTicker(done <-chan struct{}) {
+	ticker := time.NewTicker(DemoStepDelay)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			g.screen.PostEvent(tcell.NewEventInterrupt(nil))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package game
+
+import "testing"
+
+func TestDemoMoveHeadsForDoor(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 2, 5
+	gs.DoorX, gs.DoorY = 15, 5
+
+	dx, dy := gs.DemoMove()
+	if dx != 1 || dy != 0 {
+		t.Errorf("Expected demo to step toward the door (1, 0), got (%d, %d)", dx, dy)
+	}
+}
+
+func TestDemoMoveRoutesAroundWallToDoor(t *testing.T) {
+	gs := newTestState(20, 10)
+	addWallColumn(gs.Dungeon, 10, 0, 7)
+	gs.Player.X, gs.Player.Y = 9, 2
+	gs.DoorX, gs.DoorY = 15, 2
+
+	for i := 0; i < 30 && (gs.Player.X != gs.DoorX || gs.Player.Y != gs.DoorY); i++ {
+		dx, dy := gs.DemoMove()
+		gs.Player.X += dx
+		gs.Player.Y += dy
+		if !gs.Dungeon.IsWalkable(gs.Player.X, gs.Player.Y) {
+			t.Fatalf("Demo walked into a wall at (%d, %d)", gs.Player.X, gs.Player.Y)
+		}
+	}
+	if gs.Player.X != gs.DoorX || gs.Player.Y != gs.DoorY {
+		t.Errorf("Demo should reach the door, ended at (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestDemoMoveFightsAdjacentEnemy(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 5, 5
+	gs.DoorX, gs.DoorY = 15, 5
+	gs.Enemies = []*Entity{NewBug(5, 4)}
+
+	dx, dy := gs.DemoMove()
+	if dx != 0 || dy != -1 {
+		t.Errorf("Expected demo to attack the adjacent bug (0, -1), got (%d, %d)", dx, dy)
+	}
+}
+
+func TestDemoMoveAvoidsPortals(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 2, 5
+	gs.DoorX, gs.DoorY = 15, 5
+	gs.Dungeon.Tiles[5][3] = TilePortal
+	gs.Dungeon.Tiles[5][12] = TilePortal
+	gs.Dungeon.Portals = [][2]int{{3, 5}, {12, 5}}
+	gs.MaxLevel = gs.Level // reaching the door ends the run
+
+	for i := 0; i < 30 && !gs.Victory; i++ {
+		dx, dy := gs.DemoMove()
+		if gs.Dungeon.Tiles[gs.Player.Y+dy][gs.Player.X+dx] == TilePortal {
+			t.Fatalf("Demo should walk around portals, stepped onto (%d, %d)", gs.Player.X+dx, gs.Player.Y+dy)
+		}
+		gs.MovePlayer(dx, dy)
+	}
+	if !gs.Victory {
+		t.Errorf("Demo should reach the door, ended at (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestDemoDrinksPotionWhenHurt(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 2, 5
+	gs.DoorX, gs.DoorY = 15, 5
+	gs.Inventory = []*Entity{NewPotion(0, 0)}
+
+	gs.Player.HP = gs.Player.MaxHP - 1
+	gs.DemoTurn()
+	if len(gs.Inventory) != 1 || gs.Player.X != 3 {
+		t.Fatalf("Demo shouldn't drink while healthy, inventory %d, player at %d", len(gs.Inventory), gs.Player.X)
+	}
+
+	gs.Player.HP = gs.Player.MaxHP / 2
+	gs.DemoTurn()
+	if len(gs.Inventory) != 0 || gs.Player.HP <= gs.Player.MaxHP/2 || gs.Player.X != 3 {
+		t.Errorf("Demo should drink its potion instead of moving at half health, HP %d, inventory %d, player at %d",
+			gs.Player.HP, len(gs.Inventory), gs.Player.X)
+	}
+}
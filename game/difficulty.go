@@ -0,0 +1,80 @@
+package game
+
+import "fmt"
+
+// Difficulty scales how many enemies and potions each level spawns, how
+// tough scope creeps are and how much HP the player starts with
+type Difficulty int
+
+const (
+	DifficultyNormal Difficulty = iota
+	DifficultyEasy
+	DifficultyHard
+)
+
+// difficultyRules are the knobs each difficulty turns
+type difficultyRules struct {
+	playerHP      int // Starting and max HP
+	enemyPercent  int // Enemies spawned, as a percentage of normal
+	potionPercent int // Potions spawned, as a percentage of normal
+	creepHP       int // Extra HP for scope creeps
+	creepDamage   int // Extra damage for scope creeps
+	regenTurns    int // Turns of rest it takes to regenerate 1 HP
+}
+
+var difficulties = map[Difficulty]difficultyRules{
+	DifficultyEasy:   {playerHP: 30, enemyPercent: 60, potionPercent: 150, regenTurns: 10},
+	DifficultyNormal: {playerHP: 20, enemyPercent: 100, potionPercent: 100, regenTurns: 20},
+	DifficultyHard:   {playerHP: 20, enemyPercent: 150, potionPercent: 50, creepHP: 2, creepDamage: 1, regenTurns: 40},
+}
+
+// ParseDifficulty converts a --difficulty value into a Difficulty
+func ParseDifficulty(s string) (Difficulty, error) {
+	switch s {
+	case "easy":
+		return DifficultyEasy, nil
+	case "normal":
+		return DifficultyNormal, nil
+	case "hard":
+		return DifficultyHard, nil
+	}
+	return DifficultyNormal, fmt.Errorf("unknown difficulty %q (want easy, normal or hard)", s)
+}
+
+// String returns the difficulty's name as given to --difficulty
+func (d Difficulty) String() string {
+	switch d {
+	case DifficultyEasy:
+		return "easy"
+	case DifficultyHard:
+		return "hard"
+	}
+	return "normal"
+}
+
+// rules returns the difficulty's rules
+func (d Difficulty) rules() difficultyRules {
+	return difficulties[d]
+}
+
+// scaleEnemies adjusts a level's normal enemy count for the difficulty,
+// always leaving at least one
+func (d Difficulty) scaleEnemies(n int) int {
+	return max(n*d.rules().enemyPercent/100, 1)
+}
+
+// scalePotions adjusts a level's normal potion count for the difficulty
+func (d Difficulty) scalePotions(n int) int {
+	return n * d.rules().potionPercent / 100
+}
+
+// toughen makes a freshly spawned enemy as tough as the difficulty demands
+func (d Difficulty) toughen(enemy *Entity) {
+	if enemy.Type != EntityScopeCreep {
+		return
+	}
+	rules := d.rules()
+	enemy.MaxHP += rules.creepHP
+	enemy.HP += rules.creepHP
+	enemy.Damage += rules.creepDamage
+}
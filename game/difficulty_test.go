@@ -0,0 +1,58 @@
+package game
+
+import "testing"
+
+func newDifficultyState(d Difficulty) *GameState {
+	return NewGameState(nil, 12345, 80, 40, func(gs *GameState) { gs.Difficulty = d })
+}
+
+func TestDifficultyScalesSpawns(t *testing.T) {
+	easy := newDifficultyState(DifficultyEasy)
+	normal := newDifficultyState(DifficultyNormal)
+	hard := newDifficultyState(DifficultyHard)
+
+	if !(len(easy.Enemies) < len(normal.Enemies) && len(normal.Enemies) < len(hard.Enemies)) {
+		t.Errorf("Expected fewer enemies on easy and more on hard, got %d, %d and %d",
+			len(easy.Enemies), len(normal.Enemies), len(hard.Enemies))
+	}
+	// A single level's potion count is too noisy to compare, so total a few seeds
+	var potions [3]int
+	for seed := int64(1); seed <= 10; seed++ {
+		for i, d := range []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard} {
+			potions[i] += len(NewGameState(nil, seed, 80, 40, func(gs *GameState) { gs.Difficulty = d }).Potions)
+		}
+	}
+	if !(potions[0] > potions[1] && potions[1] > potions[2]) {
+		t.Errorf("Expected more potions on easy and fewer on hard, got %d, %d and %d over 10 seeds",
+			potions[0], potions[1], potions[2])
+	}
+	if easy.Player.MaxHP != 30 || normal.Player.MaxHP != 20 || easy.Player.HP != easy.Player.MaxHP {
+		t.Errorf("Expected easy to start at 30/30 HP and normal at 20, got %d/%d and %d",
+			easy.Player.HP, easy.Player.MaxHP, normal.Player.MaxHP)
+	}
+}
+
+func TestHardScopeCreepsAreTougher(t *testing.T) {
+	creep := NewScopeCreep(0, 0)
+	DifficultyHard.toughen(creep)
+	if creep.MaxHP != 5 || creep.HP != 5 || creep.Damage != 3 {
+		t.Errorf("Expected a 5 HP, 3 damage scope creep on hard, got %d/%d HP and %d damage", creep.HP, creep.MaxHP, creep.Damage)
+	}
+
+	bug := NewBug(0, 0)
+	DifficultyHard.toughen(bug)
+	if bug.MaxHP != 1 || bug.Damage != 1 {
+		t.Error("Hard shouldn't change bugs")
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	for _, d := range []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard} {
+		if got, err := ParseDifficulty(d.String()); err != nil || got != d {
+			t.Errorf("ParseDifficulty(%q) = %v, %v", d.String(), got, err)
+		}
+	}
+	if _, err := ParseDifficulty("nightmare"); err == nil {
+		t.Error("Expected an error for an unknown difficulty")
+	}
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"math/rand"
+	"strings"
 )
 
 const (
@@ -9,6 +10,10 @@ const (
 	MaxRoomSize = 15
 )
 
+// MaxDungeonAttempts is how many times a level's layout is generated before
+// giving up on the BSP split leaving room for any rooms
+const MaxDungeonAttempts = 10
+
 type Room struct {
 	X, Y, W, H int
 }
@@ -142,11 +147,13 @@ func (n *BSPNode) GetRoom() *Room {
 }
 
 type Dungeon struct {
-	Width    int
-	Height   int
-	Tiles    [][]Tile
-	Rooms    []*Room
-	CodeFile *CodeFile
+	Width       int
+	Height      int
+	Tiles       [][]Tile
+	Rooms       []*Room
+	CodeFile    *CodeFile
+	Portals     [][2]int   // Linked pull request portal pair, nil if none
+	Connections [][2]*Room `json:"-"` // Room pairs joined by a corridor in connectRooms
 }
 
 type Tile int
@@ -155,6 +162,9 @@ const (
 	TileWall Tile = iota
 	TileFloor
 	TileDoor
+	TilePortal
+	TileLint
+	TileCI
 )
 
 func GenerateDungeon(width, height int, rng *rand.Rand, codeFile *CodeFile) *Dungeon {
@@ -196,6 +206,50 @@ func GenerateDungeon(width, height int, rng *rand.Rand, codeFile *CodeFile) *Dun
 	return d
 }
 
+// GenerateDungeonWithRooms generates dungeons until one has at least one room.
+// On a small map the BSP split can leave every leaf too thin for a room; if
+// that keeps happening, a single room is carved in the middle of the map.
+func GenerateDungeonWithRooms(width, height int, rng *rand.Rand, codeFile *CodeFile) *Dungeon {
+	d := GenerateDungeon(width, height, rng, codeFile)
+	for attempt := 1; len(d.Rooms) == 0 && attempt < MaxDungeonAttempts; attempt++ {
+		d = GenerateDungeon(width, height, rng, codeFile)
+	}
+	if len(d.Rooms) == 0 {
+		room := &Room{X: (width - MinRoomSize) / 2, Y: (height - MinRoomSize) / 2, W: MinRoomSize, H: MinRoomSize}
+		d.Rooms = []*Room{room}
+		for y := room.Y; y < room.Y+room.H; y++ {
+			d.carveHorizontalCorridor(room.X, room.X+room.W-1, y)
+		}
+	}
+	return d
+}
+
+// ErodeRooms roughens room outlines by carving extra floor into the wall ring
+// around each room. Each wall tile on the ring is carved with the given
+// chance. Only walls become floor, so connectivity and the Room bounds used
+// for spawning are preserved; the map's outer border is never carved.
+func (d *Dungeon) ErodeRooms(rng *rand.Rand, chance float64) {
+	if chance <= 0 {
+		return
+	}
+
+	for _, room := range d.Rooms {
+		for y := room.Y - 1; y <= room.Y+room.H; y++ {
+			for x := room.X - 1; x <= room.X+room.W; x++ {
+				if room.Contains(x, y) {
+					continue
+				}
+				if x < 1 || x >= d.Width-1 || y < 1 || y >= d.Height-1 {
+					continue
+				}
+				if d.Tiles[y][x] == TileWall && rng.Float64() < chance {
+					d.Tiles[y][x] = TileFloor
+				}
+			}
+		}
+	}
+}
+
 func connectRooms(node *BSPNode, d *Dungeon, rng *rand.Rand) {
 	if node.Left == nil || node.Right == nil {
 		return
@@ -213,6 +267,7 @@ func connectRooms(node *BSPNode, d *Dungeon, rng *rand.Rand) {
 
 	x1, y1 := leftRoom.Center()
 	x2, y2 := rightRoom.Center()
+	d.Connections = append(d.Connections, [2]*Room{leftRoom, rightRoom})
 
 	// L-shaped corridor
 	if rng.Float32() > 0.5 {
@@ -246,6 +301,40 @@ func (d *Dungeon) carveVerticalCorridor(y1, y2, x int) {
 	}
 }
 
+// carvePath carves an L-shaped corridor from (x1, y1) to (x2, y2), turning
+// only walls into floor so doors, portals and hazards on the way are kept
+func (d *Dungeon) carvePath(x1, y1, x2, y2 int) {
+	carve := func(x, y int) {
+		if x >= 0 && x < d.Width && y >= 0 && y < d.Height && d.Tiles[y][x] == TileWall {
+			d.Tiles[y][x] = TileFloor
+		}
+	}
+	for x := min(x1, x2); x <= max(x1, x2); x++ {
+		carve(x, y1)
+	}
+	for y := min(y1, y2); y <= max(y1, y2); y++ {
+		carve(x2, y)
+	}
+}
+
+// FloorChar returns the code character shown on the floor at (x, y), or '.'
+// where the level's code file has nothing to show. Each row of the map shows
+// two code lines side by side (2x density), 40 columns each.
+func (d *Dungeon) FloorChar(x, y int) rune {
+	if d.CodeFile == nil || len(d.CodeFile.Lines) == 0 {
+		return '.'
+	}
+	line := d.CodeFile.Lines[(y*2+x/40)%len(d.CodeFile.Lines)]
+	charIdx := x % 40
+	if x >= 40 {
+		charIdx = x - 40
+	}
+	if charIdx < len(line) {
+		return rune(line[charIdx])
+	}
+	return '.'
+}
+
 func (d *Dungeon) IsWalkable(x, y int) bool {
 	if x < 0 || x >= d.Width || y < 0 || y >= d.Height {
 		return false
@@ -253,6 +342,35 @@ func (d *Dungeon) IsWalkable(x, y int) bool {
 	return d.Tiles[y][x] != TileWall
 }
 
+// IsCornerCut reports whether a diagonal step from (x, y) squeezes between two wall corners
+func (d *Dungeon) IsCornerCut(x, y, dx, dy int) bool {
+	if dx == 0 || dy == 0 {
+		return false
+	}
+	return !d.IsWalkable(x+dx, y) && !d.IsWalkable(x, y+dy)
+}
+
+// StartRoom returns the room the player starts in: the one whose center is
+// farthest from the door's room (the last room), so each level has to be crossed
+func (d *Dungeon) StartRoom() *Room {
+	if len(d.Rooms) == 0 {
+		return nil
+	}
+
+	doorX, doorY := d.Rooms[len(d.Rooms)-1].Center()
+	best := d.Rooms[0]
+	bestDist := -1
+	for _, room := range d.Rooms {
+		x, y := room.Center()
+		dist := (x-doorX)*(x-doorX) + (y-doorY)*(y-doorY)
+		if dist > bestDist {
+			bestDist = dist
+			best = room
+		}
+	}
+	return best
+}
+
 // findCentralRoomCenter finds the center of the room closest to the dungeon center
 func findCentralRoomCenter(d *Dungeon) (int, int) {
 	if len(d.Rooms) == 0 {
@@ -330,3 +448,107 @@ func (d *Dungeon) PlaceDoor(rng *rand.Rand) (int, int) {
 	d.Tiles[y][x] = TileDoor
 	return x, y
 }
+
+// PlacePortals links two different rooms with a pair of pull request portals.
+// Returns false if the dungeon has too few rooms or no free tiles for a pair.
+func (d *Dungeon) PlacePortals(rng *rand.Rand) bool {
+	if len(d.Rooms) < 2 {
+		return false
+	}
+
+	// Never place a portal on the player's start tile
+	startX, startY := d.StartRoom().Center()
+
+	first := rng.Intn(len(d.Rooms))
+	second := (first + 1 + rng.Intn(len(d.Rooms)-1)) % len(d.Rooms)
+
+	var portals [][2]int
+	for _, idx := range []int{first, second} {
+		room := d.Rooms[idx]
+		for attempts := 0; attempts < 100; attempts++ {
+			x := room.X + rng.Intn(room.W)
+			y := room.Y + rng.Intn(room.H)
+			if x < 0 || x >= d.Width || y < 0 || y >= d.Height {
+				continue
+			}
+			if d.Tiles[y][x] != TileFloor || (x == startX && y == startY) {
+				continue
+			}
+			portals = append(portals, [2]int{x, y})
+			break
+		}
+	}
+
+	if len(portals) != 2 {
+		return false
+	}
+
+	for _, p := range portals {
+		d.Tiles[p[1]][p[0]] = TilePortal
+	}
+	d.Portals = portals
+	return true
+}
+
+// PortalExit returns the tile paired with the portal at (x, y)
+func (d *Dungeon) PortalExit(x, y int) (int, int, bool) {
+	if len(d.Portals) != 2 {
+		return 0, 0, false
+	}
+	for i, p := range d.Portals {
+		if p[0] == x && p[1] == y {
+			exit := d.Portals[1-i]
+			return exit[0], exit[1], true
+		}
+	}
+	return 0, 0, false
+}
+
+// tileASCII is how each tile is written out by RenderASCII
+var tileASCII = map[Tile]rune{
+	TileWall:   '#',
+	TileFloor:  '.',
+	TileDoor:   '>',
+	TilePortal: 'O',
+	TileLint:   '!',
+	TileCI:     '%',
+}
+
+// RenderASCII returns the tile grid as plain text, one line per row, with
+// floor as '.' rather than the code drawn on it in game
+func (d *Dungeon) RenderASCII() string {
+	return d.RenderASCIIWith(nil)
+}
+
+// RenderASCIIWith is RenderASCII with entities drawn over the tiles they stand
+// on, later entities over earlier ones
+func (d *Dungeon) RenderASCIIWith(entities []*Entity) string {
+	grid := make([][]rune, d.Height)
+	for y, row := range d.Tiles {
+		grid[y] = make([]rune, d.Width)
+		for x, tile := range row {
+			grid[y][x] = tileASCII[tile]
+		}
+	}
+	for _, e := range entities {
+		if e.X >= 0 && e.X < d.Width && e.Y >= 0 && e.Y < d.Height {
+			grid[e.Y][e.X] = e.Symbol
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range grid {
+		b.WriteString(string(row))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// CloneTiles returns a copy of the tile grid
+func (d *Dungeon) CloneTiles() [][]Tile {
+	tiles := make([][]Tile, len(d.Tiles))
+	for y, row := range d.Tiles {
+		tiles[y] = append([]Tile(nil), row...)
+	}
+	return tiles
+}
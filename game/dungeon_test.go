@@ -0,0 +1,190 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStartRoomIsFarthestFromDoorRoom(t *testing.T) {
+	d := &Dungeon{
+		Rooms: []*Room{
+			{X: 30, Y: 2, W: 6, H: 6},  // close to the door room
+			{X: 2, Y: 30, W: 6, H: 6},  // farthest from the door room
+			{X: 20, Y: 20, W: 6, H: 6}, // middle
+			{X: 40, Y: 2, W: 6, H: 6},  // door room (last)
+		},
+	}
+
+	if got := d.StartRoom(); got != d.Rooms[1] {
+		t.Errorf("Expected start room at (2, 30), got (%d, %d)", got.X, got.Y)
+	}
+}
+
+func TestStartRoomSingleRoom(t *testing.T) {
+	d := &Dungeon{Rooms: []*Room{{X: 1, Y: 1, W: 6, H: 6}}}
+	if got := d.StartRoom(); got != d.Rooms[0] {
+		t.Error("A single-room dungeon should start in its only room")
+	}
+
+	empty := &Dungeon{}
+	if empty.StartRoom() != nil {
+		t.Error("A dungeon without rooms has no start room")
+	}
+}
+
+func TestPlayerSpawnsInStartRoom(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	start := gs.Dungeon.StartRoom()
+	x, y := start.Center()
+	if gs.Player.X != x || gs.Player.Y != y {
+		t.Errorf("Player should spawn at start room center (%d, %d), got (%d, %d)", x, y, gs.Player.X, gs.Player.Y)
+	}
+}
+
+// countReachable flood-fills walkable tiles from (x, y) and returns how many were reached
+func countReachable(d *Dungeon, x, y int) int {
+	return len(d.reachableFrom(x, y))
+}
+
+func countFloor(d *Dungeon) int {
+	count := 0
+	for y := range d.Tiles {
+		for x := range d.Tiles[y] {
+			if d.Tiles[y][x] != TileWall {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestErodeRoomsKeepsRoomsConnected(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		plain := GenerateDungeon(80, 40, rand.New(rand.NewSource(seed)), nil)
+
+		rng := rand.New(rand.NewSource(seed))
+		d := GenerateDungeon(80, 40, rng, nil)
+		d.ErodeRooms(rng, 0.5)
+
+		if countFloor(d) <= countFloor(plain) {
+			t.Errorf("Seed %d: erosion should carve extra floor", seed)
+		}
+
+		startX, startY := d.Rooms[0].Center()
+		reachable := countReachable(d, startX, startY)
+		for i, room := range d.Rooms {
+			x, y := room.Center()
+			if !d.IsWalkable(x, y) {
+				t.Errorf("Seed %d: room %d center (%d, %d) is not walkable", seed, i, x, y)
+			}
+			if countReachable(d, x, y) != reachable {
+				t.Errorf("Seed %d: room %d is not connected to the first room", seed, i)
+			}
+		}
+
+		// The outer border stays solid
+		for x := 0; x < d.Width; x++ {
+			if d.Tiles[0][x] != TileWall || d.Tiles[d.Height-1][x] != TileWall {
+				t.Fatalf("Seed %d: erosion carved the map border at x=%d", seed, x)
+			}
+		}
+	}
+}
+
+func TestConnectRoomsRecordsConnections(t *testing.T) {
+	a := &Room{X: 2, Y: 2, W: 6, H: 6}
+	b := &Room{X: 20, Y: 2, W: 6, H: 6}
+	c := &Room{X: 2, Y: 20, W: 6, H: 6}
+
+	// root joins the (a, b) subtree to c
+	root := &BSPNode{
+		Left: &BSPNode{
+			Left:  &BSPNode{Room: a},
+			Right: &BSPNode{Room: b},
+		},
+		Right: &BSPNode{Room: c},
+	}
+
+	d := &Dungeon{Width: 40, Height: 40, Tiles: make([][]Tile, 40)}
+	for y := range d.Tiles {
+		d.Tiles[y] = make([]Tile, 40)
+	}
+	connectRooms(root, d, rand.New(rand.NewSource(1)))
+
+	want := [][2]*Room{{a, b}, {a, c}}
+	if len(d.Connections) != len(want) {
+		t.Fatalf("Expected %d connections, got %d", len(want), len(d.Connections))
+	}
+	for i, conn := range want {
+		if d.Connections[i] != conn {
+			t.Errorf("Connection %d: expected %v, got %v", i, conn, d.Connections[i])
+		}
+	}
+}
+
+func TestGeneratedConnectionsSpanAllRooms(t *testing.T) {
+	d := GenerateDungeon(80, 40, rand.New(rand.NewSource(12345)), nil)
+
+	// A BSP tree joins n rooms with n-1 corridors
+	if len(d.Connections) != len(d.Rooms)-1 {
+		t.Errorf("Expected %d connections for %d rooms, got %d", len(d.Rooms)-1, len(d.Rooms), len(d.Connections))
+	}
+}
+
+func TestRenderASCIIDrawsTilesAndEntities(t *testing.T) {
+	d := &Dungeon{Width: 4, Height: 3, Tiles: [][]Tile{
+		{TileWall, TileWall, TileWall, TileWall},
+		{TileWall, TileFloor, TileDoor, TileWall},
+		{TileWall, TileWall, TileWall, TileWall},
+	}}
+	if got, want := d.RenderASCII(), "####\n#.>#\n####\n"; got != want {
+		t.Errorf("RenderASCII() = %q, want %q", got, want)
+	}
+	if got, want := d.RenderASCIIWith([]*Entity{NewPlayer(1, 1), NewBug(9, 9)}), "####\n#@>#\n####\n"; got != want {
+		t.Errorf("RenderASCIIWith() = %q, want %q (entities off the map are skipped)", got, want)
+	}
+}
+
+// levelSnapshot is the first level of seed 42 with the xorshift generator at
+// the smallest dungeon size. Update it deliberately when the generator changes.
+const levelSnapshot = `########################################
+##.......##.....b.#####......##........#
+##.......##.......#####......##.......!#
+##.......##.......#####......##........#
+##..............................!......#
+##...@...##.......#####......##........#
+##.......##....)O.#####......##........#
+##+++....#####.####################.####
+##.......#####.####################.####
+#####.########.####################.####
+#####.########.#####.........###.......#
+#####.#####.......##.........###..b....#
+#####.#####.....O.##.........###.s.....#
+##.......##.......##.........###.......#
+##.......##...!..............###.......#
+##.......##.......##!........###..>....#
+##.......##....s..##.........###.......#
+##.......###########.........###.....b.#
+##....+..#######################.......#
+########################################
+`
+
+func TestLevelASCIISnapshot(t *testing.T) {
+	gs := NewGameState(nil, 42, MinDungeonWidth, MinDungeonHeight+3, func(gs *GameState) {
+		gs.RNGAlgorithm = RNGXorshift
+	})
+	if got := gs.LevelASCII(); got != levelSnapshot {
+		t.Errorf("Level for seed 42 changed:\n%s\nwant:\n%s", got, levelSnapshot)
+	}
+}
+
+func TestGenerateDungeonWithRoomsAlwaysHasARoom(t *testing.T) {
+	// Too narrow for the BSP split to ever fit a room
+	d := GenerateDungeonWithRooms(7, 20, rand.New(rand.NewSource(1)), nil)
+	if len(d.Rooms) != 1 {
+		t.Fatalf("Expected a single fallback room, got %d rooms", len(d.Rooms))
+	}
+	if x, y := d.Rooms[0].Center(); !d.IsWalkable(x, y) {
+		t.Errorf("The fallback room's center (%d, %d) should be carved out", x, y)
+	}
+}
@@ -1,5 +1,7 @@
 package game
 
+import "strings"
+
 type EntityType int
 
 const (
@@ -7,15 +9,90 @@ const (
 	EntityBug
 	EntityScopeCreep
 	EntityPotion
+	EntityFlakyTest
+	EntityConflictingCommit
+	EntityRevert
+	EntityMonolith
+	EntityMergedBug
+	EntityHotfix
+	EntityArmor
+	EntityRebase
+	EntityGold
+	EntityTorch
+	EntityWeapon
+	EntityBrowserTest
+)
+
+// RevertChance is the chance a level holds a revert item
+const RevertChance = 0.15
+
+// PotionHealAmount is how much HP a health potion restores, unless potions
+// heal a percentage of max HP instead (see GameState.PotionHealPercent)
+const PotionHealAmount = 3
+
+// RevertHealAmount is how much HP picking up a revert restores
+const RevertHealAmount = 5
+
+// HotfixChance is the chance a level holds a hotfix, which wipes out every
+// enemy on the level when used
+const HotfixChance = 0.05
+
+// ArmorChance is the chance a level holds a piece of armor
+const ArmorChance = 0.1
+
+// MaxMergeFireSpread caps how many tiles chasing merge conflict fire can spread to
+const MaxMergeFireSpread = 30
+
+// Bug merging: two adjacent bugs can combine into one stronger merged bug
+const (
+	DefaultBugMergeChance = 0.1 // Chance per adjacent pair per turn
+	MaxMergedBugHP        = 4
+	MaxMergedBugDamage    = 2
 )
 
+// MergeQueueWaveSize is the number of conflicting commits spawned by the merge queue
+const MergeQueueWaveSize = 3
+
+// MaxFlankDetour is how many extra steps an enemy blocked by an ally will
+// walk to reach the player by another route
+const MaxFlankDetour = 10
+
+// MaxSquashChain is the most attacks a single bump can chain through a line of enemies
+const MaxSquashChain = 3
+
+// Sight ranges rolled for enemies at spawn; the rest see as far as their line of sight reaches
+const (
+	BlindSightRange   = 1 // Only senses the player when adjacent
+	BlindChance       = 0.15
+	NearsightedRange  = 5
+	NearsightedChance = 0.25
+)
+
+// FlakyTestFlakiness is the chance a flaky test wanders randomly instead of chasing
+const FlakyTestFlakiness = 0.4
+
 type Entity struct {
-	Type   EntityType
-	X, Y   int
-	HP     int
-	MaxHP  int
-	Damage int
-	Symbol rune
+	Type    EntityType
+	X, Y    int
+	HP      int
+	MaxHP   int
+	Damage  int
+	Symbol  rune
+	Alerted bool // Enemy has spotted the player at least once
+
+	SightRange int  // How far an enemy can see the player, in tiles (0 = unlimited)
+	FleeTurns  int  // Turns left running away from the player
+	Defense    int  // Damage an armor item blocks from each hit, or that a player's worn armor does
+	HasFled    bool // Boss has already used its retreat
+
+	HomeX, HomeY int  // Where an enemy was when it first spotted the player
+	Returning    bool // A leashed enemy gave up the chase and is walking home
+
+	TeleportCooldown int        // Turns until a rebase can teleport again
+	RegenTurns       int        // Turns a player has rested towards regenerating 1 HP
+	Tier             PotionTier // How big a health potion is
+	Value            int        // How much a pile of gold is worth
+	Weapon           *Weapon    // Which weapon a weapon item is
 }
 
 func NewPlayer(x, y int) *Entity {
@@ -54,6 +131,94 @@ func NewScopeCreep(x, y int) *Entity {
 	}
 }
 
+func NewFlakyTest(x, y int) *Entity {
+	return &Entity{
+		Type:   EntityFlakyTest,
+		X:      x,
+		Y:      y,
+		HP:     2,
+		MaxHP:  2,
+		Damage: 1,
+		Symbol: 'f',
+	}
+}
+
+// NewBrowserTest creates a browser test, which shoots from a distance
+// instead of closing in
+func NewBrowserTest(x, y int) *Entity {
+	return &Entity{
+		Type:   EntityBrowserTest,
+		X:      x,
+		Y:      y,
+		HP:     2,
+		MaxHP:  2,
+		Damage: 1,
+		Symbol: 't',
+	}
+}
+
+// NewRebase creates a "git pull --rebase", which teleports toward the player
+// instead of walking
+func NewRebase(x, y int) *Entity {
+	return &Entity{
+		Type:   EntityRebase,
+		X:      x,
+		Y:      y,
+		HP:     2,
+		MaxHP:  2,
+		Damage: 1,
+		Symbol: 'r',
+	}
+}
+
+func NewConflictingCommit(x, y int) *Entity {
+	return &Entity{
+		Type:    EntityConflictingCommit,
+		X:       x,
+		Y:       y,
+		HP:      2,
+		MaxHP:   2,
+		Damage:  1,
+		Symbol:  'c',
+		Alerted: true,
+		// It spawns already chasing, so it never gets a home from spotting the player
+		HomeX: x,
+		HomeY: y,
+	}
+}
+
+func NewMonolith(x, y int) *Entity {
+	return &Entity{
+		Type:   EntityMonolith,
+		X:      x,
+		Y:      y,
+		HP:     10,
+		MaxHP:  10,
+		Damage: 3,
+		Symbol: 'M',
+	}
+}
+
+// NewMergedBug combines two bugs into one, summing their stats plus a bonus
+// hit point, capped at MaxMergedBugHP and MaxMergedBugDamage
+func NewMergedBug(x, y int, a, b *Entity) *Entity {
+	hp := min(a.HP+b.HP+1, MaxMergedBugHP)
+	return &Entity{
+		Type:    EntityMergedBug,
+		X:       x,
+		Y:       y,
+		HP:      hp,
+		MaxHP:   hp,
+		Damage:  min(a.Damage+b.Damage, MaxMergedBugDamage),
+		Symbol:  'B',
+		Alerted: a.Alerted || b.Alerted,
+		HomeX:   x,
+		HomeY:   y,
+
+		SightRange: max(a.SightRange, b.SightRange),
+	}
+}
+
 func NewPotion(x, y int) *Entity {
 	return &Entity{
 		Type:   EntityPotion,
@@ -63,6 +228,35 @@ func NewPotion(x, y int) *Entity {
 	}
 }
 
+func NewRevert(x, y int) *Entity {
+	return &Entity{
+		Type:   EntityRevert,
+		X:      x,
+		Y:      y,
+		Symbol: 'R',
+	}
+}
+
+func NewHotfix(x, y int) *Entity {
+	return &Entity{
+		Type:   EntityHotfix,
+		X:      x,
+		Y:      y,
+		Symbol: 'H',
+	}
+}
+
+// NewArmor creates a piece of armor that blocks defense damage per hit
+func NewArmor(x, y, defense int) *Entity {
+	return &Entity{
+		Type:    EntityArmor,
+		X:       x,
+		Y:       y,
+		Symbol:  '[',
+		Defense: defense,
+	}
+}
+
 func (e *Entity) IsAlive() bool {
 	return e.HP > 0
 }
@@ -82,7 +276,57 @@ func (e *Entity) Heal(amount int) {
 }
 
 func (e *Entity) IsEnemy() bool {
-	return e.Type == EntityBug || e.Type == EntityScopeCreep
+	switch e.Type {
+	case EntityBug, EntityScopeCreep, EntityFlakyTest, EntityConflictingCommit, EntityMonolith, EntityMergedBug, EntityRebase, EntityBrowserTest:
+		return true
+	}
+	return false
+}
+
+// Name returns the display name used in combat messages
+func (e *Entity) Name() string {
+	switch e.Type {
+	case EntityBug:
+		return "bug"
+	case EntityScopeCreep:
+		return "scope creep"
+	case EntityFlakyTest:
+		return "flaky test"
+	case EntityBrowserTest:
+		return "browser test"
+	case EntityRebase:
+		return "rebase"
+	case EntityConflictingCommit:
+		return "conflicting commit"
+	case EntityMonolith:
+		return "legacy monolith"
+	case EntityMergedBug:
+		return "merged bug"
+	case EntityPotion:
+		return "potion"
+	case EntityRevert:
+		return "revert"
+	case EntityHotfix:
+		return "hotfix"
+	case EntityArmor:
+		return "armor"
+	case EntityGold:
+		return "gold"
+	case EntityTorch:
+		return "torch"
+	case EntityWeapon:
+		if e.Weapon != nil {
+			return e.Weapon.Name
+		}
+		return "weapon"
+	default:
+		return "you"
+	}
+}
+
+// KillerID returns the identifier recorded in GameState.KilledBy when this entity kills the player
+func (e *Entity) KillerID() string {
+	return strings.ReplaceAll(e.Name(), " ", "_")
 }
 
 func (e *Entity) DistanceTo(other *Entity) int {
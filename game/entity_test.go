@@ -0,0 +1,25 @@
+package game
+
+import "testing"
+
+func TestEnemyConstructorsStartAtMaxHP(t *testing.T) {
+	bug := NewBug(0, 0)
+	enemies := []*Entity{
+		bug,
+		NewScopeCreep(0, 0),
+		NewFlakyTest(0, 0),
+		NewBrowserTest(0, 0),
+		NewRebase(0, 0),
+		NewConflictingCommit(0, 0),
+		NewMonolith(0, 0),
+		NewMergedBug(0, 0, bug, NewBug(0, 0)),
+	}
+	for _, enemy := range enemies {
+		if enemy.MaxHP <= 0 {
+			t.Errorf("%s has no max HP", enemy.Name())
+		}
+		if enemy.HP != enemy.MaxHP {
+			t.Errorf("%s starts with %d/%d HP", enemy.Name(), enemy.HP, enemy.MaxHP)
+		}
+	}
+}
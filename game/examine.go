@@ -0,0 +1,43 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// hitsToKill returns how many attacks of damage it takes to bring hp down to
+// zero. Damage is at least 1 per hit, as a bump always does something.
+func hitsToKill(hp, damage int) int {
+	if hp <= 0 {
+		return 0
+	}
+	damage = max(damage, 1)
+	return (hp + damage - 1) / damage
+}
+
+// Examine describes the living enemies next to the player: their HP out of
+// their max HP and how many hits each would take to kill. It doesn't take a
+// turn, so the answer shows right away rather than waiting in the queue.
+func (gs *GameState) Examine() {
+	var parts []string
+	for _, enemy := range gs.Enemies {
+		if !enemy.IsAlive() || !gs.Player.IsAdjacent(enemy) {
+			continue
+		}
+		hits := hitsToKill(enemy.HP, gs.PlayerDamage())
+		noun := "hits"
+		if hits == 1 {
+			noun = "hit"
+		}
+		name := enemy.Name()
+		parts = append(parts, fmt.Sprintf("%s%s (%d/%d HP) %s %d %s to kill",
+			strings.ToUpper(name[:1]), name[1:], enemy.HP, enemy.MaxHP, gs.Glyphs().Dash, hits, noun))
+	}
+	if len(parts) == 0 {
+		gs.SetAlert("Nothing to examine nearby.", tcell.Style{})
+		return
+	}
+	gs.SetAlert(strings.Join(parts, "; "), tcell.Style{})
+}
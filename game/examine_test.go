@@ -0,0 +1,64 @@
+package game
+
+import "testing"
+
+func TestHitsToKill(t *testing.T) {
+	tests := []struct {
+		hp, damage, want int
+	}{
+		{hp: 1, damage: 1, want: 1},
+		{hp: 3, damage: 1, want: 3},
+		{hp: 4, damage: 2, want: 2},
+		{hp: 5, damage: 2, want: 3},
+		{hp: 10, damage: 3, want: 4},
+		{hp: 2, damage: 5, want: 1}, // Damage exceeds HP
+		{hp: 3, damage: 0, want: 3}, // A bump always deals at least 1
+		{hp: 0, damage: 1, want: 0}, // Already dead
+	}
+	for _, tt := range tests {
+		if got := hitsToKill(tt.hp, tt.damage); got != tt.want {
+			t.Errorf("hitsToKill(%d, %d) = %d, want %d", tt.hp, tt.damage, got, tt.want)
+		}
+	}
+}
+
+func TestExamineShowsHitsToKill(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.Damage = 2
+	gs.Enemies = []*Entity{NewScopeCreep(2, 1)}
+
+	gs.Examine()
+	if want := "Scope creep (3/3 HP) — 2 hits to kill"; gs.Message != want {
+		t.Errorf("expected %q, got %q", want, gs.Message)
+	}
+
+	gs.Player.Damage = 3
+	gs.SetMessage("")
+	gs.Examine()
+	if want := "Scope creep (3/3 HP) — 1 hit to kill"; gs.Message != want {
+		t.Errorf("expected %q, got %q", want, gs.Message)
+	}
+}
+
+func TestExamineIgnoresDistantEnemies(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Enemies = []*Entity{NewBug(5, 5)}
+
+	gs.Examine()
+	if gs.Message != "Nothing to examine nearby." {
+		t.Errorf("expected nothing to examine, got %q", gs.Message)
+	}
+}
+
+func TestExamineShowsWoundedHP(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.Damage = 2
+	monolith := NewMonolith(2, 2)
+	monolith.TakeDamage(4)
+	gs.Enemies = []*Entity{monolith}
+
+	gs.Examine()
+	if want := "Legacy monolith (6/10 HP) — 3 hits to kill"; gs.Message != want {
+		t.Errorf("expected %q, got %q", want, gs.Message)
+	}
+}
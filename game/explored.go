@@ -0,0 +1,68 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// exploredPath returns where the explored map for a seed and level is stored
+func exploredPath(dir string, seed int64, level int) string {
+	return filepath.Join(dir, fmt.Sprintf("%d-%d.explored", seed, level))
+}
+
+// SaveExplored writes the current level's explored map to ExploredDir so the
+// next run of the same repository starts with it already discovered
+func (gs *GameState) SaveExplored() error {
+	if gs.ExploredDir == "" || gs.Dungeon == nil {
+		return nil
+	}
+	if err := os.MkdirAll(gs.ExploredDir, 0o755); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%dx%d\n", gs.Dungeon.Width, gs.Dungeon.Height)
+	for _, row := range gs.Explored {
+		for _, seen := range row {
+			if seen {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	return os.WriteFile(exploredPath(gs.ExploredDir, gs.Seed, gs.Level), []byte(b.String()), 0o644)
+}
+
+// loadExplored pre-populates Explored from a stored map for this seed and level.
+// Maps saved for a different dungeon size (e.g. another terminal size) are ignored.
+func (gs *GameState) loadExplored() {
+	if gs.ExploredDir == "" {
+		return
+	}
+
+	f, err := os.Open(exploredPath(gs.ExploredDir, gs.Seed, gs.Level))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != fmt.Sprintf("%dx%d", gs.Dungeon.Width, gs.Dungeon.Height) {
+		return
+	}
+
+	for y := 0; y < gs.Dungeon.Height && scanner.Scan(); y++ {
+		row := scanner.Text()
+		for x := 0; x < gs.Dungeon.Width && x < len(row); x++ {
+			if row[x] == '#' {
+				gs.Explored[y][x] = true
+			}
+		}
+	}
+}
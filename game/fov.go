@@ -0,0 +1,97 @@
+package game
+
+// fovOctants maps each of the eight octants scanned by computeFOV onto the
+// map: a tile (col, row) within an octant is at (px + col*xx + row*xy,
+// py + col*yx + row*yy)
+var fovOctants = [8][4]int{
+	{1, 0, 0, 1},
+	{0, 1, 1, 0},
+	{0, -1, 1, 0},
+	{-1, 0, 0, 1},
+	{-1, 0, 0, -1},
+	{0, -1, -1, 0},
+	{0, 1, -1, 0},
+	{1, 0, 0, -1},
+}
+
+// inVisionRadius reports whether a tile dx, dy away from the viewer is close
+// enough to be seen. The half-tile of slack rounds off the edge of the circle.
+func inVisionRadius(dx, dy, radius int) bool {
+	return dx*dx+dy*dy <= radius*radius+radius
+}
+
+// blocksSight reports whether light stops at a tile. Walls and the edge of
+// the map do; the wall itself can still be seen.
+func (gs *GameState) blocksSight(x, y int) bool {
+	if x < 0 || x >= gs.Dungeon.Width || y < 0 || y >= gs.Dungeon.Height {
+		return true
+	}
+	return gs.Dungeon.Tiles[y][x] == TileWall
+}
+
+// reveal marks a tile as visible and explored, if it's on the map
+func (gs *GameState) reveal(x, y int) {
+	if x < 0 || x >= gs.Dungeon.Width || y < 0 || y >= gs.Dungeon.Height {
+		return
+	}
+	gs.Visible[y][x] = true
+	gs.Explored[y][x] = true
+	gs.markSeen(x, y)
+}
+
+// computeFOV reveals everything a viewer at (px, py) can see within radius,
+// using recursive shadowcasting: each octant is scanned row by row outwards,
+// and walls cast shadows that narrow the slopes scanned in later rows
+func (gs *GameState) computeFOV(px, py, radius int) {
+	gs.reveal(px, py)
+	for _, oct := range fovOctants {
+		gs.castLight(px, py, radius, 1, 1.0, 0.0, oct)
+	}
+}
+
+// castLight scans one octant from row outwards, lighting tiles whose slope
+// from the viewer lies between start and end. A run of walls recurses into
+// the light beyond it and carries on scanning from its far edge.
+func (gs *GameState) castLight(px, py, radius, row int, start, end float64, oct [4]int) {
+	if start < end {
+		return
+	}
+	xx, xy, yx, yy := oct[0], oct[1], oct[2], oct[3]
+
+	for ; row <= radius; row++ {
+		blocked := false
+		nextStart := start
+		for col := row; col >= 0; col-- {
+			x := px + col*xx + row*xy
+			y := py + col*yx + row*yy
+			// Slopes to the tile's two far corners
+			left := (float64(col) + 0.5) / (float64(row) - 0.5)
+			right := (float64(col) - 0.5) / (float64(row) + 0.5)
+			if right > start {
+				continue
+			}
+			if left < end {
+				break
+			}
+
+			if inVisionRadius(col, row, radius) {
+				gs.reveal(x, y)
+			}
+
+			switch {
+			case blocked && gs.blocksSight(x, y):
+				nextStart = right
+			case blocked:
+				blocked = false
+				start = nextStart
+			case gs.blocksSight(x, y) && row < radius:
+				blocked = true
+				gs.castLight(px, py, radius, row+1, start, left, oct)
+				nextStart = right
+			}
+		}
+		if blocked {
+			return
+		}
+	}
+}
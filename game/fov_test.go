@@ -0,0 +1,113 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// clearLine reports whether the straight line between two tile centers
+// passes no wall, not even grazing a corner. Walls are unit squares around
+// their centers.
+func clearLine(gs *GameState, x1, y1, x2, y2 int) bool {
+	for y := min(y1, y2); y <= max(y1, y2); y++ {
+		for x := min(x1, x2); x <= max(x1, x2); x++ {
+			if gs.Dungeon.IsWalkable(x, y) {
+				continue
+			}
+			if segmentTouchesSquare(float64(x1), float64(y1), float64(x2), float64(y2), float64(x), float64(y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// segmentTouchesSquare clips the segment against the unit square centered on
+// (cx, cy), slightly enlarged so touching a corner counts
+func segmentTouchesSquare(x1, y1, x2, y2, cx, cy float64) bool {
+	const half = 0.5 + 1e-9
+	lo, hi := 0.0, 1.0
+	clip := func(p, q float64) bool {
+		if p == 0 {
+			return q >= 0
+		}
+		r := q / p
+		if p < 0 {
+			lo = max(lo, r)
+		} else {
+			hi = min(hi, r)
+		}
+		return lo <= hi
+	}
+	dx, dy := x2-x1, y2-y1
+	return clip(-dx, x1-(cx-half)) && clip(dx, (cx+half)-x1) &&
+		clip(-dy, y1-(cy-half)) && clip(dy, (cy+half)-y1)
+}
+
+func TestFOVSeesEveryTileInSight(t *testing.T) {
+	const size, radius = 21, DefaultVisionRadius
+	for pattern := int64(0); pattern < 40; pattern++ {
+		gs := newTestState(size, size)
+		rng := rand.New(rand.NewSource(pattern))
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if rng.Float64() < 0.2 {
+					gs.Dungeon.Tiles[y][x] = TileWall
+				}
+			}
+		}
+		px, py := size/2, size/2
+		gs.Dungeon.Tiles[py][px] = TileFloor
+		gs.Player.X, gs.Player.Y = px, py
+		gs.updateVisibility()
+
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if !gs.Dungeon.IsWalkable(x, y) || !inVisionRadius(x-px, y-py, radius) {
+					continue
+				}
+				if clearLine(gs, px, py, x, y) && !gs.Visible[y][x] {
+					t.Fatalf("Pattern %d: floor at (%d, %d) is in sight of (%d, %d) but not visible", pattern, x, y, px, py)
+				}
+			}
+		}
+	}
+}
+
+func TestFOVOpenRoomHasNoGaps(t *testing.T) {
+	gs := newTestState(30, 30)
+	gs.Player.X, gs.Player.Y = 15, 15
+	gs.updateVisibility()
+
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			want := inVisionRadius(x-15, y-15, DefaultVisionRadius)
+			if gs.Visible[y][x] != want {
+				t.Errorf("Tile (%d, %d): visible %v, want %v", x, y, gs.Visible[y][x], want)
+			}
+		}
+	}
+}
+
+func TestFOVWallCastsShadow(t *testing.T) {
+	gs := newTestState(20, 10)
+	addWallColumn(gs.Dungeon, 5, 0, 9)
+	gs.Player.X, gs.Player.Y = 2, 5
+	gs.updateVisibility()
+
+	if !gs.Visible[5][5] {
+		t.Error("The wall itself should be visible")
+	}
+	for x := 6; x < 10; x++ {
+		if gs.Visible[5][x] {
+			t.Errorf("Tile (%d, 5) behind the wall shouldn't be visible", x)
+		}
+	}
+}
+
+func BenchmarkUpdateVisibility(b *testing.B) {
+	gs := NewGameState(nil, 12345, 200, 100)
+	for range b.N {
+		gs.updateVisibility()
+	}
+}
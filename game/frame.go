@@ -0,0 +1,82 @@
+package game
+
+import "github.com/gdamore/tcell/v2"
+
+// cell is a single character on screen
+type cell struct {
+	ch    rune
+	style tcell.Style
+}
+
+// cellChange is a cell that differs from the previous frame
+type cellChange struct {
+	X, Y int
+	cell
+}
+
+// frameBuffer holds one rendered frame so it can be diffed against the last
+// one, letting render update only the cells that changed instead of clearing
+// the whole screen (which flickers over slow connections like SSH)
+type frameBuffer struct {
+	width, height int
+	cells         []cell
+}
+
+func newFrameBuffer(width, height int) *frameBuffer {
+	f := &frameBuffer{
+		width:  width,
+		height: height,
+		cells:  make([]cell, width*height),
+	}
+	for i := range f.cells {
+		f.cells[i] = cell{ch: ' ', style: tcell.StyleDefault}
+	}
+	return f
+}
+
+// SetContent mirrors tcell.Screen.SetContent. Off-screen cells are ignored.
+func (f *frameBuffer) SetContent(x, y int, ch rune, _ []rune, style tcell.Style) {
+	if x < 0 || y < 0 || x >= f.width || y >= f.height {
+		return
+	}
+	f.cells[y*f.width+x] = cell{ch: ch, style: style}
+}
+
+// GetContent mirrors tcell.Screen.GetContent
+func (f *frameBuffer) GetContent(x, y int) (rune, []rune, tcell.Style, int) {
+	if x < 0 || y < 0 || x >= f.width || y >= f.height {
+		return ' ', nil, tcell.StyleDefault, 1
+	}
+	c := f.cells[y*f.width+x]
+	return c.ch, nil, c.style, 1
+}
+
+// diffFrames returns the cells of next that differ from prev. Every cell is
+// returned when there is no previous frame or the screen size changed.
+func diffFrames(prev, next *frameBuffer) []cellChange {
+	full := prev == nil || prev.width != next.width || prev.height != next.height
+
+	var changes []cellChange
+	for i, c := range next.cells {
+		if !full && prev.cells[i] == c {
+			continue
+		}
+		changes = append(changes, cellChange{X: i % next.width, Y: i / next.width, cell: c})
+	}
+	return changes
+}
+
+// present draws the current frame to the screen, writing only the cells that
+// changed since the last frame unless full clearing is enabled
+func (g *Game) present() {
+	prev := g.prevFrame
+	if g.fullClear {
+		g.screen.Clear()
+		prev = nil
+	}
+
+	for _, c := range diffFrames(prev, g.frame) {
+		g.screen.SetContent(c.X, c.Y, c.ch, nil, g.resolveStyle(c.style))
+	}
+	g.prevFrame = g.frame
+}
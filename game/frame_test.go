@@ -0,0 +1,67 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestDiffFramesReturnsOnlyChangedCells(t *testing.T) {
+	prev := newFrameBuffer(10, 5)
+	prev.SetContent(1, 1, '@', nil, tcell.StyleDefault)
+	prev.SetContent(4, 2, 'b', nil, tcell.StyleDefault)
+
+	next := newFrameBuffer(10, 5)
+	next.SetContent(2, 1, '@', nil, tcell.StyleDefault) // player moved
+	next.SetContent(4, 2, 'b', nil, tcell.StyleDefault) // bug stayed put
+
+	changes := diffFrames(prev, next)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changed cells, got %d: %+v", len(changes), changes)
+	}
+
+	got := map[[2]int]rune{}
+	for _, c := range changes {
+		got[[2]int{c.X, c.Y}] = c.ch
+	}
+	if got[[2]int{1, 1}] != ' ' {
+		t.Errorf("Vacated cell should be cleared, got %q", got[[2]int{1, 1}])
+	}
+	if got[[2]int{2, 1}] != '@' {
+		t.Errorf("Player's new cell should be drawn, got %q", got[[2]int{2, 1}])
+	}
+}
+
+func TestDiffFramesDetectsStyleChanges(t *testing.T) {
+	prev := newFrameBuffer(4, 4)
+	prev.SetContent(0, 0, '#', nil, tcell.StyleDefault.Foreground(tcell.ColorWhite))
+	next := newFrameBuffer(4, 4)
+	next.SetContent(0, 0, '#', nil, tcell.StyleDefault.Foreground(tcell.ColorRed))
+
+	changes := diffFrames(prev, next)
+	if len(changes) != 1 || changes[0].X != 0 || changes[0].Y != 0 {
+		t.Errorf("Expected only the recolored wall to change, got %+v", changes)
+	}
+}
+
+func TestDiffFramesIdenticalFrames(t *testing.T) {
+	prev := newFrameBuffer(8, 3)
+	prev.SetContent(3, 1, 'X', nil, tcell.StyleDefault)
+	next := newFrameBuffer(8, 3)
+	next.SetContent(3, 1, 'X', nil, tcell.StyleDefault)
+
+	if changes := diffFrames(prev, next); len(changes) != 0 {
+		t.Errorf("Identical frames should have no changes, got %d", len(changes))
+	}
+}
+
+func TestDiffFramesRedrawsEverythingOnResize(t *testing.T) {
+	next := newFrameBuffer(6, 4)
+
+	if changes := diffFrames(nil, next); len(changes) != 6*4 {
+		t.Errorf("First frame should draw every cell, got %d", len(changes))
+	}
+	if changes := diffFrames(newFrameBuffer(5, 4), next); len(changes) != 6*4 {
+		t.Errorf("Resized frame should draw every cell, got %d", len(changes))
+	}
+}
@@ -3,22 +3,155 @@ package game
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
 
 type Game struct {
-	screen    tcell.Screen
-	state     *GameState
-	mergeMode bool
+	screen        tcell.Screen
+	state         *GameState
+	mergeMode     bool
+	demoMode      bool
+	demoEndSteps  int
+	fullClear     bool
+	enemyColors   bool // Draw each enemy type in its own color
+	playerColor   tcell.Color
+	theme         Theme
+	noColor       bool // Draw everything in the terminal's default style
+	showGraph     bool // Dependency graph overlay toggled with 'G'
+	showLevelCode bool // Shareable level code overlay toggled with 'I'
+	showSettings  bool // Settings menu toggled with 'o'
+	settingsRow   int
+	settings      Settings // In effect: the saved settings with command line flags applied
+	savedSettings Settings // As in the settings file, plus changes made in the menu
+	settingsPath  string   // Where settings changed in the menu are saved ("" = not saved)
+	library       *CodeLibrary // Code files for level backgrounds, still growing while the scan finishes
+	conflicts     []MergeConflictLocation // Files with merge conflicts, found in merge mode
+	options       *gameOptions
+	frame         *frameBuffer // Frame being drawn
+	prevFrame     *frameBuffer // Last frame sent to the screen
+	scoreRecorded bool         // The run's end has been written to the high-score file
+	newHighScore  bool         // The recorded run killed more enemies than any before it
+	aiming        bool         // 't' was pressed, so the next direction key throws
+	projectile    *pathStep    // Where the thrown duck is drawn mid-flight
+	saved         bool         // The run in play is the one kept at the save path
+	shopRow       int          // Shop menu entry under the cursor
+	travel        *pathStep    // Tile a mouse click is walking the player to
+	travelFoe     *Entity      // Enemy a mouse click is walking the player to fight
+	travelTimer   *time.Timer  // Wakes the event loop for the walk's next step
+	keys          KeyMap       // Keys rebound in the key map file
+	screenReader  bool         // Describe the surroundings in words instead of drawing the map
 }
 
 // GameOption configures Game creation
 type GameOption func(*gameOptions)
 
 type gameOptions struct {
-	mergeMode bool
+	mergeMode         bool
+	mergeForce        bool
+	noDiagonals       bool
+	persistentEnemies bool
+	mergeQueue        bool
+	mergeFireDamage   bool
+	demoMode          bool
+	potionBudget      int
+	endless           bool
+	roomErosion       float64
+	tutorial          bool
+	exploredDir       string
+	fullClear         bool
+	enemyColors       bool
+	crowdBlocksSight  bool
+	avatar            rune
+	playerColor       tcell.Color
+	startLevel        int
+	headless          bool
+	scanOrder         ScanOrder
+	mergeFireChase    bool
+	rngAlgorithm      RNGAlgorithm
+	seed              int64
+	fixedSeed         bool
+	settingsPath      string
+	rollback          bool
+	messageTurns      int
+	coop              bool
+	potionHealPercent int
+	potionTiers       bool
+	ciTraps           bool
+	leashDistance     int
+	codeSmells        bool
+	levelSummary      bool
+	ascii             bool
+	mergeCooldown     int
+	astarChase        bool
+	highScores        bool
+	difficulty        Difficulty
+	theme             Theme
+	themeSet          bool
+	savePath          string
+	resume            bool
+	resumeOverrides   []StateOption
+	cornerCutting     bool
+	noColor           bool
+	visionRadius      int
+	memoryDecay       int
+	sourceDir         string
+	maxLevel          int
+	levelWidth        int
+	levelHeight       int
+	keyMapPath        string
+	screenReader      bool
+}
+
+// configure copies state-level options onto a new GameState before its first level is generated
+func (o *gameOptions) configure(gs *GameState) {
+	gs.NoDiagonals = o.noDiagonals
+	gs.CornerCutting = o.cornerCutting
+	gs.PersistentEnemies = o.persistentEnemies
+	gs.MergeQueue = o.mergeQueue
+	gs.MergeFireDamage = o.mergeFireDamage
+	gs.PotionBudget = o.potionBudget
+	gs.Endless = o.endless
+	gs.RoomErosion = o.roomErosion
+	gs.Tutorial = o.tutorial
+	gs.ExploredDir = o.exploredDir
+	gs.CrowdBlocksSight = o.crowdBlocksSight
+	gs.Avatar = o.avatar
+	gs.MergeFireChase = o.mergeFireChase
+	gs.RNGAlgorithm = o.rngAlgorithm
+	gs.Coop = o.coop
+	gs.PotionHealPercent = o.potionHealPercent
+	gs.PotionTiers = o.potionTiers
+	gs.CITraps = o.ciTraps
+	gs.LeashDistance = o.leashDistance
+	gs.CodeSmellsEnabled = o.codeSmells
+	gs.ShowLevelSummary = o.levelSummary
+	gs.ASCII = o.ascii
+	gs.MergeCooldown = o.mergeCooldown
+	gs.AStarChase = o.astarChase
+	gs.Difficulty = o.difficulty
+	gs.MemoryDecay = o.memoryDecay
+	if o.visionRadius > 0 {
+		gs.VisionRadius = o.visionRadius
+	}
+	if o.rollback {
+		gs.RollbacksLeft = MaxRollbacks
+	}
+	if o.messageTurns > 0 {
+		gs.MessageTurns = o.messageTurns
+	}
+	if o.maxLevel > 0 {
+		gs.MaxLevel = o.maxLevel
+	}
+	gs.LevelWidth, gs.LevelHeight = o.levelWidth, o.levelHeight
+	if o.startLevel > 0 {
+		gs.Level = o.startLevel
+		// Level codes can point past the final level in endless mode
+		gs.EndlessDepth = max(gs.Level-gs.MaxLevel, 0)
+	}
 }
 
 // WithMergeMode enables merge conflict display mode
@@ -28,6 +161,387 @@ func WithMergeMode(enabled bool) GameOption {
 	}
 }
 
+// WithMergeForce keeps merge mode on even when no merge conflict is found in the repository
+func WithMergeForce(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.mergeForce = enabled
+	}
+}
+
+// WithNoDiagonals restricts the player and enemies to 4-directional movement
+func WithNoDiagonals(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.noDiagonals = enabled
+	}
+}
+
+// WithCornerCutting lets the player and enemies step diagonally between two wall corners
+func WithCornerCutting(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.cornerCutting = enabled
+	}
+}
+
+// WithVisionRadius sets how far the player sees, in tiles (0 = DefaultVisionRadius)
+func WithVisionRadius(radius int) GameOption {
+	return func(o *gameOptions) {
+		o.visionRadius = radius
+	}
+}
+
+// WithMemoryDecay forgets explored tiles once they've been out of sight for turns turns (0 = never)
+func WithMemoryDecay(turns int) GameOption {
+	return func(o *gameOptions) {
+		o.memoryDecay = turns
+	}
+}
+
+// WithSourceDir builds the dungeon from the code in dir instead of the current directory
+func WithSourceDir(dir string) GameOption {
+	return func(o *gameOptions) {
+		o.sourceDir = dir
+	}
+}
+
+// WithKeyMapFile reads rebound keys from path, keeping the defaults if it doesn't exist
+func WithKeyMapFile(path string) GameOption {
+	return func(o *gameOptions) {
+		o.keyMapPath = path
+	}
+}
+
+// WithPersistentEnemies keeps alerted enemies hunting the player after losing sight of them
+func WithPersistentEnemies(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.persistentEnemies = enabled
+	}
+}
+
+// WithMergeQueue makes the merge marker spawn a wave of conflicting commits when triggered
+func WithMergeQueue(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.mergeQueue = enabled
+	}
+}
+
+// WithMergeFireDamage makes the whole merge conflict fire damage the player each turn
+func WithMergeFireDamage(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.mergeFireDamage = enabled
+	}
+}
+
+// WithPotionBudget limits the total number of potions spawned across the run (0 = unlimited)
+func WithPotionBudget(budget int) GameOption {
+	return func(o *gameOptions) {
+		o.potionBudget = budget
+	}
+}
+
+// WithMaxLevel sets how many levels deep a run goes before victory (0 = DefaultMaxLevel)
+func WithMaxLevel(levels int) GameOption {
+	return func(o *gameOptions) {
+		o.maxLevel = levels
+	}
+}
+
+// WithLevelCode replays the level a shared code points to. The code carries
+// everything the level was generated from, so it overrides the seed, start
+// level, RNG algorithm, difficulty, run length, level size, room erosion, CI
+// traps and potion tiers.
+func WithLevelCode(c LevelCode) GameOption {
+	return func(o *gameOptions) {
+		o.seed, o.fixedSeed = c.Seed, true
+		o.startLevel = c.Level
+		o.rngAlgorithm = c.RNG
+		o.difficulty = c.Difficulty
+		o.maxLevel = c.MaxLevel
+		o.levelWidth, o.levelHeight = c.Width, c.Height
+		o.roomErosion = c.RoomErosion
+		o.ciTraps = c.CITraps
+		o.potionTiers = c.PotionTiers
+	}
+}
+
+// WithLevelSize generates every level at a fixed size instead of fitting the
+// terminal, as a shared level code needs (0 = fit)
+func WithLevelSize(width, height int) GameOption {
+	return func(o *gameOptions) {
+		o.levelWidth, o.levelHeight = width, height
+	}
+}
+
+// WithEndless keeps generating deeper levels after MaxLevel instead of ending in victory
+func WithEndless(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.endless = enabled
+	}
+}
+
+// WithRoomErosion roughens room outlines by carving wall tiles around rooms with the given chance (0-1)
+func WithRoomErosion(chance float64) GameOption {
+	return func(o *gameOptions) {
+		o.roomErosion = chance
+	}
+}
+
+// WithCrowdBlocksSight lets enemies block each other's line of sight to the player
+func WithCrowdBlocksSight(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.crowdBlocksSight = enabled
+	}
+}
+
+// WithAvatar replaces the player's '@' with a custom symbol
+func WithAvatar(symbol rune) GameOption {
+	return func(o *gameOptions) {
+		o.avatar = symbol
+	}
+}
+
+// WithPlayerColor draws the player in a custom color instead of white
+func WithPlayerColor(color tcell.Color) GameOption {
+	return func(o *gameOptions) {
+		o.playerColor = color
+	}
+}
+
+// WithStartLevel begins the run at the given level instead of level 1
+func WithStartLevel(level int) GameOption {
+	return func(o *gameOptions) {
+		o.startLevel = level
+	}
+}
+
+// WithSeed replays a specific run seed instead of the one computed from the repository
+func WithSeed(seed int64) GameOption {
+	return func(o *gameOptions) {
+		o.seed = seed
+		o.fixedSeed = true
+	}
+}
+
+// WithSettingsFile loads in-game settings from path and saves changes made in the settings menu back to it
+func WithSettingsFile(path string) GameOption {
+	return func(o *gameOptions) {
+		o.settingsPath = path
+	}
+}
+
+// WithRollback lets a dead player roll back to the start of the level instead of losing the run, once per run
+func WithRollback(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.rollback = enabled
+	}
+}
+
+// WithMessageTurns sets how many turns a message stays up before the next queued one replaces it
+func WithMessageTurns(turns int) GameOption {
+	return func(o *gameOptions) {
+		o.messageTurns = turns
+	}
+}
+
+// WithCoop adds a second local player, moved with WASD, who shares the dungeon
+func WithCoop(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.coop = enabled
+	}
+}
+
+// WithPotionHealPercent makes potions heal percent of the player's max HP instead of a flat amount (0 = flat)
+func WithPotionHealPercent(percent int) GameOption {
+	return func(o *gameOptions) {
+		o.potionHealPercent = percent
+	}
+}
+
+// WithPotionTiers spawns big and huge potions as well as small ones. It's an
+// option so the potions rolled for a seed stay the same without it.
+func WithPotionTiers(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.potionTiers = enabled
+	}
+}
+
+// WithCITraps hides a CI trap on some levels, which reshuffles the enemies
+// and briefly blinds the player
+func WithCITraps(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.ciTraps = enabled
+	}
+}
+
+// WithLeash makes alerted enemies give up and return home once they chase more than distance tiles from it (0 = never)
+func WithLeash(distance int) GameOption {
+	return func(o *gameOptions) {
+		o.leashDistance = distance
+	}
+}
+
+// WithCodeSmells fills some rooms with a code smell haze that cuts the player's vision inside them
+func WithCodeSmells(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.codeSmells = enabled
+	}
+}
+
+// WithLevelSummary shows a summary of each finished level's stats on the way down
+func WithLevelSummary(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.levelSummary = enabled
+	}
+}
+
+// WithASCII draws the game with plain ASCII instead of box-drawing and other Unicode glyphs
+func WithASCII(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.ascii = enabled
+	}
+}
+
+// WithMergeCooldown makes tiles torn apart by the merge marker heal back to
+// floor after turns turns with the player off the marker, and fire spread by
+// WithMergeFireChase go out after turns turns off the trap (0 = never)
+func WithMergeCooldown(turns int) GameOption {
+	return func(o *gameOptions) {
+		o.mergeCooldown = turns
+	}
+}
+
+// WithAStarChase makes enemies that can see the player path around walls to
+// them with A* instead of stepping straight at them
+func WithAStarChase(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.astarChase = enabled
+	}
+}
+
+// WithHighScores records each finished run in the high-score file
+func WithHighScores(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.highScores = enabled
+	}
+}
+
+// WithDifficulty scales enemies, potions and the player's starting HP
+func WithDifficulty(difficulty Difficulty) GameOption {
+	return func(o *gameOptions) {
+		o.difficulty = difficulty
+	}
+}
+
+// WithTheme draws the dungeon in a theme's colors instead of the default or
+// saved ones
+func WithTheme(theme Theme) GameOption {
+	return func(o *gameOptions) {
+		o.theme = theme
+		o.themeSet = true
+	}
+}
+
+// WithNoColor draws everything in the terminal's default style, telling the
+// map apart by symbols alone, for terminals that can't show color
+func WithNoColor(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.noColor = enabled
+	}
+}
+
+// WithScreenReader draws a description of the player's surroundings, in
+// plain lines of text, instead of the map
+func WithScreenReader(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.screenReader = enabled
+	}
+}
+
+// WithSaveFile saves the run to path when the save key is pressed
+func WithSaveFile(path string) GameOption {
+	return func(o *gameOptions) {
+		o.savePath = path
+	}
+}
+
+// WithContinue resumes the run saved with WithSaveFile instead of starting a new one
+func WithContinue(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.resume = enabled
+	}
+}
+
+// WithResumeOverrides changes a run resumed with WithContinue, which
+// otherwise keeps the options it was saved with. Pass the options given
+// explicitly on the command line, so they take effect on the resumed run too.
+func WithResumeOverrides(overrides ...StateOption) GameOption {
+	return func(o *gameOptions) {
+		o.resumeOverrides = overrides
+	}
+}
+
+// WithTutorial plays the hand-authored tutorial lessons, a level each, instead of a generated dungeon
+func WithTutorial(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.tutorial = enabled
+	}
+}
+
+// WithExploredDir remembers each level's explored map in dir, keyed by seed and level
+func WithExploredDir(dir string) GameOption {
+	return func(o *gameOptions) {
+		o.exploredDir = dir
+	}
+}
+
+// WithFullClear clears and redraws the whole screen every frame instead of only changed cells
+func WithFullClear(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.fullClear = enabled
+	}
+}
+
+// WithEnemyColors draws each enemy type in its own color instead of all in red
+func WithEnemyColors(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.enemyColors = enabled
+	}
+}
+
+// WithHeadless renders to an in-memory screen so the game can run without a TTY
+func WithHeadless(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.headless = enabled
+	}
+}
+
+// WithScanOrder chooses which code files the levels are built from
+func WithScanOrder(order ScanOrder) GameOption {
+	return func(o *gameOptions) {
+		o.scanOrder = order
+	}
+}
+
+// WithMergeFireChase makes merge conflict fire spread toward the player each turn
+func WithMergeFireChase(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.mergeFireChase = enabled
+	}
+}
+
+// WithRNGAlgorithm chooses the random source dungeons are generated from
+func WithRNGAlgorithm(algorithm RNGAlgorithm) GameOption {
+	return func(o *gameOptions) {
+		o.rngAlgorithm = algorithm
+	}
+}
+
+// WithDemoMode lets the computer play the game as an attract loop
+func WithDemoMode(enabled bool) GameOption {
+	return func(o *gameOptions) {
+		o.demoMode = enabled
+	}
+}
+
 func New(opts ...GameOption) (*Game, error) {
 	// Apply options
 	options := &gameOptions{}
@@ -35,50 +549,157 @@ func New(opts ...GameOption) (*Game, error) {
 		opt(options)
 	}
 
-	// Find code files in current directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		cwd = "."
+	// Find code files in the source directory, or else the current one
+	root := options.sourceDir
+	var err error
+	if root == "" {
+		if root, err = os.Getwd(); err != nil {
+			root = "."
+		}
+	} else if err = CheckSourceDir(root); err != nil {
+		return nil, err
 	}
 
-	codeFiles, err := findCodeFiles(cwd, 60, 5)
-	if err != nil {
-		return nil, fmt.Errorf("scanning code files: %w", err)
+	// The tutorial is hand-authored, so skip scanning for code files. Otherwise
+	// scan in the background while merge conflicts are looked for.
+	var library *CodeLibrary
+	if !options.tutorial {
+		library = scanCodeLibrary(root, 60, QuickScanFiles, MaxCodeFiles, options.scanOrder)
 	}
 
 	// Find merge conflict location if in merge mode
-	var mergeConflict *MergeConflictLocation
+	var mergeConflicts []MergeConflictLocation
+	mergeNotice := ""
 	if options.mergeMode {
-		mergeConflict = findMergeConflict(cwd)
+		mergeConflicts = findMergeConflicts(root)
+		// Without a real conflict the marker would point at nothing, so fall back to normal mode
+		if len(mergeConflicts) == 0 && !options.mergeForce {
+			options.mergeMode = false
+			mergeNotice = "No merge conflicts found. Playing in normal mode."
+		} else if len(mergeConflicts) > 0 {
+			mergeNotice = fmt.Sprintf("%s: %s", conflictCount(len(mergeConflicts)), strings.Join(conflictedFiles(mergeConflicts), ", "))
+		}
+	}
+
+	// The run starts from the quick set, which also feeds the seed; the rest
+	// of the scan carries on in the background for deeper levels
+	var codeFiles []CodeFile
+	if library != nil {
+		if codeFiles, err = library.QuickFiles(); err != nil {
+			return nil, fmt.Errorf("scanning %s for code files: %w", root, err)
+		}
 	}
 
 	// Compute seed from code files
-	seed := computeSeed(codeFiles)
+	seed := computeSeed(root, codeFiles)
 	if len(codeFiles) == 0 {
 		seed = 42 // Default seed if no code files found
 	}
+	if options.fixedSeed {
+		seed = options.seed
+	}
 
-	screen, err := tcell.NewScreen()
-	if err != nil {
-		return nil, fmt.Errorf("creating screen: %w", err)
+	settings := DefaultSettings()
+	settingsNotice := ""
+	if options.settingsPath != "" {
+		// A broken settings file shouldn't stop the game; it's replaced the
+		// next time a setting is changed
+		if settings, err = LoadSettings(options.settingsPath); err != nil {
+			settingsNotice = fmt.Sprintf("Couldn't load your settings, so the defaults are in use: %v", err)
+		}
+	}
+	savedSettings := settings
+	keys := KeyMap{}
+	if options.keyMapPath != "" {
+		keys, err = LoadKeyMap(options.keyMapPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading key map: %w", err)
+		}
 	}
 
-	if err := screen.Init(); err != nil {
-		return nil, fmt.Errorf("initializing screen: %w", err)
+	// Flags given on the command line win over saved settings
+	if options.noDiagonals {
+		settings.Diagonals = false
+	}
+	if options.enemyColors {
+		settings.EnemyColors = true
+	}
+	if options.themeSet {
+		settings.Theme = options.theme
 	}
 
-	screen.SetStyle(tcell.StyleDefault.Foreground(tcell.ColorWhite))
+	var screen tcell.Screen
+	if options.headless {
+		screen, err = newHeadlessScreen()
+		if err != nil {
+			return nil, fmt.Errorf("initializing headless screen: %w", err)
+		}
+	} else {
+		screen, err = tcell.NewScreen()
+		if err != nil {
+			return nil, fmt.Errorf("creating screen: %w", err)
+		}
+
+		if err := screen.Init(); err != nil {
+			return nil, fmt.Errorf("initializing screen: %w", err)
+		}
+	}
+
+	if options.noColor {
+		screen.SetStyle(tcell.StyleDefault)
+	} else {
+		screen.SetStyle(tcell.StyleDefault.Foreground(tcell.ColorWhite))
+	}
 	screen.Clear()
+	screen.EnableMouse(tcell.MouseButtonEvents)
 
-	width, height := screen.Size()
-	state := NewGameState(codeFiles, seed, width, height)
-	state.MergeConflict = mergeConflict
+	g := &Game{
+		screen:        screen,
+		mergeMode:     options.mergeMode,
+		demoMode:      options.demoMode,
+		fullClear:     options.fullClear,
+		enemyColors:   options.enemyColors,
+		playerColor:   options.playerColor,
+		noColor:       options.noColor,
+		library:       library,
+		conflicts:     mergeConflicts,
+		options:       options,
+		settings:      settings,
+		savedSettings: savedSettings,
+		settingsPath:  options.settingsPath,
+		keys:          keys,
+		screenReader:  options.screenReader,
+	}
+	if options.resume {
+		g.state, err = g.loadSavedState()
+		if err != nil {
+			screen.Fini()
+			return nil, fmt.Errorf("continuing saved game: %w", err)
+		}
+		g.saved = true
+	} else {
+		g.state = g.newState(seed)
+	}
+	g.applySettings()
+	if mergeNotice != "" {
+		// Takes over from the level title, which the status bar still shows
+		g.state.SetAlert(mergeNotice, tcell.Style{})
+	}
+	if settingsNotice != "" {
+		g.state.SetMessage(settingsNotice)
+	}
+	return g, nil
+}
 
-	return &Game{
-		screen:    screen,
-		state:     state,
-		mergeMode: options.mergeMode,
-	}, nil
+// newState starts a fresh run sized to the current screen
+func (g *Game) newState(seed int64) *GameState {
+	width, height := g.screen.Size()
+	state := NewGameState(nil, seed, width, height, g.options.configure, func(gs *GameState) {
+		gs.CodeLibrary = g.library
+		gs.MergeConflicts = g.conflicts
+	})
+	state.announceLevelFile("You enter %s...")
+	return state
 }
 
 func (g *Game) Close() {
@@ -88,134 +709,301 @@ func (g *Game) Close() {
 }
 
 func (g *Game) Run() error {
+	if g.demoMode {
+		done := make(chan struct{})
+		defer close(done)
+		go g.runDemoTicker(done)
+	}
+
+	// Remember what was explored on the level the game ends on. g.state is
+	// replaced when a new run starts, so look it up only once the game ends.
+	defer func() { g.state.SaveExplored() }()
+
 	for {
 		g.render()
 		g.screen.Show()
 
-		ev := g.screen.PollEvent()
-		switch ev := ev.(type) {
-		case *tcell.EventResize:
-			g.screen.Sync()
-			width, height := g.screen.Size()
-			g.state.Resize(width, height)
-		case *tcell.EventKey:
-			if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC {
-				return nil
-			}
-			if ev.Rune() == 'q' || ev.Rune() == 'Q' {
-				return nil
-			}
+		if g.handleEvent(g.screen.PollEvent()) {
+			return nil
+		}
+	}
+}
 
-			if g.state.GameOver || g.state.Victory {
-				// Any key to exit on game over/victory
-				if ev.Key() == tcell.KeyEnter || ev.Rune() == ' ' {
-					return nil
-				}
-				continue
-			}
+// handleEvent applies a terminal event to the game and reports whether the game should exit
+func (g *Game) handleEvent(ev tcell.Event) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventResize:
+		g.screen.Sync()
+		width, height := g.screen.Size()
+		g.state.Resize(width, height)
+	case *tcell.EventInterrupt:
+		if g.demoMode {
+			g.demoStep()
+		} else if g.travelStep() {
+			g.continueTravel()
+		}
+	case *tcell.EventMouse:
+		if !g.demoMode {
+			g.handleMouse(ev)
+		}
+	case *tcell.EventKey:
+		// Any key ends the demo
+		if g.demoMode {
+			return true
+		}
+		// and stops a walk to a clicked tile
+		g.stopTravel()
+		if g.handleKey(ev) {
+			return true
+		}
+	}
+	g.recordScore()
+	g.discardFinishedSave()
+	return false
+}
 
-			// Movement
-			dx, dy := 0, 0
-			konamiKey := ""
-			switch ev.Key() {
-			case tcell.KeyUp:
-				dy = -1
-				konamiKey = "up"
-			case tcell.KeyDown:
-				dy = 1
-				konamiKey = "down"
-			case tcell.KeyLeft:
-				dx = -1
-				konamiKey = "left"
-			case tcell.KeyRight:
-				dx = 1
-				konamiKey = "right"
-			default:
-				switch ev.Rune() {
-				case 'h', 'a':
-					dx = -1
-					if ev.Rune() == 'a' {
-						konamiKey = "a"
-					}
-				case 'l', 'd':
-					dx = 1
-				case 'k', 'w':
-					dy = -1
-				case 'j', 's':
-					dy = 1
-				case 'y': // diagonal up-left
-					dx, dy = -1, -1
-				case 'u': // diagonal up-right
-					dx, dy = 1, -1
-				case 'b': // diagonal down-left
-					dx, dy = -1, 1
-					konamiKey = "b"
-				case 'n': // diagonal down-right
-					dx, dy = 1, 1
-				}
-			}
+// handleKey applies a key press to the game and reports whether the game should exit
+func (g *Game) handleKey(ev *tcell.EventKey) bool {
+	// The settings menu takes every key but quit while it's open. Esc closes
+	// it rather than quitting, so only typed quit keys get through.
+	quit := g.keys.Is(ev, ActionQuit)
+	menuQuit := quit && ev.Key() == tcell.KeyRune
+	if g.showSettings && !menuQuit {
+		g.handleSettingsKey(ev)
+		return false
+	}
+	// So does the shop, once any level summary has been dismissed
+	if g.state.ShopOpen && g.state.LevelSummary == nil && !menuQuit {
+		g.handleShopKey(ev)
+		return false
+	}
 
-			// Check for Konami code
-			if konamiKey != "" {
-				g.state.CheckKonamiCode(konamiKey)
-			}
+	if quit || ev.Key() == tcell.KeyCtrlC {
+		return true
+	}
 
-			if dx != 0 || dy != 0 {
-				g.state.MovePlayer(dx, dy)
-			}
+	// The level summary stays up until any key dismisses it
+	if g.state.LevelSummary != nil {
+		g.state.LevelSummary = nil
+		return false
+	}
+
+	if (ev.Rune() == 'r' || ev.Rune() == 'R') && g.state.CanRollback() {
+		g.state.Rollback()
+		return false
+	}
+
+	if g.state.GameOver || g.state.Victory {
+		// Any key to exit on game over/victory
+		return ev.Key() == tcell.KeyEnter || ev.Rune() == ' '
+	}
+
+	// Cycle through the level's merge conflicts
+	if ev.Key() == tcell.KeyTab && g.mergeMode {
+		g.state.CycleMergeFocus()
+		return false
+	}
+
+	// The hotfix flash lasts until the next key press
+	g.state.HotfixFlash = false
+
+	// A throw is being aimed, so this key picks the direction
+	if g.aiming {
+		g.handleAimKey(ev)
+		return false
+	}
+
+	// In pair programming mode WASD belongs to the second player
+	if dir, ok := player2Keys[ev.Rune()]; ok && g.state.Player2 != nil {
+		g.state.MovePlayer2(dir[0], dir[1])
+		return false
+	}
+
+	// Waiting and movement come before the fixed commands below, so a key
+	// rebound in the key map takes over any command it had
+	if g.keys.Is(ev, ActionWait) {
+		g.state.Wait()
+		return false
+	}
+	if g.handleMoveKey(ev) {
+		return false
+	}
+
+	// Aim a rubber duck
+	if ev.Rune() == 't' {
+		if g.state.Throws == 0 {
+			g.state.SetMessage("You're out of rubber ducks to throw.")
+		} else {
+			g.aiming = true
+			g.state.SetMessage("Throw a rubber duck which way? (any other key to cancel)")
 		}
+		return false
+	}
+
+	// Toggle combat stance
+	if ev.Rune() == 'f' {
+		g.state.ToggleStance()
+		return false
+	}
+
+	// Examine adjacent enemies
+	if ev.Rune() == 'e' {
+		g.state.Examine()
+		return false
+	}
+
+	// Deploy a hotfix
+	if ev.Rune() == 'H' {
+		g.state.UseHotfix()
+		return false
+	}
+
+	// Pick up the potion underfoot
+	if ev.Rune() == ',' {
+		g.state.PickUp()
+		return false
+	}
+
+	// Use an inventory slot
+	if r := ev.Rune(); r >= '1' && r <= '9' {
+		g.state.UseItem(int(r - '1'))
+		return false
 	}
+
+	// Drink the carried potion that tops off HP with the least waste
+	if ev.Rune() == 'p' {
+		g.state.QuickHeal()
+		return false
+	}
+
+	// Save the game to pick up later with --continue
+	if ev.Rune() == 'S' {
+		g.saveGame()
+		return false
+	}
+
+	// Open the settings menu
+	if ev.Rune() == 'o' {
+		g.showSettings = true
+		return false
+	}
+
+	// Toggle the shareable level code overlay
+	if ev.Rune() == 'I' && !g.state.Tutorial {
+		g.showLevelCode = !g.showLevelCode
+		return false
+	}
+
+	// Toggle the room dependency graph overlay
+	if ev.Rune() == 'G' {
+		g.showGraph = !g.showGraph
+		return false
+	}
+
+	return false
 }
 
-func (g *Game) render() {
-	g.screen.Clear()
+// handleMoveKey moves the player for a movement key, and feeds the Konami
+// code. Reports whether the key was a movement key.
+func (g *Game) handleMoveKey(ev *tcell.EventKey) bool {
+	dx, dy := g.keys.direction(ev)
+	konamiKey := ""
+	switch {
+	case ev.Key() == tcell.KeyUp:
+		konamiKey = "up"
+	case ev.Key() == tcell.KeyDown:
+		konamiKey = "down"
+	case ev.Key() == tcell.KeyLeft:
+		konamiKey = "left"
+	case ev.Key() == tcell.KeyRight:
+		konamiKey = "right"
+	case ev.Rune() == 'a':
+		konamiKey = "a"
+	case ev.Rune() == 'b':
+		konamiKey = "b"
+	}
 
-	width, height := g.screen.Size()
-	dungeon := g.state.Dungeon
+	// Check for Konami code
+	if konamiKey != "" {
+		g.state.CheckKonamiCode(konamiKey)
+	}
 
-	// Calculate offsets to center the dungeon
-	offsetX := (width - dungeon.Width) / 2
-	offsetY := (height - dungeon.Height - 3) / 2 // -3 for UI bar and message
-	if offsetX < 0 {
-		offsetX = 0
+	if dx == 0 && dy == 0 {
+		return false
 	}
-	if offsetY < 0 {
-		offsetY = 0
+	// Ignore diagonal keys in 4-directional mode
+	if !g.state.NoDiagonals || dx == 0 || dy == 0 {
+		g.state.MovePlayer(dx, dy)
 	}
+	return true
+}
 
-	// Styles - walls turn red (visible) or orange (fog) when merge conflict triggered
-	var wallStyle, fogWallStyle tcell.Style
-	if g.state.MergeConflictTriggered {
-		wallStyle = tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack)
-		fogWallStyle = tcell.StyleDefault.Foreground(tcell.ColorOrange).Background(tcell.ColorBlack)
+func (g *Game) render() {
+	width, height := g.screen.Size()
+	g.frame = newFrameBuffer(width, height)
+	if g.screenReader {
+		g.drawDescription(width, height)
 	} else {
-		wallStyle = tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
-		fogWallStyle = tcell.StyleDefault.Foreground(tcell.Color240).Background(tcell.ColorBlack)
-	}
-	uiStyle := tcell.StyleDefault.Foreground(tcell.ColorLightGreen).Background(tcell.ColorBlack)
-	codeStyle := tcell.StyleDefault.Foreground(tcell.Color238).Background(tcell.ColorBlack)
-	playerStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack).Bold(true)
-	enemyStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack)
-	potionStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
-	doorStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack).Bold(true)
-	fogStyle := tcell.StyleDefault.Foreground(tcell.Color240).Background(tcell.ColorBlack)
-	mergeAffectedStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true)
-
-	// Get code lines for background
-	var codeLines []string
-	if dungeon.CodeFile != nil && len(dungeon.CodeFile.Lines) > 0 {
-		codeLines = dungeon.CodeFile.Lines
-	}
-
-	// Render dungeon
-	for y := 0; y < min(dungeon.Height, height-2); y++ {
-		for x := 0; x < min(dungeon.Width, width); x++ {
+		g.draw(width, height)
+	}
+	g.present()
+}
+
+// mapOffset returns where the dungeon's top left corner is drawn on a view
+// of the given size, which may be off screen when following the player
+func (g *Game) mapOffset(viewWidth, viewHeight int) (int, int) {
+	player := g.state.Player
+	return cameraOffset(viewWidth, g.state.Dungeon.Width, player.X),
+		cameraOffset(viewHeight, g.state.Dungeon.Height, player.Y)
+}
+
+// cameraOffset works out one axis of the map offset. A map that fits in the
+// view is centered in it; a larger one scrolls to keep the player in the
+// middle, stopping at the map's edges so no space is wasted past them.
+func cameraOffset(view, size, player int) int {
+	if size <= view {
+		return (view - size) / 2
+	}
+	return min(max(view/2-player, view-size), 0)
+}
+
+// draw renders the game into the current frame buffer
+func (g *Game) draw(width, height int) {
+	dungeon := g.state.Dungeon
+
+	// Center the dungeon, or follow the player if it doesn't fit on screen
+	viewHeight := max(height-3, 0) // -3 for UI bar and message
+	offsetX, offsetY := g.mapOffset(width, viewHeight)
+
+	// Styles come from the theme - walls change color when merge conflict triggered
+	theme := g.theme.palette()
+	wallStyle := theme.wallStyle(true, g.state.MergeConflictTriggered)
+	fogWallStyle := theme.wallStyle(false, g.state.MergeConflictTriggered)
+	uiStyle := theme.ui
+	codeStyle := theme.code
+	playerStyle := theme.player
+	if g.playerColor != tcell.ColorDefault {
+		playerStyle = playerStyle.Foreground(g.playerColor)
+	}
+	potionStyle := theme.potion
+	doorStyle := theme.door
+	lintStyle := theme.lint
+	ciStyle := theme.ci
+	portalStyle := theme.portal
+	fogStyle := theme.fog
+	mergeAffectedStyle := theme.mergeAffected
+	hotfixFlashStyle := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorYellow)
+	smellStyle := theme.smell
+
+	// Render the part of the dungeon in view
+	for y := max(-offsetY, 0); y < min(dungeon.Height, viewHeight-offsetY); y++ {
+		for x := max(-offsetX, 0); x < min(dungeon.Width, width-offsetX); x++ {
 			tile := dungeon.Tiles[y][x]
 			visible := g.state.Visible[y][x]
-			explored := g.state.Explored[y][x]
+			explored := g.state.Remembers(x, y)
 
 			if !explored {
-				g.screen.SetContent(offsetX+x, offsetY+y, ' ', nil, tcell.StyleDefault)
+				g.frame.SetContent(offsetX+x, offsetY+y, ' ', nil, tcell.StyleDefault)
 				continue
 			}
 
@@ -231,24 +1019,13 @@ func (g *Game) render() {
 					style = fogWallStyle
 				}
 			case TileFloor:
-				// Show code character if available (2x density)
-				if len(codeLines) > 0 {
-					// Use both y and x/40 to show 2x more code lines
-					lineIdx := (y*2 + x/40) % len(codeLines)
-					line := codeLines[lineIdx]
-					charIdx := x % 40
-					if x >= 40 {
-						charIdx = x - 40
-					}
-					if charIdx < len(line) {
-						ch = rune(line[charIdx])
-					} else {
-						ch = '.'
-					}
-				} else {
-					ch = '.'
+				ch = dungeon.FloorChar(x, y)
+				if g.noColor {
+					ch = '.' // Without color, code on the floor would look like entities
 				}
-				if visible {
+				if visible && g.state.InCodeSmell(x, y) {
+					style = smellStyle
+				} else if visible {
 					style = codeStyle
 				} else {
 					style = fogStyle
@@ -260,6 +1037,27 @@ func (g *Game) render() {
 				} else {
 					style = fogStyle
 				}
+			case TilePortal:
+				ch = 'O'
+				if visible {
+					style = portalStyle
+				} else {
+					style = fogStyle
+				}
+			case TileLint:
+				ch = '!'
+				if visible {
+					style = lintStyle
+				} else {
+					style = fogStyle
+				}
+			case TileCI:
+				ch = '%'
+				if visible {
+					style = ciStyle
+				} else {
+					style = fogStyle
+				}
 			}
 
 			// Override style for merge-affected tiles (show in red with conflict chars)
@@ -270,38 +1068,169 @@ func (g *Game) render() {
 				ch = conflictChars[(x+y+g.state.MergeAnimationStep)%len(conflictChars)]
 			}
 
-			g.screen.SetContent(offsetX+x, offsetY+y, ch, nil, style)
+			// A freshly deployed hotfix lights up everything in view
+			if g.state.HotfixFlash && g.settings.Flash && visible {
+				style = hotfixFlashStyle
+			}
+
+			g.frame.SetContent(offsetX+x, offsetY+y, ch, nil, style)
 		}
 	}
 
 	// Render potions
 	for _, potion := range g.state.Potions {
 		if g.state.Visible[potion.Y][potion.X] {
-			g.screen.SetContent(offsetX+potion.X, offsetY+potion.Y, potion.Symbol, nil, potionStyle)
+			g.frame.SetContent(offsetX+potion.X, offsetY+potion.Y, potion.Symbol, nil, potionStyle)
 		}
 	}
 	
+	// Render hotfixes
+	hotfixStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack).Bold(true)
+	for _, hotfix := range g.state.Hotfixes {
+		if g.state.Visible[hotfix.Y][hotfix.X] {
+			g.frame.SetContent(offsetX+hotfix.X, offsetY+hotfix.Y, hotfix.Symbol, nil, hotfixStyle)
+		}
+	}
+
+	// Render armor
+	armorStyle := tcell.StyleDefault.Foreground(tcell.ColorSilver).Background(tcell.ColorBlack).Bold(true)
+	for _, armor := range g.state.Armor {
+		if g.state.Visible[armor.Y][armor.X] {
+			g.frame.SetContent(offsetX+armor.X, offsetY+armor.Y, armor.Symbol, nil, armorStyle)
+		}
+	}
+
+	// Render gold
+	goldStyle := tcell.StyleDefault.Foreground(tcell.ColorGold).Background(tcell.ColorBlack).Bold(true)
+	for _, pile := range g.state.GoldPiles {
+		if g.state.Visible[pile.Y][pile.X] {
+			g.frame.SetContent(offsetX+pile.X, offsetY+pile.Y, pile.Symbol, nil, goldStyle)
+		}
+	}
+
+	// Render torches
+	torchStyle := tcell.StyleDefault.Foreground(tcell.ColorOrange).Background(tcell.ColorBlack).Bold(true)
+	for _, torch := range g.state.Torches {
+		if g.state.Visible[torch.Y][torch.X] {
+			g.frame.SetContent(offsetX+torch.X, offsetY+torch.Y, torch.Symbol, nil, torchStyle)
+		}
+	}
+
+	// Render weapons
+	weaponStyle := tcell.StyleDefault.Foreground(tcell.ColorLightSteelBlue).Background(tcell.ColorBlack).Bold(true)
+	for _, weapon := range g.state.Weapons {
+		if g.state.Visible[weapon.Y][weapon.X] {
+			g.frame.SetContent(offsetX+weapon.X, offsetY+weapon.Y, weapon.Symbol, nil, weaponStyle)
+		}
+	}
+
+	// Render reverts
+	revertStyle := tcell.StyleDefault.Foreground(tcell.ColorAqua).Background(tcell.ColorBlack).Bold(true)
+	for _, revert := range g.state.Reverts {
+		if g.state.Visible[revert.Y][revert.X] {
+			g.frame.SetContent(offsetX+revert.X, offsetY+revert.Y, revert.Symbol, nil, revertStyle)
+		}
+	}
+
 	// Render merge conflict if it has been triggered (fire persists after leaving)
 	if g.state.MergeConflictTriggered {
 		g.renderMergeConflict(offsetX, offsetY)
 	}
 
-	// Render enemies
+	// Render the fleeing boss's stack trace
+	stackStyle := tcell.StyleDefault.Foreground(tcell.ColorOrange).Background(tcell.ColorBlack)
+	for _, frame := range g.state.StackTrace {
+		if g.state.Visible[frame.Y][frame.X] {
+			g.frame.SetContent(offsetX+frame.X, offsetY+frame.Y, g.state.Glyphs().StackFrame, nil, stackStyle)
+		}
+	}
+
+	if g.showGraph {
+		g.renderRoomGraph(offsetX, offsetY)
+	}
+
+	// Render enemies, underlining the ones that can see the player
+	hunting := g.state.HuntingEnemies()
 	for _, enemy := range g.state.Enemies {
 		if enemy.IsAlive() && g.state.Visible[enemy.Y][enemy.X] {
-			g.screen.SetContent(offsetX+enemy.X, offsetY+enemy.Y, enemy.Symbol, nil, enemyStyle)
+			style := theme.enemy
+			if g.enemyColors {
+				style = enemyStyle(enemy.Type, true)
+			}
+			if hunting[enemy] {
+				style = style.Underline(true)
+			}
+			if g.noColor {
+				style = style.Reverse(true) // Stands out from the bold player without color
+			}
+			g.frame.SetContent(offsetX+enemy.X, offsetY+enemy.Y, enemy.Symbol, nil, style)
 		}
 	}
 
-	// Render player
-	g.screen.SetContent(offsetX+g.state.Player.X, offsetY+g.state.Player.Y, g.state.Player.Symbol, nil, playerStyle)
+	// Render player, and in pair programming mode whichever of the pair is still alive
+	if g.state.Player2 != nil {
+		player2Style := tcell.StyleDefault.Foreground(tcell.ColorAqua).Background(tcell.ColorBlack).Bold(true)
+		if p2 := g.state.Player2; p2.IsAlive() {
+			g.frame.SetContent(offsetX+p2.X, offsetY+p2.Y, p2.Symbol, nil, player2Style)
+		}
+	}
+	if g.state.Player.IsAlive() || g.state.Player2 == nil {
+		g.frame.SetContent(offsetX+g.state.Player.X, offsetY+g.state.Player.Y, g.state.Player.Symbol, nil, playerStyle)
+	}
+
+	// Render a thrown rubber duck mid-flight
+	if p := g.projectile; p != nil {
+		duckStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack).Bold(true)
+		g.frame.SetContent(offsetX+p.X, offsetY+p.Y, ThrowSymbol, nil, duckStyle)
+	}
 
 	// Render merge conflict marker (red X at center of the most central room)
 	if g.mergeMode {
 		mergeStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true)
-		markerX, markerY := findCentralRoomCenter(dungeon)
+		markerX, markerY := g.state.MergeMarkerX, g.state.MergeMarkerY
 		if markerX >= 0 && markerY >= 0 {
-			g.screen.SetContent(offsetX+markerX, offsetY+markerY, 'X', nil, mergeStyle)
+			g.frame.SetContent(offsetX+markerX, offsetY+markerY, 'X', nil, mergeStyle)
+		}
+	}
+
+	// Highlight the merge conflict focused with Tab
+	if g.mergeMode && g.state.MergeFocus >= 0 {
+		positions := g.state.MergeConflictPositions()
+		if g.state.MergeFocus < len(positions) {
+			focus := positions[g.state.MergeFocus]
+			if g.state.Explored[focus[1]][focus[0]] {
+				mainc, combc, style, _ := g.frame.GetContent(offsetX+focus[0], offsetY+focus[1])
+				g.frame.SetContent(offsetX+focus[0], offsetY+focus[1], mainc, combc, style.Reverse(true))
+			}
+		}
+	}
+
+	// Render tutorial prompt at the top of the screen
+	if prompt := g.state.TutorialPrompt(); prompt != "" {
+		tutorialStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack).Bold(true)
+		for i, ch := range []rune(prompt) {
+			if i < width {
+				g.frame.SetContent(i, 0, ch, nil, tutorialStyle)
+			}
+		}
+	}
+
+	// Render the shareable level code at the top of the screen
+	if g.showLevelCode {
+		code := g.state.LevelCode()
+		codeLine := fmt.Sprintf(" Level code: %s  (share it: gh dungeons --level-code %s) ", code, code)
+		codeStyle := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorAqua)
+		for i, ch := range []rune(codeLine) {
+			if i < width {
+				g.frame.SetContent(i, 0, ch, nil, codeStyle)
+			}
+		}
+	}
+
+	// Anything on the map below the view would run into the UI
+	for y := viewHeight; y < height; y++ {
+		for x := 0; x < width; x++ {
+			g.frame.SetContent(x, y, ' ', nil, tcell.StyleDefault)
 		}
 	}
 
@@ -311,15 +1240,64 @@ func (g *Game) render() {
 	if g.state.Invulnerable {
 		invulnStatus = " | INVULNERABLE"
 	}
-	uiLine := fmt.Sprintf("HP: %d/%d | Level: %d/%d | Kills: %d%s | [q]uit",
+	if g.demoMode {
+		invulnStatus += " | DEMO - press any key"
+	}
+	levelStatus := fmt.Sprintf("%d/%d (%s)", g.state.Level, g.state.MaxLevel, g.state.Difficulty)
+	if g.state.Endless {
+		levelStatus = fmt.Sprintf("%d (endless, %s) | Score: %d", g.state.Level, g.state.Difficulty, g.state.EndlessScore())
+	}
+	if g.state.CombatStance {
+		invulnStatus += " | STANCE"
+	}
+	if armor := g.state.Player.Defense; armor > 0 {
+		invulnStatus += fmt.Sprintf(" | Armor: -%d", armor)
+	}
+	if w := g.state.EquippedWeapon; w != nil {
+		invulnStatus += fmt.Sprintf(" | %s +%d", w.Name, w.Bonus)
+	}
+	if g.state.TorchTurnsLeft > 0 {
+		invulnStatus += fmt.Sprintf(" | Torch: %d", g.state.TorchTurnsLeft)
+	}
+	if g.state.TechDebt > 0 {
+		invulnStatus += fmt.Sprintf(" | Debt: %d", g.state.TechDebt)
+	}
+	if g.state.Gold > 0 {
+		invulnStatus += fmt.Sprintf(" | Gold: %d", g.state.Gold)
+	}
+	if p2 := g.state.Player2; p2 != nil {
+		invulnStatus += fmt.Sprintf(" | P2 HP: %d/%d", p2.HP, p2.MaxHP)
+		if p2.Defense > 0 {
+			invulnStatus += fmt.Sprintf(" Armor: -%d", p2.Defense)
+		}
+	}
+	if g.state.HotfixesHeld > 0 {
+		invulnStatus += fmt.Sprintf(" | Hotfixes: %d [H]", g.state.HotfixesHeld)
+	}
+	if g.state.Throws > 0 {
+		invulnStatus += fmt.Sprintf(" | Ducks: %d [t]", g.state.Throws)
+	}
+	if len(g.state.Inventory) > 0 {
+		invulnStatus += fmt.Sprintf(" | Items: %s [p]", g.state.InventoryLabel())
+	}
+	uiLine := fmt.Sprintf("HP: %d/%d | Lv %d (XP %d) | Level: %s | Kills: %d | Moves: %d%s | [q]uit",
 		g.state.Player.HP, g.state.Player.MaxHP,
-		g.state.Level, g.state.MaxLevel,
+		g.state.PlayerLevel(), g.state.XP,
+		levelStatus,
 		g.state.EnemiesKilled,
+		g.state.MoveCount,
 		invulnStatus)
+	if g.state.LevelFileName != "" {
+		uiLine += fmt.Sprintf(" | File: %s", g.state.LevelFileName)
+	}
+	if !g.state.Tutorial {
+		// Last, so it's what gets cut off on a narrow terminal
+		uiLine += fmt.Sprintf(" | Seed: %d", g.state.Seed)
+	}
 
 	for i, ch := range uiLine {
 		if i < width {
-			g.screen.SetContent(i, uiY, ch, nil, uiStyle)
+			g.frame.SetContent(i, uiY, ch, nil, uiStyle)
 		}
 	}
 
@@ -334,7 +1312,7 @@ func (g *Game) render() {
 
 	// Clear the message line first to avoid leftover characters
 	for i := 0; i < width; i++ {
-		g.screen.SetContent(i, msgY, ' ', nil, tcell.StyleDefault)
+		g.frame.SetContent(i, msgY, ' ', nil, tcell.StyleDefault)
 	}
 	if displayMsg != "" {
 		msgStyle := uiStyle
@@ -347,7 +1325,7 @@ func (g *Game) render() {
 		}
 		for i, ch := range displayMsg {
 			if i < width {
-				g.screen.SetContent(i, msgY, ch, nil, msgStyle)
+				g.frame.SetContent(i, msgY, ch, nil, msgStyle)
 			}
 		}
 	}
@@ -366,28 +1344,44 @@ func (g *Game) render() {
 		if dx <= 2 && dy <= 2 {
 			warningStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true)
 			warningMsg := "WARNING: Merge conflict detected"
+			if conflict := g.state.MergeConflict; conflict != nil {
+				warningMsg = fmt.Sprintf("WARNING: Merge conflict in %s (%s)", conflict.File, conflictCount(len(g.state.MergeConflicts)))
+			}
 			msgY := height - 1
 			for i, ch := range warningMsg {
 				if i < width {
-					g.screen.SetContent(i, msgY, ch, nil, warningStyle)
+					g.frame.SetContent(i, msgY, ch, nil, warningStyle)
 				}
 			}
 		}
 	}
 
+	g.drawOverlays(width, height)
+}
+
+// drawOverlays draws the end screen, level summary and menus over the frame
+func (g *Game) drawOverlays(width, height int) {
 	// Game over / Victory screen
 	if g.state.GameOver || g.state.Victory {
 		g.renderEndScreen(width, height)
 	}
+
+	if g.state.LevelSummary != nil {
+		g.renderLevelSummary(width, height)
+	}
+
+	if g.state.ShopOpen && g.state.LevelSummary == nil {
+		g.renderShop(width, height)
+	}
+
+	if g.showSettings {
+		g.renderSettings(width, height)
+	}
 }
 
 func (g *Game) renderMergeConflict(offsetX, offsetY int) {
-	// Colors for merge conflict: red, orange, yellow - rotate based on movement
-	baseColors := []tcell.Color{
-		tcell.ColorRed,
-		tcell.ColorOrange,
-		tcell.ColorYellow,
-	}
+	// Colors for merge conflict fire come from the theme - rotate based on movement
+	baseColors := g.theme.palette().fire
 	// Rotate colors based on ColorRotation
 	rotation := g.state.ColorRotation % 3
 	colors := make([]tcell.Color, 3)
@@ -465,7 +1459,7 @@ func (g *Game) renderMergeConflict(offsetX, offsetY int) {
 				// Deterministic color based on position and rotation
 				colorIdx := (mcX + mcY) % 3
 				mcStyle := tcell.StyleDefault.Foreground(colors[colorIdx]).Background(tcell.ColorBlack)
-				g.screen.SetContent(offsetX+mcX, offsetY+mcY, ch, nil, mcStyle)
+				g.frame.SetContent(offsetX+mcX, offsetY+mcY, ch, nil, mcStyle)
 			}
 		}
 	}
@@ -491,52 +1485,69 @@ func (g *Game) renderMergeConflict(offsetX, offsetY int) {
 		// Deterministic color based on position and rotation
 		colorIdx := (mcX + mcY + i) % 3
 		mcStyle := tcell.StyleDefault.Foreground(colors[colorIdx]).Background(tcell.ColorBlack)
-		g.screen.SetContent(offsetX+mcX, offsetY+mcY, ch, nil, mcStyle)
+		g.frame.SetContent(offsetX+mcX, offsetY+mcY, ch, nil, mcStyle)
 	}
 }
 
-func (g *Game) renderEndScreen(width, height int) {
-	centerStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Bold(true)
+// endScreenLines returns the boxed victory or game over screen
+func (g *Game) endScreenLines() []string {
+	score := g.state.FinalScore()
+	scoreLines := []string{fmt.Sprintf("   Score: %d (grade %s)", score.Points, score.Grade)}
+	if len(score.Achievements) > 0 {
+		scoreLines = append(scoreLines, "   Achievements: "+strings.Join(score.Achievements, ", "))
+	}
+	if g.newHighScore {
+		scoreLines = append(scoreLines, "   * New high score! *")
+	}
 
 	var lines []string
 	if g.state.Victory {
 		lines = []string{
-			"╔══════════════════════════════════════╗",
-			"║            o VICTORY! o              ║",
-			"║                                      ║",
-			"║   You've conquered all the dungeons! ║",
-			"║                                      ║",
-			fmt.Sprintf("║   Levels Cleared: %d                  ║", g.state.Level),
-			fmt.Sprintf("║   Enemies Killed: %-3d                ║", g.state.EnemiesKilled),
-			"║                                      ║",
-			"║      Press ENTER or SPACE to exit    ║",
-			"║ (none of that vi :q nonsense to die) ",
-			"╚══════════════════════════════════════╝",
+			"            o VICTORY! o              ",
+			"",
+			"   You've conquered all the dungeons! ",
+			"",
+			fmt.Sprintf("   Levels Cleared: %d", g.state.LevelsCleared()),
+			fmt.Sprintf("   Enemies Killed: %-3d", g.state.EnemiesKilled),
+			fmt.Sprintf("   Moves: %d", g.state.MoveCount),
+			"",
+			"      Press ENTER or SPACE to exit    ",
+			" (none of that vi :q nonsense to die) ",
 		}
 	} else {
 		// Get custom death message based on what killed the player
 		deathMsg := g.getDeathMessage()
 		lines = []string{
-			"╔══════════════════════════════════════╗",
-			"║            x GAME OVER x             ║",
-			"║                                      ║",
-			fmt.Sprintf("║   %-36s ║", deathMsg),
-			"║                                      ║",
-			fmt.Sprintf("║   Levels Cleared: %d                  ║", g.state.Level-1),
-			fmt.Sprintf("║   Enemies Killed: %-3d                ║", g.state.EnemiesKilled),
-			"║                                      ║",
-			"║      Press ENTER or SPACE to exit    ║",
-			"║ (none of that vi :q nonsense to die) ║",
-			"╚══════════════════════════════════════╝",
+			"            x GAME OVER x             ",
+			"",
+			fmt.Sprintf("   %-36s ", deathMsg),
+			"",
+			fmt.Sprintf("   Levels Cleared: %d", g.state.LevelsCleared()),
+			fmt.Sprintf("   Enemies Killed: %-3d", g.state.EnemiesKilled),
+			fmt.Sprintf("   Moves: %d", g.state.MoveCount),
+			"",
+			"      Press ENTER or SPACE to exit    ",
+			" (none of that vi :q nonsense to die) ",
+		}
+		if g.state.CanRollback() {
+			prompt := fmt.Sprintf("Press R to roll back to level %d", g.state.Checkpoint.Level)
+			lines = slices.Insert(lines, 8, fmt.Sprintf("   %-36s ", prompt))
 		}
 	}
+	lines = slices.Insert(lines, 7, scoreLines...)
+	return boxLines(lines, g.state.Glyphs())
+}
+
+func (g *Game) renderEndScreen(width, height int) {
+	centerStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Bold(true)
 
+	lines := g.endScreenLines()
 	startY := (height - len(lines)) / 2
 	startX := (width - stringWidth(lines[0])) / 2 // Use first line (top border) for consistent alignment
 	for i, line := range lines {
 		col := 0
 		for _, ch := range line {
-			g.screen.SetContent(startX+col, startY+i, ch, nil, centerStyle)
+			g.frame.SetContent(startX+col, startY+i, ch, nil, centerStyle)
 			col++
 		}
 	}
@@ -555,6 +1566,18 @@ func (g *Game) getDeathMessage() string {
 		return fmt.Sprintf("Death by merge conflict. Just a typical %s.", dayName)
 	case "scope_creep":
 		return "Foiled by scope creep again!"
+	case "conflicting_commit":
+		return "Rejected by the merge queue."
+	case "flaky_test":
+		return "Failed by a flaky test. Re-run?"
+	case "rebase":
+		return "Rebased out of existence. Your history has been rewritten."
+	case "legacy_monolith":
+		return "Crushed by the legacy monolith."
+	case "merged_bug":
+		return "Taken down by a messy merge."
+	case "stack_trace":
+		return "Lost in a 400-line stack trace."
 	default:
 		return "The bugs and scope creeps won..."
 	}
@@ -0,0 +1,214 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func runeKey(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func TestDiagonalKeyMovesPlayer(t *testing.T) {
+	g := &Game{state: newTestState(10, 10)}
+	g.state.Player.X, g.state.Player.Y = 5, 5
+
+	g.handleKey(runeKey('n'))
+
+	if g.state.Player.X != 6 || g.state.Player.Y != 6 {
+		t.Errorf("Diagonal key should move player to (6, 6), got (%d, %d)", g.state.Player.X, g.state.Player.Y)
+	}
+}
+
+func TestNoDiagonalsIgnoresDiagonalKeys(t *testing.T) {
+	g := &Game{state: newTestState(10, 10)}
+	g.state.NoDiagonals = true
+	g.state.Player.X, g.state.Player.Y = 5, 5
+
+	for _, r := range []rune{'y', 'u', 'b', 'n'} {
+		g.handleKey(runeKey(r))
+		if g.state.Player.X != 5 || g.state.Player.Y != 5 {
+			t.Fatalf("Key %q should not move player with diagonals disabled, got (%d, %d)", r, g.state.Player.X, g.state.Player.Y)
+		}
+	}
+	if g.state.MoveCount != 0 {
+		t.Errorf("Diagonal keys should not count as moves, got %d", g.state.MoveCount)
+	}
+
+	// Cardinal movement still works
+	g.handleKey(runeKey('l'))
+	if g.state.Player.X != 6 || g.state.Player.Y != 5 {
+		t.Errorf("Cardinal key should still move player to (6, 5), got (%d, %d)", g.state.Player.X, g.state.Player.Y)
+	}
+}
+
+func TestTabCyclesMergeConflictsInMergeMode(t *testing.T) {
+	g := &Game{state: newTestState(20, 20), mergeMode: true}
+	g.state.MergeFocus = -1
+	g.state.MergeConflictX, g.state.MergeConflictY = 3, 3
+	g.state.MergeMarkerX, g.state.MergeMarkerY = 10, 10
+
+	tab := tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone)
+	expected := []int{0, 1, 0, 1}
+	for i, want := range expected {
+		g.handleKey(tab)
+		if g.state.MergeFocus != want {
+			t.Errorf("Press %d: expected focus %d, got %d", i+1, want, g.state.MergeFocus)
+		}
+	}
+}
+
+func TestTabIgnoredOutsideMergeMode(t *testing.T) {
+	g := &Game{state: newTestState(20, 20)}
+	g.state.MergeFocus = -1
+
+	g.handleKey(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	if g.state.MergeFocus != -1 {
+		t.Errorf("Tab should do nothing outside merge mode, focus is %d", g.state.MergeFocus)
+	}
+}
+
+func TestGraphOverlayToggle(t *testing.T) {
+	g := &Game{state: newTestState(20, 10)}
+
+	g.handleKey(runeKey('G'))
+	if !g.showGraph {
+		t.Error("'G' should show the dependency graph overlay")
+	}
+	g.handleKey(runeKey('G'))
+	if g.showGraph {
+		t.Error("Pressing 'G' again should hide the overlay")
+	}
+	if g.state.Player.X != 1 || g.state.Player.Y != 1 {
+		t.Error("Toggling the overlay should not move the player")
+	}
+}
+
+func TestMergeModeWithoutConflictFallsBack(t *testing.T) {
+	// The game package has no merge conflicts for findMergeConflicts to detect
+	g, err := New(WithHeadless(true), WithMergeMode(true))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer g.Close()
+
+	if g.mergeMode {
+		t.Error("Merge mode should turn off when no merge conflict is found")
+	}
+	if !strings.Contains(g.state.Message, "No merge conflicts found") {
+		t.Errorf("Expected an informational message, got %q", g.state.Message)
+	}
+
+	g.render()
+	x, y := g.state.MergeMarkerX, g.state.MergeMarkerY
+	if ch := g.frame.cells[y*g.frame.width+x].ch; ch == 'X' {
+		t.Error("No merge marker should be drawn without a detected conflict")
+	}
+}
+
+func TestMergeForceKeepsMergeMode(t *testing.T) {
+	g, err := New(WithHeadless(true), WithMergeMode(true), WithMergeForce(true))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer g.Close()
+
+	if !g.mergeMode {
+		t.Error("--merge-force should keep merge mode on without a detected conflict")
+	}
+}
+
+func TestDrawAfterResizeKeepsMergeTilesInPlace(t *testing.T) {
+	g := &Game{state: newTestState(20, 10)}
+	g.state.MergeMarkerX, g.state.MergeMarkerY = 5, 5
+	g.state.triggerMergeConflict()
+	for y := range g.state.Visible {
+		for x := range g.state.Visible[y] {
+			g.state.Visible[y][x] = true
+			g.state.Explored[y][x] = true
+		}
+	}
+
+	for _, size := range [][2]int{{20, 13}, {60, 30}, {10, 6}} {
+		width, height := size[0], size[1]
+		g.state.Resize(width, height)
+		g.frame = newFrameBuffer(width, height)
+		g.draw(width, height)
+
+		offsetX, offsetY := g.mapOffset(width, height-3)
+		x, y := offsetX+4, offsetY+4
+		if x < 0 || y < 0 || x >= width || y >= height-3 {
+			continue // Clipped by the smaller terminal
+		}
+		ch, _, _, _ := g.frame.GetContent(x, y)
+		if !strings.ContainsRune("<>=", ch) {
+			t.Errorf("at %dx%d, expected a conflict marker at the affected tile, got %q", width, height, ch)
+		}
+	}
+}
+
+func TestCameraOffsetClampsAtMapCorners(t *testing.T) {
+	// A 100x50 map seen through a 40x20 view
+	cases := []struct {
+		name         string
+		player       [2]int
+		wantX, wantY int
+	}{
+		{"top left", [2]int{0, 0}, 0, 0},
+		{"top right", [2]int{99, 0}, -60, 0},
+		{"bottom left", [2]int{0, 49}, 0, -30},
+		{"bottom right", [2]int{99, 49}, -60, -30},
+		{"middle", [2]int{50, 25}, -30, -15},
+	}
+	for _, c := range cases {
+		x := cameraOffset(40, 100, c.player[0])
+		y := cameraOffset(20, 50, c.player[1])
+		if x != c.wantX || y != c.wantY {
+			t.Errorf("%s: player at %v got offset (%d, %d), want (%d, %d)", c.name, c.player, x, y, c.wantX, c.wantY)
+		}
+	}
+
+	if got := cameraOffset(80, 60, 59); got != 10 {
+		t.Errorf("A map that fits should stay centered whatever the player does, got offset %d", got)
+	}
+}
+
+func TestDrawFollowsPlayerOnLargeMaps(t *testing.T) {
+	g := &Game{state: newTestState(100, 50)}
+	g.state.Player.X, g.state.Player.Y = 99, 49
+	width, height := 40, 23
+	g.frame = newFrameBuffer(width, height)
+	g.draw(width, height)
+
+	// Bottom right corner of the map lands at the bottom right of the view
+	if ch, _, _, _ := g.frame.GetContent(width-1, height-4); ch != '@' {
+		t.Errorf("Expected the player at the view's bottom right corner, got %q", ch)
+	}
+	if ch, _, _, _ := g.frame.GetContent(0, height-2); ch == '@' {
+		t.Error("The map shouldn't be drawn over the status bar")
+	}
+}
+
+func TestWithSeedOverridesComputedSeed(t *testing.T) {
+	g, err := New(WithHeadless(true), WithSeed(12345))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer g.Close()
+
+	if g.state.Seed != 12345 {
+		t.Errorf("Expected seed 12345, got %d", g.state.Seed)
+	}
+	// The seed comes last in the status bar, so make room for all of it
+	g.screen.SetSize(200, HeadlessHeight)
+	g.render()
+	var status strings.Builder
+	for x := 0; x < g.frame.width; x++ {
+		status.WriteRune(g.frame.cells[(g.frame.height-2)*g.frame.width+x].ch)
+	}
+	if !strings.Contains(status.String(), "Seed: 12345") {
+		t.Errorf("Expected the status bar to show the seed, got %q", status.String())
+	}
+}
@@ -0,0 +1,58 @@
+package game
+
+import "strings"
+
+// Glyphs is the set of box-drawing and special characters the game draws
+// with, so terminals and fonts without Unicode support can swap in ASCII
+type Glyphs struct {
+	TopLeft, TopRight       rune
+	BottomLeft, BottomRight rune
+	Horizontal, Vertical    rune
+	StackFrame              rune   // A frame of the fleeing boss's stack trace
+	GraphEdge               rune   // An edge of the dependency graph overlay
+	Dash                    string // Separates a name from its details in messages
+}
+
+// UnicodeGlyphs is the default glyph set
+var UnicodeGlyphs = Glyphs{
+	TopLeft: '╔', TopRight: '╗',
+	BottomLeft: '╚', BottomRight: '╝',
+	Horizontal: '═', Vertical: '║',
+	StackFrame: '≡',
+	GraphEdge:  '·',
+	Dash:       "—",
+}
+
+// ASCIIGlyphs is the glyph set used with --ascii
+var ASCIIGlyphs = Glyphs{
+	TopLeft: '+', TopRight: '+',
+	BottomLeft: '+', BottomRight: '+',
+	Horizontal: '-', Vertical: '|',
+	StackFrame: '=',
+	GraphEdge:  '.',
+	Dash:       "-",
+}
+
+// Glyphs returns the glyph set the game is drawn with
+func (gs *GameState) Glyphs() Glyphs {
+	if gs.ASCII {
+		return ASCIIGlyphs
+	}
+	return UnicodeGlyphs
+}
+
+// boxLines frames lines of text in a border, padding them to the widest line
+func boxLines(lines []string, glyphs Glyphs) []string {
+	width := 0
+	for _, line := range lines {
+		width = max(width, stringWidth(line))
+	}
+
+	border := strings.Repeat(string(glyphs.Horizontal), width)
+	boxed := []string{string(glyphs.TopLeft) + border + string(glyphs.TopRight)}
+	for _, line := range lines {
+		padding := strings.Repeat(" ", width-stringWidth(line))
+		boxed = append(boxed, string(glyphs.Vertical)+line+padding+string(glyphs.Vertical))
+	}
+	return append(boxed, string(glyphs.BottomLeft)+border+string(glyphs.BottomRight))
+}
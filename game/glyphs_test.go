@@ -0,0 +1,81 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestASCIIEndScreensArePlainASCII(t *testing.T) {
+	for _, victory := range []bool{true, false} {
+		g := &Game{state: newTestState(10, 10)}
+		g.state.ASCII = true
+		g.state.Victory = victory
+		g.state.GameOver = !victory
+		g.state.KilledBy = "bug"
+
+		for _, line := range g.endScreenLines() {
+			for _, ch := range line {
+				if ch >= utf8.RuneSelf {
+					t.Errorf("victory=%v: end screen line %q has non-ASCII rune %q", victory, line, ch)
+				}
+			}
+		}
+	}
+}
+
+func TestEndScreenBoxLinesLineUp(t *testing.T) {
+	g := &Game{state: newTestState(10, 10)}
+	g.state.GameOver = true
+	g.state.KilledBy = "rebase" // Longer than the box's usual width
+
+	lines := g.endScreenLines()
+	for _, line := range lines {
+		if stringWidth(line) != stringWidth(lines[0]) {
+			t.Errorf("Line %q is %d wide, want %d like the border", line, stringWidth(line), stringWidth(lines[0]))
+		}
+	}
+}
+
+func TestEndScreenMovesFitTheBox(t *testing.T) {
+	for _, victory := range []bool{true, false} {
+		g := &Game{state: newTestState(10, 10)}
+		g.state.Victory = victory
+		g.state.GameOver = !victory
+		g.state.KilledBy = "bug"
+		g.state.MoveCount = 1234567890123456789
+
+		lines := g.endScreenLines()
+		border := stringWidth(lines[0])
+		found := false
+		for _, line := range lines {
+			found = found || strings.Contains(line, "Moves: 1234567890123456789")
+			if stringWidth(line) != border {
+				t.Errorf("victory=%v: line %q is %d wide, want %d like the border", victory, line, stringWidth(line), border)
+			}
+		}
+		if !found {
+			t.Errorf("victory=%v: expected the move count on the end screen, got %q", victory, lines)
+		}
+
+		g.frame = newFrameBuffer(80, 30)
+		g.renderEndScreen(80, 30)
+		startX, startY := (80-border)/2, (30-len(lines))/2
+		for i := 1; i < len(lines)-1; i++ {
+			if ch, _, _, _ := g.frame.GetContent(startX+border-1, startY+i); ch != UnicodeGlyphs.Vertical {
+				t.Errorf("victory=%v: row %d should end in the box border, got %q", victory, i, ch)
+			}
+		}
+	}
+}
+
+func TestASCIIExamineMessage(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.ASCII = true
+	gs.Enemies = []*Entity{NewBug(2, 1)}
+	gs.Examine()
+
+	if want := "Bug (1/1 HP) - 1 hit to kill"; gs.Message != want {
+		t.Errorf("Expected %q, got %q", want, gs.Message)
+	}
+}
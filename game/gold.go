@@ -0,0 +1,61 @@
+package game
+
+import "fmt"
+
+// Gold drops: a killed enemy has GoldDropChance of leaving a pile worth
+// GoldPerMaxHP per point of its max HP, plus a little extra, so tougher
+// enemies like scope creeps pay better than bugs
+const (
+	GoldDropChance = 0.5
+	GoldPerMaxHP   = 5
+	GoldSymbol     = '$'
+)
+
+// NewGold creates a pile of gold worth value
+func NewGold(x, y, value int) *Entity {
+	return &Entity{
+		Type:   EntityGold,
+		X:      x,
+		Y:      y,
+		Symbol: GoldSymbol,
+		Value:  value,
+	}
+}
+
+// dropGold may leave a pile of gold where an enemy fell, adding to any pile
+// already there. Returns a note for the kill message ("" if nothing dropped).
+func (gs *GameState) dropGold(enemy *Entity) string {
+	if gs.RNG.Float64() >= GoldDropChance {
+		return ""
+	}
+	value := max(enemy.MaxHP, 1)*GoldPerMaxHP + gs.RNG.Intn(GoldPerMaxHP)
+	if pile := gs.goldAt(enemy.X, enemy.Y); pile != nil {
+		pile.Value += value
+	} else {
+		gs.GoldPiles = append(gs.GoldPiles, NewGold(enemy.X, enemy.Y, value))
+	}
+	return fmt.Sprintf(" It dropped %d gold.", value)
+}
+
+// goldAt returns the pile of gold lying on (x, y), or nil
+func (gs *GameState) goldAt(x, y int) *Entity {
+	for _, pile := range gs.GoldPiles {
+		if pile.X == x && pile.Y == y {
+			return pile
+		}
+	}
+	return nil
+}
+
+// pickUpGold pockets any gold the player walks over
+func (gs *GameState) pickUpGold(x, y int) {
+	for i, pile := range gs.GoldPiles {
+		if pile.X != x || pile.Y != y {
+			continue
+		}
+		gs.GoldPiles = append(gs.GoldPiles[:i], gs.GoldPiles[i+1:]...)
+		gs.Gold += pile.Value
+		gs.SetMessage(fmt.Sprintf("You pick up %d gold. (%d total)", pile.Value, gs.Gold))
+		return
+	}
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// zeroSource makes every roll come out as low as possible, so anything with
+// a chance of happening does
+type zeroSource struct{}
+
+func (zeroSource) Int63() int64 { return 0 }
+func (zeroSource) Seed(int64)   {}
+
+func TestKillDropsGoldThatCanBePickedUp(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.RNG = rand.New(zeroSource{})
+	bug := NewBug(2, 1)
+	gs.Enemies = []*Entity{bug}
+
+	gs.MovePlayer(1, 0)
+	if bug.IsAlive() {
+		t.Fatal("Expected the bug to die")
+	}
+	pile := gs.goldAt(2, 1)
+	if pile == nil || pile.Value != GoldPerMaxHP {
+		t.Fatalf("Expected a pile of %d gold where the bug fell, got %+v", GoldPerMaxHP, pile)
+	}
+
+	gs.MovePlayer(1, 0)
+	if gs.Gold != GoldPerMaxHP {
+		t.Errorf("Walking over the pile should pocket %d gold, got %d", GoldPerMaxHP, gs.Gold)
+	}
+	if len(gs.GoldPiles) != 0 {
+		t.Error("The pile should be gone once picked up")
+	}
+}
+
+func TestAutoAttackKillDropsGold(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.RNG = rand.New(zeroSource{})
+	gs.Enemies = []*Entity{NewBug(3, 2)}
+
+	gs.MovePlayer(1, 0)
+	if gs.goldAt(3, 2) == nil {
+		t.Error("An enemy killed by auto-attack should drop gold too")
+	}
+}
+
+func TestScopeCreepsDropMoreGoldThanBugs(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.RNG = rand.New(zeroSource{})
+	gs.dropGold(NewBug(3, 3))
+	gs.dropGold(NewScopeCreep(6, 3))
+
+	bugGold, creepGold := gs.goldAt(3, 3), gs.goldAt(6, 3)
+	if bugGold == nil || creepGold == nil || creepGold.Value <= bugGold.Value {
+		t.Errorf("Scope creeps should drop more gold than bugs, got %+v and %+v", creepGold, bugGold)
+	}
+}
+
+func TestGoldPilesStack(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.RNG = rand.New(zeroSource{})
+	gs.dropGold(NewBug(3, 3))
+	gs.dropGold(NewBug(3, 3))
+
+	if len(gs.GoldPiles) != 1 || gs.GoldPiles[0].Value != 2*GoldPerMaxHP {
+		t.Errorf("Gold dropped on the same tile should make one bigger pile, got %d piles", len(gs.GoldPiles))
+	}
+}
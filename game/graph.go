@@ -0,0 +1,60 @@
+package game
+
+import "github.com/gdamore/tcell/v2"
+
+// renderRoomGraph overlays the level's dependency graph: each room center is
+// a node and each corridor from connectRooms is an edge between two nodes.
+// Only rooms the player remembers are drawn, so the graph doesn't map out
+// the parts of the level they haven't explored.
+func (g *Game) renderRoomGraph(offsetX, offsetY int) {
+	edgeStyle := tcell.StyleDefault.Foreground(tcell.ColorTeal).Background(tcell.ColorBlack)
+	nodeStyle := tcell.StyleDefault.Foreground(tcell.ColorAqua).Background(tcell.ColorBlack).Bold(true)
+
+	edge := g.state.Glyphs().GraphEdge
+	dungeon := g.state.Dungeon
+	for _, conn := range dungeon.Connections {
+		if !g.state.roomKnown(conn[0]) || !g.state.roomKnown(conn[1]) {
+			continue
+		}
+		x1, y1 := conn[0].Center()
+		x2, y2 := conn[1].Center()
+		for _, p := range linePoints(x1, y1, x2, y2) {
+			g.frame.SetContent(offsetX+p[0], offsetY+p[1], edge, nil, edgeStyle)
+		}
+	}
+	for _, room := range dungeon.Rooms {
+		if !g.state.roomKnown(room) {
+			continue
+		}
+		x, y := room.Center()
+		g.frame.SetContent(offsetX+x, offsetY+y, 'o', nil, nodeStyle)
+	}
+}
+
+// roomKnown reports whether the player remembers any tile of a room
+func (gs *GameState) roomKnown(room *Room) bool {
+	for y := max(room.Y, 0); y < min(room.Y+room.H, gs.Dungeon.Height); y++ {
+		for x := max(room.X, 0); x < min(room.X+room.W, gs.Dungeon.Width); x++ {
+			if gs.Remembers(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// linePoints returns the tiles on a straight line from (x1, y1) to (x2, y2), inclusive
+func linePoints(x1, y1, x2, y2 int) [][2]int {
+	steps := max(abs(x2-x1), abs(y2-y1))
+	if steps == 0 {
+		return [][2]int{{x1, y1}}
+	}
+
+	points := make([][2]int, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		x := x1 + (x2-x1)*i/steps
+		y := y1 + (y2-y1)*i/steps
+		points = append(points, [2]int{x, y})
+	}
+	return points
+}
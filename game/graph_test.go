@@ -0,0 +1,25 @@
+package game
+
+import "testing"
+
+func TestRoomGraphShowsOnlyExploredRooms(t *testing.T) {
+	gs := newTestState(30, 10)
+	seen := &Room{X: 1, Y: 1, W: 5, H: 5}
+	hidden := &Room{X: 20, Y: 1, W: 5, H: 5}
+	gs.Dungeon.Rooms = []*Room{seen, hidden}
+	gs.Dungeon.Connections = [][2]*Room{{seen, hidden}}
+	gs.Explored[2][2] = true
+
+	g := &Game{state: gs, frame: newFrameBuffer(30, 10)}
+	g.renderRoomGraph(0, 0)
+
+	if ch, _, _, _ := g.frame.GetContent(3, 3); ch != 'o' {
+		t.Errorf("Expected the explored room's node at 3,3, got %q", ch)
+	}
+	if ch, _, _, _ := g.frame.GetContent(22, 3); ch == 'o' {
+		t.Error("The unexplored room's node shouldn't be drawn")
+	}
+	if ch, _, _, _ := g.frame.GetContent(12, 3); ch != ' ' && ch != 0 {
+		t.Errorf("The edge to the unexplored room shouldn't be drawn, got %q", ch)
+	}
+}
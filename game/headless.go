@@ -0,0 +1,121 @@
+package game
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Screen size used when running without a terminal
+const (
+	HeadlessWidth  = 80
+	HeadlessHeight = 24
+)
+
+// HeadlessMaxSteps caps how many moves RunHeadless plays before giving up on a run
+const HeadlessMaxSteps = 5000
+
+// NewHeadless creates a game on an in-memory screen, for driving the game
+// loop from tests and benchmarks without a terminal
+func NewHeadless(opts ...GameOption) (*Game, error) {
+	return New(append(opts, WithHeadless(true))...)
+}
+
+// newHeadlessScreen creates an in-memory screen for running without a TTY
+func newHeadlessScreen() (tcell.Screen, error) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		return nil, err
+	}
+	screen.SetSize(HeadlessWidth, HeadlessHeight)
+	return screen, nil
+}
+
+// Dump renders the current frame and writes it to w as plain text
+func (g *Game) Dump(w io.Writer) error {
+	g.render()
+	return writeFrame(w, g.frame)
+}
+
+// writeFrame writes a frame to w as plain text, dropping trailing spaces
+func writeFrame(w io.Writer, frame *frameBuffer) error {
+	out := bufio.NewWriter(w)
+	row := make([]rune, frame.width)
+	for y := 0; y < frame.height; y++ {
+		for x := range row {
+			row[x] = frame.cells[y*frame.width+x].ch
+		}
+		out.WriteString(strings.TrimRight(string(row), " "))
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
+
+// DumpLevel writes the current level to w as plain text, tiles and everything
+// on them, with no status bar or fog of war
+func (g *Game) DumpLevel(w io.Writer) error {
+	_, err := io.WriteString(w, g.state.LevelASCII())
+	return err
+}
+
+// LevelASCII renders the current level as plain text with the items, enemies
+// and players on it. Dead enemies are left out.
+func (gs *GameState) LevelASCII() string {
+	var entities []*Entity
+	for _, items := range [][]*Entity{gs.Potions, gs.Reverts, gs.Hotfixes, gs.Armor, gs.GoldPiles, gs.Torches, gs.Weapons} {
+		entities = append(entities, items...)
+	}
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() {
+			entities = append(entities, enemy)
+		}
+	}
+	if gs.Player2 != nil {
+		entities = append(entities, gs.Player2)
+	}
+	entities = append(entities, gs.Player)
+	return gs.Dungeon.RenderASCIIWith(entities)
+}
+
+// RunHeadless lets the demo player play a single run without a terminal,
+// then writes the final frame to w
+func (g *Game) RunHeadless(w io.Writer) error {
+	for step := 0; step < HeadlessMaxSteps && !g.state.GameOver && !g.state.Victory; step++ {
+		g.state.DemoTurn()
+	}
+	return g.Dump(w)
+}
+
+// Play feeds events to the game in order, just as the game loop would, and
+// draws a frame after each. Returns true as soon as one of them exits the
+// game, ignoring the rest.
+func (g *Game) Play(events ...tcell.Event) bool {
+	for _, ev := range events {
+		if g.handleEvent(ev) {
+			return true
+		}
+		g.render()
+	}
+	return false
+}
+
+// KeyEvents turns a script of key presses into events for Play: each rune is
+// a key, with '\n' for Enter
+func KeyEvents(keys string) []tcell.Event {
+	var events []tcell.Event
+	for _, r := range keys {
+		if r == '\n' {
+			events = append(events, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+			continue
+		}
+		events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return events
+}
+
+// State returns the state of the run being played
+func (g *Game) State() *GameState {
+	return g.state
+}
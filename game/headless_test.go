@@ -0,0 +1,97 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewHeadlessWithoutTTY(t *testing.T) {
+	g, err := New(WithHeadless(true))
+	if err != nil {
+		t.Fatalf("Headless game should not need a TTY: %v", err)
+	}
+	defer g.Close()
+
+	if w, h := g.screen.Size(); w != HeadlessWidth || h != HeadlessHeight {
+		t.Errorf("Expected a %dx%d headless screen, got %dx%d", HeadlessWidth, HeadlessHeight, w, h)
+	}
+
+	var out bytes.Buffer
+	if err := g.Dump(&out); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	if !strings.ContainsRune(out.String(), g.state.Player.Symbol) {
+		t.Error("Dump should show the player")
+	}
+	if !strings.Contains(out.String(), "HP: 20/20") {
+		t.Error("Dump should include the status bar")
+	}
+}
+
+func TestRunHeadlessFinishesRun(t *testing.T) {
+	g, err := New(WithHeadless(true), WithSeed(12345))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer g.Close()
+
+	var out bytes.Buffer
+	if err := g.RunHeadless(&out); err != nil {
+		t.Fatalf("RunHeadless failed: %v", err)
+	}
+	if !g.state.GameOver && !g.state.Victory {
+		t.Error("RunHeadless should play until the run ends")
+	}
+	if out.Len() == 0 {
+		t.Error("RunHeadless should print the final frame")
+	}
+}
+
+func TestScriptedRunDescendsThenDies(t *testing.T) {
+	g, err := NewHeadless(WithSeed(12345))
+	if err != nil {
+		t.Fatalf("NewHeadless failed: %v", err)
+	}
+	defer g.Close()
+
+	// Put the door just right of the player and walk onto it
+	gs := g.State()
+	gs.Enemies = nil
+	x, y := gs.Player.X+1, gs.Player.Y
+	gs.Dungeon.Tiles[y][x] = TileFloor
+	gs.DoorX, gs.DoorY = x, y
+	if g.Play(KeyEvents("l")...) {
+		t.Fatal("Descending shouldn't exit the game")
+	}
+	if gs.Level != 2 {
+		t.Fatalf("Expected to reach level 2, on level %d", gs.Level)
+	}
+	// Level 2 opens with the shop; walk past it
+	if !gs.ShopOpen {
+		t.Fatal("The shop should be open on arriving at level 2")
+	}
+	g.Play(KeyEvents("x")...)
+
+	// Bump a monolith with 1 HP left and let it hit back
+	gs.Player.HP = 1
+	x, y = gs.Player.X+1, gs.Player.Y
+	gs.Dungeon.Tiles[y][x] = TileFloor
+	gs.Enemies = []*Entity{NewMonolith(x, y)}
+	if g.Play(KeyEvents("l")...) {
+		t.Fatal("Dying shouldn't exit the game until the end screen is dismissed")
+	}
+	if !gs.GameOver || gs.LevelsCleared() != 1 {
+		t.Fatalf("Expected the run to end on level 2, game over %v on level %d", gs.GameOver, gs.Level)
+	}
+
+	if !g.Play(KeyEvents("\n")...) {
+		t.Error("Enter on the end screen should exit")
+	}
+}
+
+func BenchmarkNewGameState(b *testing.B) {
+	for i := range b.N {
+		NewGameState(nil, int64(i), HeadlessWidth, HeadlessHeight)
+	}
+}
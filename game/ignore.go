@@ -0,0 +1,116 @@
+package game
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the file at the top of a repository listing paths the
+// code scan should skip, one gitignore-style pattern per line
+const IgnoreFileName = ".gh-dungeons-ignore"
+
+// ignoreRule is one line of an ignore file
+type ignoreRule struct {
+	segments []string // The pattern split on '/'; "**" matches any number of directories
+	negate   bool     // A leading '!' brings back paths an earlier rule ignored
+	dirOnly  bool     // A trailing '/' only matches directories
+}
+
+// ignoreRules are the patterns from an ignore file, in file order
+type ignoreRules []ignoreRule
+
+// loadIgnoreFile reads root's ignore file. A repository without one ignores nothing.
+func loadIgnoreFile(root string) (ignoreRules, error) {
+	file, err := os.Open(filepath.Join(root, IgnoreFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules ignoreRules
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// parseIgnoreRule parses a line of an ignore file the way git reads a
+// .gitignore line. Returns false for blank lines and comments.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A pattern with no slash but the trailing one matches at any depth;
+	// anything else is relative to the repository root
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	rule.segments = strings.Split(line, "/")
+	if !anchored {
+		rule.segments = append([]string{"**"}, rule.segments...)
+	}
+	return rule, true
+}
+
+// ignores reports whether a path, relative to the repository root and
+// separated by '/', should be skipped. Later rules override earlier ones.
+func (r ignoreRules) ignores(rel string, isDir bool) bool {
+	ignored := false
+	parts := strings.Split(rel, "/")
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(rule.segments, parts) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches a path's segments against a pattern's, each with
+// path.Match, letting a "**" segment stand in for zero or more segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
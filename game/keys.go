@@ -0,0 +1,208 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Action is something the player can rebind to other keys in the key map file
+type Action string
+
+const (
+	ActionUp        Action = "up"
+	ActionDown      Action = "down"
+	ActionLeft      Action = "left"
+	ActionRight     Action = "right"
+	ActionUpLeft    Action = "up_left"
+	ActionUpRight   Action = "up_right"
+	ActionDownLeft  Action = "down_left"
+	ActionDownRight Action = "down_right"
+	ActionQuit      Action = "quit"
+	ActionWait      Action = "wait"
+)
+
+// actionDirections are the moves each movement action makes
+var actionDirections = map[Action][2]int{
+	ActionUp:        {0, -1},
+	ActionDown:      {0, 1},
+	ActionLeft:      {-1, 0},
+	ActionRight:     {1, 0},
+	ActionUpLeft:    {-1, -1},
+	ActionUpRight:   {1, -1},
+	ActionDownLeft:  {-1, 1},
+	ActionDownRight: {1, 1},
+}
+
+// keyBinding is one key: a special key like Up or Esc, or a rune typed as is
+type keyBinding struct {
+	key tcell.Key
+	ch  rune
+}
+
+// matches reports whether a key press is this key
+func (b keyBinding) matches(ev *tcell.EventKey) bool {
+	if b.key == tcell.KeyRune {
+		return ev.Key() == tcell.KeyRune && ev.Rune() == b.ch
+	}
+	return ev.Key() == b.key
+}
+
+// String is how the key is written in the key map file
+func (b keyBinding) String() string {
+	switch {
+	case b.key != tcell.KeyRune:
+		return tcell.KeyNames[b.key]
+	case b.ch == ' ':
+		return "space"
+	}
+	return string(b.ch)
+}
+
+// runeBinding binds a typed character
+func runeBinding(ch rune) keyBinding {
+	return keyBinding{key: tcell.KeyRune, ch: ch}
+}
+
+// KeyMap maps actions to the keys that trigger them. Actions it leaves out
+// keep their DefaultKeyMap keys.
+type KeyMap map[Action][]keyBinding
+
+// DefaultKeyMap is how the game plays without a key map file: arrow keys,
+// WASD and Vim keys to move, q or Esc to quit and '.' or space to wait
+var DefaultKeyMap = KeyMap{
+	ActionUp:        {{key: tcell.KeyUp}, runeBinding('k'), runeBinding('w')},
+	ActionDown:      {{key: tcell.KeyDown}, runeBinding('j'), runeBinding('s')},
+	ActionLeft:      {{key: tcell.KeyLeft}, runeBinding('h'), runeBinding('a')},
+	ActionRight:     {{key: tcell.KeyRight}, runeBinding('l'), runeBinding('d')},
+	ActionUpLeft:    {runeBinding('y')},
+	ActionUpRight:   {runeBinding('u')},
+	ActionDownLeft:  {runeBinding('b')},
+	ActionDownRight: {runeBinding('n')},
+	ActionQuit:      {runeBinding('q'), runeBinding('Q'), {key: tcell.KeyEscape}},
+	ActionWait:      {runeBinding('.'), runeBinding(' ')},
+}
+
+// bindings returns the keys for an action, falling back to the default keys
+func (k KeyMap) bindings(action Action) []keyBinding {
+	if keys, ok := k[action]; ok {
+		return keys
+	}
+	return DefaultKeyMap[action]
+}
+
+// Is reports whether a key press is bound to action
+func (k KeyMap) Is(ev *tcell.EventKey, action Action) bool {
+	for _, b := range k.bindings(action) {
+		if b.matches(ev) {
+			return true
+		}
+	}
+	return false
+}
+
+// direction returns the direction a movement key points in, or 0, 0 for any other key
+func (k KeyMap) direction(ev *tcell.EventKey) (dx, dy int) {
+	for action, dir := range actionDirections {
+		if k.Is(ev, action) {
+			return dir[0], dir[1]
+		}
+	}
+	return 0, 0
+}
+
+// keyNames looks up special keys by their lowercase tcell names ("up", "esc", "f1"...)
+var keyNames = func() map[string]tcell.Key {
+	names := map[string]tcell.Key{}
+	for key, name := range tcell.KeyNames {
+		names[strings.ToLower(name)] = key
+	}
+	return names
+}()
+
+// parseKey reads a key from the key map file: a single character, "space",
+// or the name of a special key such as "Up", "Esc" or "F1"
+func parseKey(s string) (keyBinding, error) {
+	if utf8.RuneCountInString(s) == 1 {
+		ch, _ := utf8.DecodeRuneInString(s)
+		return runeBinding(ch), nil
+	}
+	if strings.EqualFold(s, "space") {
+		return runeBinding(' '), nil
+	}
+	if key, ok := keyNames[strings.ToLower(s)]; ok {
+		return keyBinding{key: key}, nil
+	}
+	return keyBinding{}, fmt.Errorf("unknown key %q", s)
+}
+
+// KeyMapPath returns where the key map file is read from
+func KeyMapPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gh-dungeons", "keys.json"), nil
+}
+
+// LoadKeyMap reads a key map from path: a JSON object of actions to lists of
+// keys, such as {"up": ["c", "Up"]}. A missing file leaves every key as it is.
+func LoadKeyMap(path string) (KeyMap, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return KeyMap{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var raw map[Action][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return parseKeyMap(raw)
+}
+
+// parseKeyMap checks and converts the key map file's contents
+func parseKeyMap(raw map[Action][]string) (KeyMap, error) {
+	keys := KeyMap{}
+	for action, names := range raw {
+		if _, ok := DefaultKeyMap[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q", action)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%s has no keys", action)
+		}
+		for _, name := range names {
+			b, err := parseKey(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", action, err)
+			}
+			keys[action] = append(keys[action], b)
+		}
+	}
+
+	// A key can only do one thing, counting the defaults of actions left out
+	actions := make([]Action, 0, len(DefaultKeyMap))
+	for action := range DefaultKeyMap {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	bound := map[keyBinding]Action{}
+	for _, action := range actions {
+		for _, b := range keys.bindings(action) {
+			if other, ok := bound[b]; ok {
+				return nil, fmt.Errorf("%s and %s are both bound to %s", other, action, b)
+			}
+			bound[b] = action
+		}
+	}
+	return keys, nil
+}
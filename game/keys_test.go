@@ -0,0 +1,83 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeKeyMap(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRemappedUpKeyMovesPlayerUp(t *testing.T) {
+	keys, err := LoadKeyMap(writeKeyMap(t, `{"up": ["c"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Game{state: newTestState(10, 10), keys: keys}
+	g.state.Player.X, g.state.Player.Y = 5, 5
+
+	g.handleKey(runeKey('c'))
+	if g.state.Player.X != 5 || g.state.Player.Y != 4 {
+		t.Errorf("Remapped up key should move player to (5, 4), got (%d, %d)", g.state.Player.X, g.state.Player.Y)
+	}
+
+	// The keys it replaced no longer move the player up
+	g.handleKey(runeKey('k'))
+	if g.state.Player.Y != 4 {
+		t.Errorf("k should no longer move the player up, player is at y=%d", g.state.Player.Y)
+	}
+	// Other actions keep their default keys
+	g.handleKey(runeKey('j'))
+	if g.state.Player.Y != 5 {
+		t.Errorf("j should still move the player down, player is at y=%d", g.state.Player.Y)
+	}
+}
+
+func TestMissingKeyMapKeepsDefaults(t *testing.T) {
+	keys, err := LoadKeyMap(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatalf("A missing key map should not be an error, got %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("A missing key map should rebind nothing, got %v", keys)
+	}
+	if !keys.Is(runeKey('q'), ActionQuit) {
+		t.Error("q should quit without a key map")
+	}
+}
+
+func TestBadKeyMapsAreRejected(t *testing.T) {
+	for name, contents := range map[string]string{
+		"not json":       `{"up": `,
+		"unknown action": `{"jump": ["x"]}`,
+		"no keys":        `{"up": []}`,
+		"unknown key":    `{"up": ["PageSideways"]}`,
+		"taken key":      `{"up": ["j"]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadKeyMap(writeKeyMap(t, contents)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestKeyMapNamesSpecialKeys(t *testing.T) {
+	keys, err := LoadKeyMap(writeKeyMap(t, `{"wait": ["space", "Enter"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Game{state: newTestState(10, 10), keys: keys}
+	turns := g.state.Turns
+	g.handleKey(runeKey(' '))
+	if g.state.Turns != turns+1 {
+		t.Errorf("space should wait a turn, turns went from %d to %d", turns, g.state.Turns)
+	}
+}
@@ -0,0 +1,27 @@
+package game
+
+// leashEnemy handles an enemy on a leash: one that has chased more than
+// LeashDistance tiles from home gives up and walks back, going back to sleep
+// when it gets there. Returns true if the enemy used its move heading home.
+func (gs *GameState) leashEnemy(enemy *Entity) bool {
+	if gs.LeashDistance <= 0 || !enemy.Alerted {
+		return false
+	}
+	if !enemy.Returning {
+		if max(abs(enemy.X-enemy.HomeX), abs(enemy.Y-enemy.HomeY)) <= gs.LeashDistance {
+			return false
+		}
+		enemy.Returning = true
+	}
+
+	if path := gs.pathTo(enemy.X, enemy.Y, enemy.HomeX, enemy.HomeY); len(path) > 0 {
+		if next := path[0]; gs.canEnemyMoveTo(next.X, next.Y, enemy) {
+			enemy.X, enemy.Y = next.X, next.Y
+		}
+	}
+	if enemy.X == enemy.HomeX && enemy.Y == enemy.HomeY {
+		enemy.Returning = false
+		enemy.Alerted = false
+	}
+	return true
+}
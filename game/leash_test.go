@@ -0,0 +1,86 @@
+package game
+
+import "testing"
+
+func newLeashTestState() (*GameState, *Entity) {
+	gs := newTestState(30, 10)
+	gs.LeashDistance = 5
+	enemy := NewScopeCreep(10, 5)
+	enemy.Alerted = true
+	enemy.HomeX, enemy.HomeY = 20, 5
+	gs.Enemies = []*Entity{enemy}
+	return gs, enemy
+}
+
+func TestLeashedEnemyHeadsHome(t *testing.T) {
+	gs, enemy := newLeashTestState()
+
+	gs.moveEnemies()
+	if enemy.X != 11 || !enemy.Returning {
+		t.Errorf("An enemy 10 tiles from home on a 5 tile leash should turn back, got (%d,%d) returning=%v",
+			enemy.X, enemy.Y, enemy.Returning)
+	}
+}
+
+func TestLeashedEnemyGoesBackToSleepAtHome(t *testing.T) {
+	gs, enemy := newLeashTestState()
+
+	for i := 0; i < 20 && enemy.Alerted; i++ {
+		gs.moveEnemies()
+	}
+	if enemy.X != 20 || enemy.Y != 5 {
+		t.Errorf("Expected the enemy back home at (20,5), got (%d,%d)", enemy.X, enemy.Y)
+	}
+	if enemy.Alerted || enemy.Returning {
+		t.Error("The enemy should go back to sleep once it's home")
+	}
+}
+
+func TestEnemyWithinLeashKeepsChasing(t *testing.T) {
+	gs, enemy := newLeashTestState()
+	enemy.HomeX = 12
+
+	gs.moveEnemies()
+	if enemy.X != 9 || enemy.Returning {
+		t.Errorf("An enemy within its leash should keep chasing, got (%d,%d)", enemy.X, enemy.Y)
+	}
+}
+
+func TestReturningEnemyDoesntAttack(t *testing.T) {
+	gs, enemy := newLeashTestState()
+	enemy.Returning = true
+	enemy.X, enemy.Y = 2, 2
+
+	gs.enemyAttacks()
+	if gs.Player.HP != gs.Player.MaxHP {
+		t.Error("An enemy heading home shouldn't attack on the way")
+	}
+}
+
+func TestNoLeashByDefault(t *testing.T) {
+	gs, enemy := newLeashTestState()
+	gs.LeashDistance = 0
+
+	gs.moveEnemies()
+	if enemy.X != 9 || enemy.Returning {
+		t.Errorf("Without a leash the enemy should keep chasing, got (%d,%d)", enemy.X, enemy.Y)
+	}
+}
+
+func TestPreAlertedEnemiesAreLeashedToTheirSpawn(t *testing.T) {
+	for _, enemy := range []*Entity{
+		NewConflictingCommit(12, 5),
+		NewMergedBug(12, 5, &Entity{Alerted: true}, &Entity{}),
+	} {
+		gs := newTestState(30, 10)
+		gs.LeashDistance = 5
+		gs.Player.X, gs.Player.Y = 25, 5
+		gs.Enemies = []*Entity{enemy}
+
+		gs.moveEnemies()
+		if enemy.Returning || enemy.X != 13 {
+			t.Errorf("A %s next to where it spawned should chase, got (%d,%d) returning=%v",
+				enemy.Name(), enemy.X, enemy.Y, enemy.Returning)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LevelCodeVersion is the format of the codes EncodeLevelCode makes. Codes of
+// any other version are refused rather than quietly building a different level.
+const LevelCodeVersion = 2
+
+// MaxLevelCodeSize caps the dungeon width and height a level code can ask for
+const MaxLevelCodeSize = 1000
+
+// LevelCode is everything a level is generated from, apart from the code
+// files its floor text comes from
+type LevelCode struct {
+	Seed       int64
+	Level      int
+	Width      int // Dungeon size, which follows the sharer's terminal
+	Height     int
+	RNG        RNGAlgorithm
+	Difficulty Difficulty
+	MaxLevel   int // How deep the run goes; the boss is on the last level
+
+	RoomErosion float64 // Reshapes the rooms
+	CITraps     bool    // Hides CI traps on some levels
+	PotionTiers bool    // Rolls a size for each potion
+}
+
+// EncodeLevelCode packs a level code into a short shareable string: the
+// format version, the seed in base 36, the level, the dungeon size, the RNG
+// algorithm, the difficulty, the run's length, the room erosion and the
+// options that change what spawns, separated by dashes
+// (e.g. "2-3w5e11264sgsg-2-80x37-stdlib-normal-5-0-none")
+func EncodeLevelCode(c LevelCode) string {
+	var options []string
+	if c.CITraps {
+		options = append(options, "citraps")
+	}
+	if c.PotionTiers {
+		options = append(options, "tiers")
+	}
+	if len(options) == 0 {
+		options = []string{"none"}
+	}
+	return fmt.Sprintf("%d-%s-%d-%dx%d-%s-%s-%d-%s-%s", LevelCodeVersion,
+		strconv.FormatUint(uint64(c.Seed), 36), c.Level, c.Width, c.Height, c.RNG, c.Difficulty, c.MaxLevel,
+		strconv.FormatFloat(c.RoomErosion, 'f', -1, 64), strings.Join(options, "+"))
+}
+
+// ParseLevelCode decodes a code made by EncodeLevelCode
+func ParseLevelCode(code string) (LevelCode, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(code)), "-")
+	if len(parts) == 2 || (len(parts) == 7 && parts[0] == "1") {
+		return LevelCode{}, fmt.Errorf("level code %q is from an older version of gh-dungeons; ask for a new one", code)
+	}
+	if len(parts) != 9 {
+		return LevelCode{}, fmt.Errorf("level code %q should look like <version>-<seed>-<level>-<width>x<height>-<rng>-<difficulty>-<levels>-<erosion>-<options>", code)
+	}
+	if version, err := strconv.Atoi(parts[0]); err != nil || version != LevelCodeVersion {
+		return LevelCode{}, fmt.Errorf("level code %q is version %s, but this gh-dungeons reads version %d", code, parts[0], LevelCodeVersion)
+	}
+
+	var c LevelCode
+	seed, err := strconv.ParseUint(parts[1], 36, 64)
+	if err != nil {
+		return LevelCode{}, fmt.Errorf("level code %q has an invalid seed", code)
+	}
+	c.Seed = int64(seed)
+	if c.Level, err = strconv.Atoi(parts[2]); err != nil || c.Level < 1 {
+		return LevelCode{}, fmt.Errorf("level code %q has an invalid level", code)
+	}
+	width, height, ok := strings.Cut(parts[3], "x")
+	if c.Width, err = strconv.Atoi(width); !ok || err != nil || c.Width < MinDungeonWidth || c.Width > MaxLevelCodeSize {
+		return LevelCode{}, fmt.Errorf("level code %q has an invalid size", code)
+	}
+	if c.Height, err = strconv.Atoi(height); err != nil || c.Height < MinDungeonHeight || c.Height > MaxLevelCodeSize {
+		return LevelCode{}, fmt.Errorf("level code %q has an invalid size", code)
+	}
+	if c.RNG, err = ParseRNGAlgorithm(parts[4]); err != nil {
+		return LevelCode{}, fmt.Errorf("level code %q: %w", code, err)
+	}
+	if c.Difficulty, err = ParseDifficulty(parts[5]); err != nil {
+		return LevelCode{}, fmt.Errorf("level code %q: %w", code, err)
+	}
+	if c.MaxLevel, err = strconv.Atoi(parts[6]); err != nil || c.MaxLevel < 1 {
+		return LevelCode{}, fmt.Errorf("level code %q has an invalid number of levels", code)
+	}
+	if c.RoomErosion, err = strconv.ParseFloat(parts[7], 64); err != nil || !(c.RoomErosion >= 0 && c.RoomErosion <= 1) {
+		return LevelCode{}, fmt.Errorf("level code %q has an invalid room erosion", code)
+	}
+	if parts[8] != "none" {
+		for _, option := range strings.Split(parts[8], "+") {
+			switch option {
+			case "citraps":
+				c.CITraps = true
+			case "tiers":
+				c.PotionTiers = true
+			default:
+				return LevelCode{}, fmt.Errorf("level code %q has an unknown option %q", code, option)
+			}
+		}
+	}
+	return c, nil
+}
+
+// LevelCode returns the shareable code for the current level
+func (gs *GameState) LevelCode() string {
+	return EncodeLevelCode(LevelCode{
+		Seed:       gs.Seed,
+		Level:      gs.Level,
+		Width:      gs.Dungeon.Width,
+		Height:     gs.Dungeon.Height,
+		RNG:        gs.RNGAlgorithm,
+		Difficulty: gs.Difficulty,
+		MaxLevel:   gs.MaxLevel,
+
+		RoomErosion: gs.RoomErosion,
+		CITraps:     gs.CITraps,
+		PotionTiers: gs.PotionTiers,
+	})
+}
+
+// levelSeed derives the seed a level is generated from, so each level's
+// layout depends only on the run seed and its number, not on how the
+// earlier levels were played. Level 1 uses the run seed itself, as it did
+// before levels were seeded separately, so a seed's first level is unchanged.
+func levelSeed(seed int64, level int) int64 {
+	return seed + int64(level-1)*0x5851f42d4c957f2d
+}
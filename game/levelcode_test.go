@@ -0,0 +1,118 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevelCodeRoundTrip(t *testing.T) {
+	cases := []LevelCode{
+		{Seed: 42, Level: 1, Width: 80, Height: 37, MaxLevel: 5},
+		{Seed: 0, Level: 3, Width: MinDungeonWidth, Height: MinDungeonHeight, RNG: RNGXorshift, MaxLevel: 5},
+		{Seed: 9223372036854775807, Level: 5, Width: 200, Height: 60, Difficulty: DifficultyHard, MaxLevel: 5},
+		{Seed: -1, Level: 2, Width: 80, Height: 37, Difficulty: DifficultyEasy, MaxLevel: 3},
+		{Seed: -7342919288112, Level: 12, Width: 120, Height: 40, RNG: RNGXorshift, MaxLevel: 10},
+		{Seed: 42, Level: 2, Width: 80, Height: 37, MaxLevel: 5, RoomErosion: 0.3, CITraps: true},
+		{Seed: 42, Level: 2, Width: 80, Height: 37, MaxLevel: 5, RoomErosion: 0.00001, PotionTiers: true},
+		{Seed: 42, Level: 2, Width: 80, Height: 37, MaxLevel: 5, RoomErosion: 1, CITraps: true, PotionTiers: true},
+	}
+	for _, c := range cases {
+		code := EncodeLevelCode(c)
+		got, err := ParseLevelCode(code)
+		if err != nil {
+			t.Errorf("Code %q for %+v didn't parse: %v", code, c, err)
+			continue
+		}
+		if got != c {
+			t.Errorf("Code %q decoded to %+v, expected %+v", code, got, c)
+		}
+	}
+}
+
+func TestLevelCodeIgnoresCaseAndSpaces(t *testing.T) {
+	c, err := ParseLevelCode("  2-ZZ-4-80X37-XORSHIFT-Hard-5-0.25-Tiers+CITraps\n")
+	want := LevelCode{Seed: 36*35 + 35, Level: 4, Width: 80, Height: 37, RNG: RNGXorshift, Difficulty: DifficultyHard, MaxLevel: 5,
+		RoomErosion: 0.25, CITraps: true, PotionTiers: true}
+	if err != nil || c != want {
+		t.Errorf("Expected %+v, got %+v (err %v)", want, c, err)
+	}
+}
+
+func TestLevelCodeRejectsMalformed(t *testing.T) {
+	for _, code := range []string{
+		"", "abc", "abc-2", "2-abc-2", "2--2-80x37-stdlib-normal-5-0-none", "2-ab!c-2-80x37-stdlib-normal-5-0-none",
+		"2-abc-0-80x37-stdlib-normal-5-0-none", "2-abc-x-80x37-stdlib-normal-5-0-none", "2-abc-2-80-stdlib-normal-5-0-none",
+		"2-abc-2-10x10-stdlib-normal-5-0-none", "2-abc-2-80x99999-stdlib-normal-5-0-none", "2-abc-2-80x37-mt-normal-5-0-none",
+		"2-abc-2-80x37-stdlib-brutal-5-0-none", "2-abc-2-80x37-stdlib-normal-0-0-none", "2-zzzzzzzzzzzzzzzz-1-80x37-stdlib-normal-5-0-none",
+		"3-abc-2-80x37-stdlib-normal-5-0-none", "2-abc-2-80x37-stdlib-normal-5-0-none-6",
+		"2-abc-2-80x37-stdlib-normal-5-1.5-none", "2-abc-2-80x37-stdlib-normal-5-nan-none", "2-abc-2-80x37-stdlib-normal-5-0-",
+		"2-abc-2-80x37-stdlib-normal-5-0-traps", "2-abc-2-80x37-stdlib-normal-5-0-none+tiers",
+		// A version 1 code leaves out what changes the level since
+		"1-abc-2-80x37-stdlib-normal-5",
+	} {
+		if _, err := ParseLevelCode(code); err == nil {
+			t.Errorf("Expected %q to be rejected", code)
+		}
+	}
+}
+
+func TestLevelCodeRebuildsSameLevel(t *testing.T) {
+	played := NewGameState(nil, 2024, 100, 45, func(gs *GameState) {
+		gs.RNGAlgorithm = RNGXorshift
+		gs.Difficulty = DifficultyHard
+		gs.MaxLevel = 4
+		gs.RoomErosion = 0.4
+		gs.CITraps = true
+		gs.PotionTiers = true
+	})
+	// Play a few turns so the run's RNG has moved on before descending
+	for i := 0; i < 5; i++ {
+		played.processTurn()
+	}
+	played.Level = 3
+	played.generateLevel()
+
+	code, err := ParseLevelCode(played.LevelCode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The friend's terminal is a different size and they've set nothing else
+	g, err := NewHeadless(WithSourceDir(t.TempDir()), WithLevelCode(code))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	shared := g.State()
+
+	if shared.Dungeon.Width != played.Dungeon.Width || shared.Dungeon.Height != played.Dungeon.Height {
+		t.Errorf("Shared level is %dx%d, expected %dx%d", shared.Dungeon.Width, shared.Dungeon.Height, played.Dungeon.Width, played.Dungeon.Height)
+	}
+	if shared.DoorX != played.DoorX || shared.DoorY != played.DoorY ||
+		shared.Player.X != played.Player.X || shared.Player.Y != played.Player.Y {
+		t.Errorf("Shared level differs: door %d,%d player %d,%d vs door %d,%d player %d,%d",
+			shared.DoorX, shared.DoorY, shared.Player.X, shared.Player.Y,
+			played.DoorX, played.DoorY, played.Player.X, played.Player.Y)
+	}
+	if len(shared.Enemies) != len(played.Enemies) || shared.MaxLevel != 4 || shared.Player.MaxHP != played.Player.MaxHP {
+		t.Errorf("Shared level has %d enemies and %d levels, expected %d and 4", len(shared.Enemies), shared.MaxLevel, len(played.Enemies))
+	}
+	// Room erosion reshapes the level and potion tiers change what spawns
+	if !reflect.DeepEqual(shared.Dungeon.Tiles, played.Dungeon.Tiles) {
+		t.Error("Shared level's tiles differ from the played level's")
+	}
+	for i, potion := range played.Potions {
+		if i >= len(shared.Potions) || shared.Potions[i].Tier != potion.Tier {
+			t.Errorf("Shared level's potions differ from the played level's")
+			break
+		}
+	}
+}
+
+func TestFirstLevelUsesRunSeed(t *testing.T) {
+	if levelSeed(12345, 1) != 12345 {
+		t.Error("Level 1 should be generated from the run seed itself, so seeds keep their first level")
+	}
+	if levelSeed(12345, 2) == levelSeed(12345, 3) {
+		t.Error("Each level should get its own seed")
+	}
+}
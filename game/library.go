@@ -0,0 +1,124 @@
+package game
+
+import "sync"
+
+// QuickScanFiles is how many qualifying code files a run starts from. They
+// are the first ones the walk reaches, ranked by the scan order; the seed is
+// hashed from them and the first levels are built from them, while the rest
+// of the repository is scanned in the background.
+const QuickScanFiles = 5
+
+// MaxCodeFiles caps how many code files a run's levels are built from
+const MaxCodeFiles = 20
+
+// CodeLibrary is the set of code files levels are built from. It becomes
+// ready as soon as the quick set is in, so the game can launch right away on
+// huge repositories, and grows once the background scan ranks the rest.
+// Which file a level gets never depends on how far the scan has got: the
+// quick set keeps the first slots, and a level past it waits for the scan to
+// finish. It is safe to use from multiple goroutines.
+type CodeLibrary struct {
+	mu    sync.Mutex
+	files []CodeFile // The quick set, followed by the best of the rest once done
+	quick int        // How many of files are the quick set
+	err   error
+	ready chan struct{} // Closed once the quick set is in
+	done  chan struct{} // Closed once the whole scan has finished
+}
+
+// scanCodeLibrary starts scanning root for code files of at least minLines
+// lines and returns without waiting. The best of the first quickFiles found
+// make up the quick set; everything after is ranked by order and added behind
+// it, up to maxFiles in all.
+func scanCodeLibrary(root string, minLines, quickFiles, maxFiles int, order ScanOrder) *CodeLibrary {
+	return newCodeLibrary(func(found func(CodeFile)) error {
+		return walkCodeFiles(root, minLines, found)
+	}, quickFiles, maxFiles, order)
+}
+
+// newCodeLibrary builds a library in the background from the files walk
+// reports, in the order it reports them
+func newCodeLibrary(walk func(found func(CodeFile)) error, quickFiles, maxFiles int, order ScanOrder) *CodeLibrary {
+	quickFiles = max(quickFiles, 1)
+	l := &CodeLibrary{ready: make(chan struct{}), done: make(chan struct{})}
+
+	go func() {
+		defer close(l.done)
+
+		var quick, rest []CodeFile
+		published := false
+		publish := func(err error) {
+			sortCodeFiles(quick, order)
+			quick = quick[:min(len(quick), maxFiles)]
+			l.mu.Lock()
+			l.files, l.quick, l.err = quick, len(quick), err
+			l.mu.Unlock()
+			published = true
+			close(l.ready)
+		}
+		err := walk(func(f CodeFile) {
+			if published {
+				rest = append(rest, f)
+				return
+			}
+			quick = append(quick, f)
+			if len(quick) == quickFiles {
+				publish(nil)
+			}
+		})
+		if !published {
+			// The repository ran out of files, or the scan failed, before the quick set filled
+			publish(err)
+			return
+		}
+
+		sortCodeFiles(rest, order)
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for _, f := range rest {
+			if len(l.files) >= maxFiles {
+				break
+			}
+			l.files = append(l.files, f)
+		}
+	}()
+	return l
+}
+
+// QuickFiles waits for the quick set and returns it, best first, or the
+// error that stopped the scan before it filled
+func (l *CodeLibrary) QuickFiles() ([]CodeFile, error) {
+	<-l.ready
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]CodeFile(nil), l.files[:l.quick]...), l.err
+}
+
+// Files waits for the whole scan and returns every file levels are built from
+func (l *CodeLibrary) Files() []CodeFile {
+	<-l.done
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]CodeFile(nil), l.files...)
+}
+
+// levelFile returns the code file a level is built from, or nil if the scan
+// found none. Levels in the quick set's range use it straight away; deeper
+// levels wait for the scan to finish, then rotate through every file.
+func (l *CodeLibrary) levelFile(level int) *CodeFile {
+	<-l.ready
+	i := level - 1
+	l.mu.Lock()
+	if i < l.quick {
+		f := l.files[i]
+		l.mu.Unlock()
+		return &f
+	}
+	l.mu.Unlock()
+
+	files := l.Files()
+	if len(files) == 0 {
+		return nil
+	}
+	return &files[i%len(files)]
+}
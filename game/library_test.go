@@ -0,0 +1,151 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeLibraryRepo writes a.go and b.go, which the walk reaches first, plus
+// longer files c.go..f.go it only reaches after them
+func writeLibraryRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	now := time.Now()
+	writeCodeFile(t, dir, "a.go", 80, now)
+	writeCodeFile(t, dir, "b.go", 90, now)
+	for i, lines := range []int{200, 400, 300, 100} {
+		writeCodeFile(t, dir, fmt.Sprintf("%c.go", 'c'+i), lines, now)
+	}
+	return dir
+}
+
+func fileNames(files []CodeFile) []string {
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.Path))
+	}
+	return names
+}
+
+func TestScanCodeLibraryRanksQuickSetThenRest(t *testing.T) {
+	dir := writeLibraryRepo(t)
+
+	library := scanCodeLibrary(dir, 60, 2, 5, ScanByLines)
+	quick, err := library.QuickFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b.go", "a.go"}; fmt.Sprint(fileNames(quick)) != fmt.Sprint(want) {
+		t.Errorf("expected the quick set %v, got %v", want, fileNames(quick))
+	}
+	// The rest are ranked behind the quick set, which keeps its place
+	if want := []string{"b.go", "a.go", "d.go", "e.go", "c.go"}; fmt.Sprint(fileNames(library.Files())) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, fileNames(library.Files()))
+	}
+}
+
+func TestScanCodeLibraryFewerFilesThanWanted(t *testing.T) {
+	dir := t.TempDir()
+	writeCodeFile(t, dir, "only.go", 80, time.Now())
+
+	library := scanCodeLibrary(dir, 60, QuickScanFiles, MaxCodeFiles, ScanByLines)
+	if quick, err := library.QuickFiles(); err != nil || len(quick) != 1 || len(library.Files()) != 1 {
+		t.Errorf("expected 1 file, got %v (%v)", fileNames(quick), err)
+	}
+	if f := library.levelFile(3); f == nil || filepath.Base(f.Path) != "only.go" {
+		t.Errorf("every level should rotate back to the only file, got %v", f)
+	}
+}
+
+func TestScanCodeLibraryReturnsScanErrors(t *testing.T) {
+	dir := writeLibraryRepo(t)
+	// An ignore file that can't be read must not quietly ignore nothing
+	if err := os.Mkdir(filepath.Join(dir, IgnoreFileName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := scanCodeLibrary(dir, 60, QuickScanFiles, MaxCodeFiles, ScanByLines).QuickFiles(); err == nil {
+		t.Error("expected the ignore file's read error")
+	}
+}
+
+func TestCodeLibraryReadyBeforeScanFinishes(t *testing.T) {
+	release := make(chan struct{})
+	library := newCodeLibrary(func(found func(CodeFile)) error {
+		found(CodeFile{Path: "first.go"})
+		found(CodeFile{Path: "second.go"})
+		<-release // The rest of a huge repository
+		found(CodeFile{Path: "later.go"})
+		return nil
+	}, 2, MaxCodeFiles, ScanByLines)
+
+	quickReady := make(chan []CodeFile)
+	go func() {
+		quick, _ := library.QuickFiles()
+		quickReady <- quick
+	}()
+	select {
+	case quick := <-quickReady:
+		if len(quick) != 2 {
+			t.Errorf("expected the 2 quick files, got %v", fileNames(quick))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the quick set should be ready while the scan is still going")
+	}
+	if f := library.levelFile(2); f.Path != "second.go" {
+		t.Errorf("level 2 should use the quick set's second file, got %s", f.Path)
+	}
+
+	close(release)
+	if f := library.levelFile(3); f.Path != "later.go" {
+		t.Errorf("level 3 should get the file found later, got %s", f.Path)
+	}
+}
+
+func TestScanCodeLibraryConcurrentReads(t *testing.T) {
+	dir := writeLibraryRepo(t)
+
+	library := scanCodeLibrary(dir, 60, 2, 5, ScanByLines)
+	var wg sync.WaitGroup
+	for level := 1; level <= 8; level++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			want := []string{"b.go", "a.go", "d.go", "e.go", "c.go"}[(level-1)%5]
+			if f := library.levelFile(level); f == nil || filepath.Base(f.Path) != want {
+				t.Errorf("level %d should use %s, got %v", level, want, f)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRunsFromTheSameRepositoryMatch(t *testing.T) {
+	dir := writeLibraryRepo(t)
+
+	var seeds []int64
+	var levelFiles [][]string
+	for range 3 {
+		g, err := NewHeadless(WithSourceDir(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for level := 1; level <= QuickScanFiles+3; level++ {
+			g.state.Level = level
+			g.state.generateLevel()
+			names = append(names, g.state.LevelFileName)
+		}
+		seeds = append(seeds, g.state.Seed)
+		levelFiles = append(levelFiles, names)
+	}
+	for i := 1; i < len(seeds); i++ {
+		if seeds[i] != seeds[0] || fmt.Sprint(levelFiles[i]) != fmt.Sprint(levelFiles[0]) {
+			t.Errorf("run %d got seed %d and files %v, the first got %d and %v", i, seeds[i], levelFiles[i], seeds[0], levelFiles[0])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package game
+
+import "fmt"
+
+// LootTier is what a killed enemy drops, from nothing up to the rarest item
+type LootTier int
+
+const (
+	LootNone LootTier = iota
+	LootPotion
+	LootRevert
+	LootArmor
+	LootHotfix
+)
+
+// Loot rolls: a random value in [0, 1) plus bonuses for depth and for
+// tougher enemies, compared against the tier thresholds below. Deeper levels
+// and bigger enemies shift every roll toward the better tiers.
+const (
+	LootPotionRoll    = 0.75 // Rolls below this drop nothing
+	LootRevertRoll    = 0.92
+	LootArmorRoll     = 1.05
+	LootHotfixRoll    = 1.2 // Out of reach for a plain bug on level 1
+	LootLevelBonus    = 0.06
+	LootMaxLevelBonus = 0.3  // Reached on level 6, so endless depths don't drop hotfixes from every bug
+	LootMaxHPBonus    = 0.03 // Per point of enemy max HP beyond 1
+)
+
+// rollLoot picks what an enemy killed on the given level drops. Once the
+// run's potion budget is spent, potions are left out of the roll and it
+// lands on one of the other tiers instead.
+func (gs *GameState) rollLoot(enemy *Entity, level int) LootTier {
+	bonus := min(float64(level-1)*LootLevelBonus, LootMaxLevelBonus) + float64(enemy.MaxHP-1)*LootMaxHPBonus
+	if !gs.potionBudgetSpent() {
+		return lootTier(gs.RNG.Float64() + bonus)
+	}
+	// Roll over everything but the potion band, then skip over it
+	band := LootRevertRoll - LootPotionRoll
+	roll := gs.RNG.Float64()*(1-band) + bonus
+	if roll >= LootPotionRoll {
+		roll += band
+	}
+	return lootTier(roll)
+}
+
+// lootTier is the tier a loot roll lands on
+func lootTier(roll float64) LootTier {
+	switch {
+	case roll >= LootHotfixRoll:
+		return LootHotfix
+	case roll >= LootArmorRoll:
+		return LootArmor
+	case roll >= LootRevertRoll:
+		return LootRevert
+	case roll >= LootPotionRoll:
+		return LootPotion
+	}
+	return LootNone
+}
+
+// potionBudgetSpent reports whether survival mode has handed out every
+// potion its budget allows
+func (gs *GameState) potionBudgetSpent() bool {
+	return gs.PotionBudget > 0 && gs.PotionsSpawned >= gs.PotionBudget
+}
+
+// dropLoot rolls loot for a freshly killed enemy and leaves it, and maybe some
+// gold, where the enemy fell, returning a note for the kill message ("" if
+// nothing dropped)
+func (gs *GameState) dropLoot(enemy *Entity) string {
+	return gs.dropItem(enemy) + gs.dropGold(enemy)
+}
+
+// dropItem rolls the item a killed enemy drops
+func (gs *GameState) dropItem(enemy *Entity) string {
+	switch gs.rollLoot(enemy, gs.Level) {
+	case LootPotion:
+		// Survival mode's potion budget covers drops too
+		if gs.PotionBudget > 0 {
+			gs.PotionsSpawned++
+		}
+		potion := NewPotion(enemy.X, enemy.Y)
+		if gs.PotionTiers {
+			potion.Tier = lootPotionTier(enemy)
+		}
+		gs.Potions = append(gs.Potions, potion)
+		return fmt.Sprintf(" It dropped a %s.", potion.Tier.Name())
+	case LootRevert:
+		gs.Reverts = append(gs.Reverts, NewRevert(enemy.X, enemy.Y))
+		return " It dropped a revert!"
+	case LootArmor:
+		gs.Armor = append(gs.Armor, NewArmor(enemy.X, enemy.Y, armorDefense(gs.Level)))
+		return " It dropped some armor!"
+	case LootHotfix:
+		gs.Hotfixes = append(gs.Hotfixes, NewHotfix(enemy.X, enemy.Y))
+		return " It dropped a hotfix!"
+	}
+	return ""
+}
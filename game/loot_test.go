@@ -0,0 +1,112 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLootScalesWithLevel(t *testing.T) {
+	bug := NewBug(5, 5)
+	gs := newTestState(20, 10)
+
+	// The same seed means the same underlying roll at both levels
+	gs.RNG = rand.New(rand.NewSource(3))
+	shallow := gs.rollLoot(bug, 1)
+	gs.RNG = rand.New(rand.NewSource(3))
+	deep := gs.rollLoot(bug, 5)
+
+	if deep <= shallow {
+		t.Errorf("Expected a better drop on level 5 than level 1, got tier %d vs %d", deep, shallow)
+	}
+}
+
+func TestLootNeverWorseDeeper(t *testing.T) {
+	creep := NewScopeCreep(5, 5)
+	gs := newTestState(20, 10)
+	for seed := int64(0); seed < 200; seed++ {
+		gs.RNG = rand.New(rand.NewSource(seed))
+		shallow := gs.rollLoot(creep, 1)
+		gs.RNG = rand.New(rand.NewSource(seed))
+		deep := gs.rollLoot(creep, 5)
+		if deep < shallow {
+			t.Errorf("Seed %d: level 5 dropped tier %d, worse than level 1's %d", seed, deep, shallow)
+		}
+	}
+}
+
+func TestNoHotfixFromBugsOnFirstLevel(t *testing.T) {
+	bug := NewBug(5, 5)
+	gs := newTestState(20, 10)
+	for i := 0; i < 1000; i++ {
+		if gs.rollLoot(bug, 1) == LootHotfix {
+			t.Fatal("A bug on level 1 should never drop a hotfix")
+		}
+	}
+}
+
+func TestKillDropsLootWhereEnemyFell(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Level = 5
+	gs.RNG = rand.New(rand.NewSource(3))
+	enemy := NewBug(2, 1)
+	gs.Enemies = []*Entity{enemy}
+
+	gs.MovePlayer(1, 0)
+
+	if enemy.IsAlive() {
+		t.Fatal("Expected the bug to die")
+	}
+	items := append(append(append(append([]*Entity{}, gs.Potions...), gs.Reverts...), gs.Hotfixes...), gs.Armor...)
+	if len(items) != 1 || items[0].X != 2 || items[0].Y != 1 {
+		t.Errorf("Expected one drop at 2,1, got %d items", len(items))
+	}
+}
+
+func TestLootLevelBonusIsCapped(t *testing.T) {
+	bug := NewBug(5, 5)
+	gs := newTestState(20, 10)
+	for seed := int64(0); seed < 200; seed++ {
+		gs.RNG = rand.New(rand.NewSource(seed))
+		capped := gs.rollLoot(bug, 6)
+		gs.RNG = rand.New(rand.NewSource(seed))
+		if deep := gs.rollLoot(bug, 40); deep != capped {
+			t.Fatalf("Seed %d: level 40 dropped tier %d, expected the same as level 6's %d", seed, deep, capped)
+		}
+	}
+}
+
+func TestSpentPotionBudgetRerollsPotionDrops(t *testing.T) {
+	bug := NewBug(5, 5)
+	gs := newTestState(20, 10)
+	gs.PotionBudget = 3
+	gs.PotionsSpawned = 3
+
+	others := 0
+	for i := 0; i < 1000; i++ {
+		switch gs.rollLoot(bug, 1) {
+		case LootPotion:
+			t.Fatal("With the potion budget spent, no potion should drop")
+		case LootNone:
+		default:
+			others++
+		}
+	}
+	if others == 0 {
+		t.Error("Rolls that would have been potions should land on other loot")
+	}
+}
+
+func TestPotionDropsComeOutOfTheBudget(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.PotionBudget = 3
+	gs.PotionsSpawned = 2
+	bug := NewBug(2, 1)
+
+	for seed := int64(0); len(gs.Potions) == 0; seed++ {
+		gs.RNG = rand.New(rand.NewSource(seed))
+		gs.dropItem(bug)
+	}
+	if gs.PotionsSpawned != 3 {
+		t.Errorf("A dropped potion should count towards the budget, spawned %d", gs.PotionsSpawned)
+	}
+}
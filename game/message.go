@@ -0,0 +1,87 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultMessageTurns is how many turns a message stays up before a queued one replaces it
+const DefaultMessageTurns = 1
+
+// MaxQueuedMessages caps the backlog of waiting messages; the oldest are dropped first
+const MaxQueuedMessages = 4
+
+// levelTitleStyle is how the code file a level is built from is announced
+var levelTitleStyle = tcell.StyleDefault.Foreground(tcell.ColorAqua).Background(tcell.ColorBlack).Bold(true)
+
+// queuedMessage is a message waiting for the current one to have been shown long enough
+type queuedMessage struct {
+	text  string
+	style tcell.Style
+}
+
+// SetMessage shows a message with the default (green) style. If the current
+// message hasn't been up for MessageTurns turns yet, it waits its turn in the
+// queue instead. An empty message clears the message line and the queue.
+func (gs *GameState) SetMessage(msg string) {
+	if msg == "" {
+		gs.MessageQueue = nil
+		gs.showMessage(queuedMessage{})
+		return
+	}
+	gs.queueMessage(queuedMessage{text: msg})
+}
+
+// SetAlert shows an urgent message, such as damage or death, right away in
+// the given style, skipping the queue. Queued messages still follow it.
+func (gs *GameState) SetAlert(msg string, style tcell.Style) {
+	gs.showMessage(queuedMessage{text: msg, style: style})
+}
+
+// announceLevelFile shows the name of the level's code file as a title,
+// formatted into format and shortened from the front to fit the terminal.
+// Reports false, showing nothing, on a level without a code file.
+func (gs *GameState) announceLevelFile(format string) bool {
+	if gs.LevelFileName == "" {
+		return false
+	}
+	name := []rune(gs.LevelFileName)
+	room := gs.TermWidth - len(fmt.Sprintf(format, ""))
+	if len(name) > room && room > len("...") {
+		name = append([]rune("..."), name[len(name)-room+len("..."):]...)
+	}
+	gs.queueMessage(queuedMessage{text: fmt.Sprintf(format, string(name)), style: levelTitleStyle})
+	return true
+}
+
+func (gs *GameState) queueMessage(m queuedMessage) {
+	if gs.Message == "" || (gs.MessageAge >= gs.MessageTurns && len(gs.MessageQueue) == 0) {
+		gs.showMessage(m)
+		return
+	}
+	if len(gs.MessageQueue) >= MaxQueuedMessages {
+		gs.MessageQueue = gs.MessageQueue[1:]
+	}
+	gs.MessageQueue = append(gs.MessageQueue, m)
+}
+
+func (gs *GameState) showMessage(m queuedMessage) {
+	gs.Message = m.text
+	gs.MessageStyle = m.style
+	gs.MessageAge = 0
+}
+
+// tickMessages runs at the end of every turn. Once the current message has
+// been up for MessageTurns turns the next queued one replaces it; otherwise
+// the message ages by a turn. A message that appeared during the turn just
+// ending is left up, so it's always seen before anything queued behind it.
+func (gs *GameState) tickMessages() {
+	if len(gs.MessageQueue) > 0 && gs.MessageAge >= gs.MessageTurns {
+		next := gs.MessageQueue[0]
+		gs.MessageQueue = gs.MessageQueue[1:]
+		gs.showMessage(next)
+		return
+	}
+	gs.MessageAge++
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestQueuedMessageWaitsForMinimumTurns(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.MessageTurns = 2
+
+	gs.SetMessage("You squashed a bug!")
+	gs.SetMessage("You drink a health potion!")
+	if gs.Message != "You squashed a bug!" {
+		t.Fatalf("The first message should stay up, got %q", gs.Message)
+	}
+
+	gs.tickMessages() // The end of the turn the message appeared in
+	gs.tickMessages()
+	if gs.Message != "You squashed a bug!" {
+		t.Errorf("After 1 turn the first message should still be up, got %q", gs.Message)
+	}
+
+	gs.tickMessages()
+	if gs.Message != "You drink a health potion!" {
+		t.Errorf("After 2 turns the queued message should appear, got %q", gs.Message)
+	}
+}
+
+func TestEveryTurnAgesMessages(t *testing.T) {
+	turns := map[string]func(gs *GameState){
+		"move":     func(gs *GameState) { gs.MovePlayer(0, 1) },
+		"bump":     func(gs *GameState) { gs.MovePlayer(1, 0) },
+		"wait":     func(gs *GameState) { gs.Wait() },
+		"use item": func(gs *GameState) { gs.UseItem(0) },
+		"throw":    func(gs *GameState) { gs.ThrowAttack(0, 1) },
+	}
+	for name, turn := range turns {
+		gs := newTestState(20, 10)
+		gs.MessageTurns = 1
+		gs.Throws = 1
+		gs.Invulnerable = true
+		gs.Player.HP = 5
+		gs.Inventory = []*Entity{NewPotion(0, 0)}
+		gs.Enemies = []*Entity{NewMonolith(2, 1)}
+		gs.Message, gs.MessageAge = "first", 1 // Up since the last turn
+		gs.MessageQueue = []queuedMessage{{text: "second"}}
+
+		turn(gs)
+		if gs.Message != "second" {
+			t.Errorf("A %s should move the message line on to the queue, got %q", name, gs.Message)
+		}
+	}
+}
+
+func TestMessageReplacedOnceShownLongEnough(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.MessageTurns = 1
+
+	gs.SetMessage("first")
+	gs.tickMessages()
+	gs.SetMessage("second")
+	if gs.Message != "second" || len(gs.MessageQueue) != 0 {
+		t.Errorf("A message shown long enough should be replaced right away, got %q with %d queued", gs.Message, len(gs.MessageQueue))
+	}
+}
+
+func TestAlertSkipsQueue(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.MessageTurns = 3
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+
+	gs.SetMessage("You squashed a bug!")
+	gs.SetMessage("You found a hotfix!")
+	gs.SetAlert("A bug attacked - 1 HP damage", red)
+
+	if gs.Message != "A bug attacked - 1 HP damage" || gs.MessageStyle != red {
+		t.Errorf("Damage should show immediately in its style, got %q", gs.Message)
+	}
+	if len(gs.MessageQueue) != 1 {
+		t.Errorf("Queued messages should survive an alert, %d queued", len(gs.MessageQueue))
+	}
+}
+
+func TestFreeActionsAnswerRightAway(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.MessageTurns = 3
+	gs.Enemies = []*Entity{NewBug(2, 1)}
+
+	gs.SetMessage("You squashed a bug!")
+	gs.Examine()
+	if !strings.HasPrefix(gs.Message, "Bug (") {
+		t.Errorf("Examine should answer immediately, got %q", gs.Message)
+	}
+
+	gs.ToggleStance()
+	if gs.Message != "Combat stance: holding position. Directions only attack." {
+		t.Errorf("Toggling stance should answer immediately, got %q", gs.Message)
+	}
+}
+
+func TestMessageQueueCapped(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.MessageTurns = 5
+
+	gs.SetMessage("showing")
+	for i := 0; i < MaxQueuedMessages+3; i++ {
+		gs.SetMessage("queued")
+	}
+	if len(gs.MessageQueue) != MaxQueuedMessages {
+		t.Errorf("Expected the queue capped at %d, got %d", MaxQueuedMessages, len(gs.MessageQueue))
+	}
+}
+
+func TestEmptyMessageClearsQueue(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.MessageTurns = 2
+
+	gs.SetMessage("first")
+	gs.SetMessage("second")
+	gs.SetMessage("")
+	if gs.Message != "" || len(gs.MessageQueue) != 0 {
+		t.Errorf("Clearing should empty the message and queue, got %q with %d queued", gs.Message, len(gs.MessageQueue))
+	}
+}
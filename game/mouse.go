@@ -0,0 +1,118 @@
+package game
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// TravelStepDelay is how long the player pauses on each tile when walking to a clicked tile
+const TravelStepDelay = 80 * time.Millisecond
+
+// screenToMap converts a screen cell to the map tile drawn there on a screen
+// of the given size. Returns false for cells outside the map view.
+func (g *Game) screenToMap(sx, sy, width, height int) (int, int, bool) {
+	viewHeight := max(height-3, 0)
+	if sx < 0 || sy < 0 || sx >= width || sy >= viewHeight {
+		return 0, 0, false
+	}
+	offsetX, offsetY := g.mapOffset(width, viewHeight)
+	x, y := sx-offsetX, sy-offsetY
+	if x < 0 || y < 0 || x >= g.state.Dungeon.Width || y >= g.state.Dungeon.Height {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// handleMouse starts walking the player to a visible tile clicked with the
+// left button. Clicking an enemy walks up to it and attacks. Clicks on tiles
+// the player can't see right now are ignored, as are clicks while a menu or
+// end screen is up.
+func (g *Game) handleMouse(ev *tcell.EventMouse) {
+	if ev.Buttons()&tcell.Button1 == 0 {
+		return
+	}
+	gs := g.state
+	if g.showSettings || g.aiming || gs.ShopOpen || gs.LevelSummary != nil || gs.GameOver || gs.Victory {
+		return
+	}
+
+	sx, sy := ev.Position()
+	width, height := g.screen.Size()
+	x, y, ok := g.screenToMap(sx, sy, width, height)
+	if !ok || !gs.Visible[y][x] || !gs.Dungeon.IsWalkable(x, y) {
+		return
+	}
+
+	g.travel = &pathStep{x, y}
+	g.travelFoe = gs.enemyAt(x, y)
+	if g.travelStep() {
+		g.continueTravel()
+	}
+}
+
+// travelStep takes the next step toward the clicked tile, or the clicked
+// enemy wherever it has moved to. It reports whether there are more steps to
+// take; the walk stops on arrival, once the enemy has been attacked, or when
+// the level changes. A walk to a tile also stops as soon as the player is
+// hurt; a walk to an enemy expects to be.
+func (g *Game) travelStep() bool {
+	gs := g.state
+	if g.travel == nil {
+		return false
+	}
+	target := *g.travel
+	if foe := g.travelFoe; foe != nil {
+		if !foe.IsAlive() {
+			g.stopTravel()
+			return false
+		}
+		target = pathStep{foe.X, foe.Y}
+	}
+
+	path := gs.pathTo(gs.Player.X, gs.Player.Y, target.X, target.Y)
+	if len(path) == 0 {
+		g.stopTravel()
+		return false
+	}
+
+	x, y, hp, level := gs.Player.X, gs.Player.Y, gs.Player.HP, gs.Level
+	gs.MovePlayer(path[0].X-x, path[0].Y-y)
+	moved := gs.Player.X != x || gs.Player.Y != y
+	arrived := gs.Player.X == target.X && gs.Player.Y == target.Y
+	hurt := gs.Player.HP < hp && g.travelFoe == nil
+	if !moved || arrived || hurt || gs.Level != level || gs.GameOver || gs.Victory {
+		g.stopTravel()
+		return false
+	}
+	return true
+}
+
+// continueTravel has the rest of a click-to-move walk taken one step per
+// TravelStepDelay, woken by interrupts so a key press can still stop it.
+// There is only ever one timer: clicking again mid-walk replaces it rather
+// than starting a second walk alongside. Without a terminal there is no one
+// to watch, so the walk is finished at once.
+func (g *Game) continueTravel() {
+	if g.options.headless {
+		for g.travelStep() {
+		}
+		return
+	}
+	if g.travelTimer != nil {
+		g.travelTimer.Stop()
+	}
+	g.travelTimer = time.AfterFunc(TravelStepDelay, func() {
+		g.screen.PostEvent(tcell.NewEventInterrupt(nil))
+	})
+}
+
+// stopTravel calls off any walk to a clicked tile
+func (g *Game) stopTravel() {
+	g.travel = nil
+	g.travelFoe = nil
+	if g.travelTimer != nil {
+		g.travelTimer.Stop()
+		g.travelTimer = nil
+	}
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestScreenToMapAccountsForOffset(t *testing.T) {
+	cases := []struct {
+		name          string
+		mapW, mapH    int
+		player        [2]int
+		width, height int
+		click         [2]int
+		want          [2]int
+		ok            bool
+	}{
+		{"centered map", 20, 10, [2]int{1, 1}, 60, 30, [2]int{25, 13}, [2]int{5, 5}, true},
+		{"scrolled to the bottom right", 100, 50, [2]int{99, 49}, 40, 23, [2]int{39, 19}, [2]int{99, 49}, true},
+		{"scrolled to the middle", 100, 50, [2]int{50, 25}, 40, 23, [2]int{0, 0}, [2]int{30, 15}, true},
+		{"left of a centered map", 20, 10, [2]int{1, 1}, 60, 30, [2]int{19, 13}, [2]int{}, false},
+		{"on the status bar", 100, 50, [2]int{50, 25}, 40, 23, [2]int{5, 21}, [2]int{}, false},
+	}
+	for _, c := range cases {
+		g := &Game{state: newTestState(c.mapW, c.mapH)}
+		g.state.Player.X, g.state.Player.Y = c.player[0], c.player[1]
+		x, y, ok := g.screenToMap(c.click[0], c.click[1], c.width, c.height)
+		if ok != c.ok || (ok && [2]int{x, y} != c.want) {
+			t.Errorf("%s: click at %v gave (%d, %d) %v, want %v %v", c.name, c.click, x, y, ok, c.want, c.ok)
+		}
+	}
+}
+
+// clickTile returns a left click on the screen cell showing map tile (x, y)
+func clickTile(g *Game, x, y int) *tcell.EventMouse {
+	width, height := g.screen.Size()
+	offsetX, offsetY := g.mapOffset(width, height-3)
+	return tcell.NewEventMouse(offsetX+x, offsetY+y, tcell.Button1, tcell.ModNone)
+}
+
+func TestClickWalksToVisibleTile(t *testing.T) {
+	g, err := NewHeadless(WithSeed(7))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	gs := g.State()
+	gs.Enemies = nil
+	room := gs.Dungeon.StartRoom()
+	x, y := room.X, room.Y
+	gs.updateVisibility()
+	if !gs.Visible[y][x] {
+		t.Fatalf("Expected the start room's corner (%d, %d) to be in view", x, y)
+	}
+
+	g.Play(clickTile(g, x, y))
+	if gs.Player.X != x || gs.Player.Y != y {
+		t.Errorf("Clicking (%d, %d) should walk the player there, got (%d, %d)", x, y, gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestClickIgnoresTilesOutOfView(t *testing.T) {
+	g, err := NewHeadless(WithSeed(7))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	gs := g.State()
+	door := [2]int{gs.DoorX, gs.DoorY}
+	if gs.Visible[door[1]][door[0]] {
+		t.Fatal("Expected the door to start out of view")
+	}
+
+	startX, startY := gs.Player.X, gs.Player.Y
+	g.Play(clickTile(g, door[0], door[1]))
+	if gs.Player.X != startX || gs.Player.Y != startY || gs.Turns != 0 {
+		t.Errorf("Clicking a tile out of view shouldn't move the player, now at (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestClickOnEnemyWalksUpAndAttacks(t *testing.T) {
+	g := &Game{state: newTestState(20, 10), options: &gameOptions{headless: true}}
+	gs := g.state
+	gs.NoAutoAttack = true
+	bug := NewBug(5, 1)
+	bug.SightRange = BlindSightRange // Stays put until the player is next to it
+	gs.Enemies = []*Entity{bug}
+
+	hp := bug.HP
+	g.travel, g.travelFoe = &pathStep{bug.X, bug.Y}, bug
+	for g.travelStep() {
+	}
+	if bug.HP >= hp {
+		t.Error("Walking to a clicked enemy should end in an attack")
+	}
+	if gs.Player.X != 4 || gs.Player.Y != 1 {
+		t.Errorf("Player should have stopped next to the bug at (4, 1), got (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestClickingAgainReplacesTravelTimer(t *testing.T) {
+	g, err := NewHeadless(WithSourceDir(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	g.options.headless = false // Walk on a timer, as in a terminal
+
+	g.continueTravel()
+	first := g.travelTimer
+	g.continueTravel()
+	if g.travelTimer == first || first.Stop() {
+		t.Error("A new click should stop the first walk's timer, not run a second one alongside it")
+	}
+
+	g.stopTravel()
+	if g.travelTimer != nil {
+		t.Error("Stopping the walk should stop its timer")
+	}
+}
@@ -0,0 +1,127 @@
+package game
+
+import "container/heap"
+
+// MaxPathNodes caps how many tiles a single path search may expand so one
+// enemy can't stall a turn on a huge dungeon
+const MaxPathNodes = 2000
+
+// pathStep is a single tile along a path
+type pathStep struct {
+	X, Y int
+}
+
+// pathTo finds a shortest walkable route from (fromX, fromY) to (toX, toY)
+// using A* search. The returned steps exclude the start and end on the
+// target. Other entities are ignored since they move every turn, and nothing
+// is cached between calls. Returns nil if no route is found within
+// MaxPathNodes.
+func (gs *GameState) pathTo(fromX, fromY, toX, toY int) []pathStep {
+	return gs.pathAround(fromX, fromY, toX, toY, nil)
+}
+
+// pathAround is pathTo with an extra blocked check for tiles the route must
+// avoid, such as ones occupied by other enemies. A nil blocked avoids nothing.
+func (gs *GameState) pathAround(fromX, fromY, toX, toY int, blocked func(x, y int) bool) []pathStep {
+	if fromX == toX && fromY == toY {
+		return nil
+	}
+	if !gs.Dungeon.IsWalkable(toX, toY) {
+		return nil
+	}
+
+	directions := [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}, {-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+
+	// Every step costs 1, so the heuristic is the number of steps left with
+	// nothing in the way: Chebyshev distance, or Manhattan without diagonals
+	estimate := func(p pathStep) int {
+		dx, dy := abs(toX-p.X), abs(toY-p.Y)
+		if gs.NoDiagonals {
+			return dx + dy
+		}
+		return max(dx, dy)
+	}
+
+	start := pathStep{fromX, fromY}
+	cameFrom := map[pathStep]pathStep{start: start}
+	cost := map[pathStep]int{start: 0}
+	open := &pathQueue{}
+	heap.Push(open, pathNode{step: start, priority: estimate(start)})
+
+	for expanded := 0; open.Len() > 0 && expanded < MaxPathNodes; expanded++ {
+		node := heap.Pop(open).(pathNode)
+		current := node.step
+		if node.cost > cost[current] {
+			continue // A cheaper route here was already expanded
+		}
+
+		if current.X == toX && current.Y == toY {
+			// Walk back to the start to build the path
+			var path []pathStep
+			for step := current; step != start; step = cameFrom[step] {
+				path = append([]pathStep{step}, path...)
+			}
+			return path
+		}
+
+		for _, dir := range directions {
+			diagonal := dir[0] != 0 && dir[1] != 0
+			if diagonal && (gs.NoDiagonals || gs.cornerBlocked(current.X, current.Y, dir[0], dir[1])) {
+				continue
+			}
+			next := pathStep{current.X + dir[0], current.Y + dir[1]}
+			if !gs.Dungeon.IsWalkable(next.X, next.Y) {
+				continue
+			}
+			if blocked != nil && blocked(next.X, next.Y) {
+				continue
+			}
+			nextCost := cost[current] + 1
+			if known, seen := cost[next]; seen && known <= nextCost {
+				continue
+			}
+			cost[next] = nextCost
+			cameFrom[next] = current
+			heap.Push(open, pathNode{step: next, cost: nextCost, priority: nextCost + estimate(next), order: open.pushed})
+		}
+	}
+
+	return nil
+}
+
+// pathNode is a tile waiting to be expanded by the A* search
+type pathNode struct {
+	step     pathStep
+	cost     int // Steps from the start
+	priority int // Cost plus the estimate of steps left
+	order    int // Push order, so ties break the same way every time
+}
+
+// pathQueue is a min-heap of pathNodes by priority
+type pathQueue struct {
+	nodes  []pathNode
+	pushed int
+}
+
+func (q *pathQueue) Len() int { return len(q.nodes) }
+
+func (q *pathQueue) Less(i, j int) bool {
+	a, b := q.nodes[i], q.nodes[j]
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	return a.order < b.order
+}
+
+func (q *pathQueue) Swap(i, j int) { q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i] }
+
+func (q *pathQueue) Push(x any) {
+	q.nodes = append(q.nodes, x.(pathNode))
+	q.pushed++
+}
+
+func (q *pathQueue) Pop() any {
+	last := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+	return last
+}
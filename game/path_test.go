@@ -0,0 +1,222 @@
+package game
+
+import "testing"
+
+// addWallColumn walls off column x from y0 to y1 inclusive
+func addWallColumn(d *Dungeon, x, y0, y1 int) {
+	for y := y0; y <= y1; y++ {
+		d.Tiles[y][x] = TileWall
+	}
+}
+
+func TestPathToRoutesAroundWall(t *testing.T) {
+	gs := newTestState(20, 10)
+	addWallColumn(gs.Dungeon, 10, 0, 7)
+
+	path := gs.pathTo(5, 2, 15, 2)
+	if len(path) == 0 {
+		t.Fatal("Expected a path around the wall")
+	}
+	last := path[len(path)-1]
+	if last.X != 15 || last.Y != 2 {
+		t.Errorf("Path should end on the target, ended at (%d, %d)", last.X, last.Y)
+	}
+
+	prevX, prevY := 5, 2
+	for _, step := range path {
+		if !gs.Dungeon.IsWalkable(step.X, step.Y) {
+			t.Fatalf("Path crosses wall at (%d, %d)", step.X, step.Y)
+		}
+		if abs(step.X-prevX) > 1 || abs(step.Y-prevY) > 1 {
+			t.Fatalf("Path jumps from (%d, %d) to (%d, %d)", prevX, prevY, step.X, step.Y)
+		}
+		prevX, prevY = step.X, step.Y
+	}
+}
+
+func TestPathToUnreachable(t *testing.T) {
+	gs := newTestState(20, 10)
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+
+	if path := gs.pathTo(5, 2, 15, 2); path != nil {
+		t.Errorf("Expected no path through a solid wall, got %v", path)
+	}
+}
+
+// newCorridorLoopState builds a long east-west corridor on row 2 with a
+// parallel bypass on row 4 joined to it at x=8 and x=12
+func newCorridorLoopState() *GameState {
+	gs := newTestState(20, 8)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 20; x++ {
+			gs.Dungeon.Tiles[y][x] = TileWall
+		}
+	}
+	for x := 1; x < 19; x++ {
+		gs.Dungeon.Tiles[2][x] = TileFloor
+	}
+	for x := 8; x <= 12; x++ {
+		gs.Dungeon.Tiles[4][x] = TileFloor
+	}
+	for y := 2; y <= 4; y++ {
+		gs.Dungeon.Tiles[y][8] = TileFloor
+		gs.Dungeon.Tiles[y][12] = TileFloor
+	}
+	return gs
+}
+
+func TestBlockedEnemyFlanksAroundAlly(t *testing.T) {
+	gs := newCorridorLoopState()
+	gs.Player.X, gs.Player.Y = 16, 2
+
+	follower := NewBug(9, 2)
+	leader := NewBug(10, 2)
+	gs.Enemies = []*Entity{follower, leader}
+
+	gs.moveEnemies()
+
+	if follower.X == 9 && follower.Y == 2 {
+		t.Fatal("Enemy blocked by an ally should take the bypass instead of standing still")
+	}
+	if follower.X != 8 || follower.Y < 2 || follower.Y > 3 {
+		t.Errorf("Expected the blocked enemy to head for the bypass at x=8, got (%d, %d)", follower.X, follower.Y)
+	}
+}
+
+func TestBlockedEnemyWaitsWithoutAlternateRoute(t *testing.T) {
+	gs := newTestState(20, 8)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 20; x++ {
+			if y != 2 {
+				gs.Dungeon.Tiles[y][x] = TileWall
+			}
+		}
+	}
+	gs.Player.X, gs.Player.Y = 16, 2
+
+	follower := NewBug(9, 2)
+	leader := NewBug(10, 2)
+	gs.Enemies = []*Entity{follower, leader}
+
+	gs.moveEnemies()
+
+	if follower.X != 9 || follower.Y != 2 {
+		t.Errorf("With no way around its ally the enemy should wait, moved to (%d, %d)", follower.X, follower.Y)
+	}
+}
+
+// newLWallState puts an L-shaped wall between a bug and the player: a foot
+// along y=3 from x=2 to x=4 and a column at x=5 from y=4 to y=8. The bug at
+// (3, 10) sees the player at (12, 4) past the bottom of the column, but a
+// greedy step toward them takes it to (4, 9), where the column hides the
+// player; the way round is along the bottom of the column.
+func newLWallState() (*GameState, *Entity) {
+	gs := newTestState(16, 12)
+	for x := 2; x <= 4; x++ {
+		gs.Dungeon.Tiles[3][x] = TileWall
+	}
+	addWallColumn(gs.Dungeon, 5, 4, 8)
+	gs.Player.X, gs.Player.Y = 12, 4
+	bug := NewBug(3, 10)
+	gs.Enemies = []*Entity{bug}
+	return gs, bug
+}
+
+func TestGreedyChaseStuckInLWall(t *testing.T) {
+	gs, bug := newLWallState()
+
+	for turn := 0; turn < 20; turn++ {
+		gs.moveEnemies()
+	}
+	if !bug.Alerted {
+		t.Fatal("Expected the bug to see the player past the wall")
+	}
+	if bug.X != 4 || bug.Y != 9 {
+		t.Errorf("Expected the greedy chase to stay stuck at (4, 9), got (%d, %d)", bug.X, bug.Y)
+	}
+}
+
+func TestAStarChaseRoutesAroundLWall(t *testing.T) {
+	gs, bug := newLWallState()
+	gs.AStarChase = true
+
+	for turn := 0; turn < 20 && !bug.IsAdjacent(gs.Player); turn++ {
+		gs.moveEnemies()
+	}
+	if !bug.IsAdjacent(gs.Player) {
+		t.Errorf("Expected the bug to find its way around the wall, stuck at (%d, %d)", bug.X, bug.Y)
+	}
+}
+
+func TestAStarChaseStaysOutOfLWallShadow(t *testing.T) {
+	gs, bug := newLWallState()
+	gs.AStarChase = true
+
+	gs.moveEnemies()
+	if bug.X == 3 && bug.Y == 10 {
+		t.Fatal("Expected the bug to start around the wall on the first turn")
+	}
+	if bug.X == 4 && bug.Y == 9 {
+		t.Error("Expected the bug's first step to keep the player in sight, not step behind the column")
+	}
+}
+
+// newPillarState puts a bug at (2, 6) in sight of the player at (10, 5),
+// with a single wall tile at (4, 5) just off the sight line. A greedy chase
+// steps diagonally to (3, 5), where the wall cuts the bug off from the
+// player; the shortest route keeps to the sight line instead.
+func newPillarState() (*GameState, *Entity) {
+	gs := newTestState(16, 12)
+	gs.Dungeon.Tiles[5][4] = TileWall
+	gs.Player.X, gs.Player.Y = 10, 5
+	bug := NewBug(2, 6)
+	gs.Enemies = []*Entity{bug}
+	return gs, bug
+}
+
+func TestGreedyChaseLosesSightBehindPillar(t *testing.T) {
+	gs, bug := newPillarState()
+
+	for turn := 0; turn < 20; turn++ {
+		gs.moveEnemies()
+	}
+	if !bug.Alerted {
+		t.Fatal("Expected the bug to see the player before stepping behind the wall")
+	}
+	if bug.X != 3 || bug.Y != 5 {
+		t.Errorf("Expected the greedy chase to stall behind the wall at (3, 5), got (%d, %d)", bug.X, bug.Y)
+	}
+}
+
+func TestAStarChaseKeepsToSightLine(t *testing.T) {
+	gs, bug := newPillarState()
+	gs.AStarChase = true
+
+	gs.moveEnemies()
+	if bug.X != 3 || bug.Y != 6 {
+		t.Errorf("Expected the bug's first step to be (3, 6), got (%d, %d)", bug.X, bug.Y)
+	}
+	for turn := 0; turn < 20 && !bug.IsAdjacent(gs.Player); turn++ {
+		gs.moveEnemies()
+	}
+	if !bug.IsAdjacent(gs.Player) {
+		t.Errorf("Expected the bug to reach the player, stuck at (%d, %d)", bug.X, bug.Y)
+	}
+}
+
+func TestDiagonalWallCornerBlocksChase(t *testing.T) {
+	// Walls meet at a corner between the bug and the player, so the bug
+	// neither sees the player through the gap nor squeezes through it
+	gs := newTestState(16, 12)
+	gs.Dungeon.Tiles[3][4] = TileWall
+	gs.Dungeon.Tiles[4][5] = TileWall
+	gs.Player.X, gs.Player.Y = 7, 1
+	bug := NewBug(4, 4)
+	gs.Enemies = []*Entity{bug}
+	gs.AStarChase = true
+
+	gs.moveEnemies()
+	if bug.Alerted || bug.X != 4 || bug.Y != 4 {
+		t.Errorf("Expected the bug not to see through the corner, alerted=%v at (%d, %d)", bug.Alerted, bug.X, bug.Y)
+	}
+}
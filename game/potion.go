@@ -0,0 +1,211 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PotionTier is the size of a health potion. A potion heals potionHeal() per
+// tier, so bigger potions keep up with --potion-heal-percent too.
+type PotionTier int
+
+const (
+	PotionSmall PotionTier = iota
+	PotionBig
+	PotionHuge
+)
+
+// MaxInventory is how many items the player can carry: one per number key
+const MaxInventory = 9
+
+// Chances that a potion spawned on a level is bigger than small
+const (
+	PotionHugeChance = 0.1
+	PotionBigChance  = 0.25
+)
+
+// Name returns how the potion tier is described in messages
+func (t PotionTier) Name() string {
+	switch t {
+	case PotionBig:
+		return "big health potion"
+	case PotionHuge:
+		return "huge health potion"
+	default:
+		return "health potion"
+	}
+}
+
+// rollPotionTier picks the size of a potion spawned with a level
+func (gs *GameState) rollPotionTier() PotionTier {
+	roll := gs.RNG.Float64()
+	switch {
+	case roll < PotionHugeChance:
+		return PotionHuge
+	case roll < PotionHugeChance+PotionBigChance:
+		return PotionBig
+	}
+	return PotionSmall
+}
+
+// lootPotionTier picks the size of a potion dropped by an enemy: tougher
+// enemies drop bigger ones
+func lootPotionTier(enemy *Entity) PotionTier {
+	switch {
+	case enemy.MaxHP >= 6:
+		return PotionHuge
+	case enemy.MaxHP >= 3:
+		return PotionBig
+	}
+	return PotionSmall
+}
+
+// tierHeal is how much HP a potion of the given tier restores the player
+func (gs *GameState) tierHeal(tier PotionTier) int {
+	return gs.potionHeal() * (int(tier) + 1)
+}
+
+// drinkPotion heals the player with a potion, reporting the HP it actually
+// restored rather than its full heal when that would go past max HP
+func (gs *GameState) drinkPotion(potion *Entity) {
+	before := gs.Player.HP
+	gs.LevelStats.PotionsUsed++
+	gs.Player.Heal(gs.tierHeal(potion.Tier))
+	gs.SetMessage(fmt.Sprintf("You drink a %s! (+%d HP)", potion.Tier.Name(), gs.Player.HP-before))
+}
+
+// pickUpPotion puts a potion in the player's inventory, unless it's already
+// full. Returns false if the potion has to stay where it is.
+func (gs *GameState) pickUpPotion(potion *Entity) bool {
+	if len(gs.Inventory) >= MaxInventory {
+		gs.SetMessage(fmt.Sprintf("Your inventory is full, so you leave the %s.", potion.Tier.Name()))
+		return false
+	}
+	gs.Inventory = append(gs.Inventory, potion)
+	gs.SetMessage(fmt.Sprintf("You pick up the %s. [%d]", potion.Tier.Name(), len(gs.Inventory)))
+	return true
+}
+
+// bestPotion picks which of the carried heal amounts to drink at hp out of
+// maxHP: the smallest that tops the player off, or the largest if none do.
+// Returns -1 for an empty inventory.
+func bestPotion(hp, maxHP int, heals []int) int {
+	missing := maxHP - hp
+	best := -1
+	for i, heal := range heals {
+		switch {
+		case best < 0:
+			best = i
+		case heal >= missing && (heals[best] < missing || heal < heals[best]):
+			best = i // Tops off with less waste
+		case heal < missing && heals[best] < missing && heal > heals[best]:
+			best = i // Nothing tops off yet, so heal as much as possible
+		}
+	}
+	return best
+}
+
+// QuickHeal drinks the carried potion that heals the player best with the
+// least waste. It takes a turn. Returns false if nothing was drunk.
+func (gs *GameState) QuickHeal() bool {
+	var potions []*Entity
+	var heals []int
+	for _, item := range gs.Inventory {
+		if item.Type == EntityPotion {
+			potions = append(potions, item)
+			heals = append(heals, gs.tierHeal(item.Tier))
+		}
+	}
+	if len(potions) == 0 {
+		gs.SetMessage("You aren't carrying any potions.")
+		return false
+	}
+	if gs.Player.HP >= gs.Player.MaxHP {
+		gs.SetMessage("You're already at full health.")
+		return false
+	}
+
+	potion := potions[bestPotion(gs.Player.HP, gs.Player.MaxHP, heals)]
+	gs.removeFromInventory(potion)
+	gs.drinkPotion(potion)
+	gs.countTurn()
+	gs.processTurn()
+	return true
+}
+
+// UseItem uses the item in an inventory slot, counting from 0. It takes a
+// turn. Returns false if nothing was used.
+func (gs *GameState) UseItem(index int) bool {
+	if index < 0 || index >= len(gs.Inventory) {
+		gs.SetMessage(fmt.Sprintf("You aren't carrying anything in slot %d.", index+1))
+		return false
+	}
+	item := gs.Inventory[index]
+	if item.Type == EntityPotion && gs.Player.HP >= gs.Player.MaxHP {
+		gs.SetMessage("You're already at full health.")
+		return false
+	}
+
+	gs.removeFromInventory(item)
+	gs.drinkPotion(item)
+	gs.countTurn()
+	gs.processTurn()
+	return true
+}
+
+// InventoryLabel describes the inventory for the status bar: each slot's key
+// and what it holds
+func (gs *GameState) InventoryLabel() string {
+	var b strings.Builder
+	for i, item := range gs.Inventory {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%d:%c%d", i+1, item.Symbol, gs.tierHeal(item.Tier))
+	}
+	return b.String()
+}
+
+// removeFromInventory takes an item out of the player's inventory
+func (gs *GameState) removeFromInventory(item *Entity) {
+	for i, carried := range gs.Inventory {
+		if carried == item {
+			gs.Inventory = append(gs.Inventory[:i], gs.Inventory[i+1:]...)
+			return
+		}
+	}
+}
+
+// carriedPotions counts the potions in the player's inventory
+func (gs *GameState) carriedPotions() int {
+	count := 0
+	for _, item := range gs.Inventory {
+		if item.Type == EntityPotion {
+			count++
+		}
+	}
+	return count
+}
+
+// PickUp puts the potion the player is standing on into their inventory. With
+// manual pickup on, this is the only way to collect potions. It takes a turn.
+// Returns false if there was nothing to pick up.
+func (gs *GameState) PickUp() bool {
+	for i, potion := range gs.Potions {
+		if potion.X != gs.Player.X || potion.Y != gs.Player.Y {
+			continue
+		}
+		if !gs.pickUpPotion(potion) {
+			return false
+		}
+		gs.Potions = append(gs.Potions[:i], gs.Potions[i+1:]...)
+		if room := gs.smellyRoomAt(gs.Player.X, gs.Player.Y); room != nil {
+			gs.clearCodeSmell(room)
+		}
+		gs.countTurn()
+		gs.processTurn()
+		return true
+	}
+	gs.SetMessage("There's nothing here to pick up.")
+	return false
+}
@@ -0,0 +1,212 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBestPotionSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		hp, maxHP int
+		heals     []int
+		want      int
+	}{
+		{"smallest that tops off", 14, 20, []int{9, 3, 6}, 2},
+		{"exact fit", 17, 20, []int{6, 3, 9}, 1},
+		{"largest when none suffice", 2, 20, []int{3, 9, 6}, 1},
+		{"only one potion", 19, 20, []int{9}, 0},
+		{"ties take the first", 15, 20, []int{6, 6}, 0},
+		{"empty inventory", 10, 20, nil, -1},
+	}
+	for _, tt := range tests {
+		if got := bestPotion(tt.hp, tt.maxHP, tt.heals); got != tt.want {
+			t.Errorf("%s: bestPotion(%d, %d, %v) = %d, want %d", tt.name, tt.hp, tt.maxHP, tt.heals, got, tt.want)
+		}
+	}
+}
+
+func carryPotions(gs *GameState, tiers ...PotionTier) {
+	for _, tier := range tiers {
+		potion := NewPotion(0, 0)
+		potion.Tier = tier
+		gs.Inventory = append(gs.Inventory, potion)
+	}
+}
+
+func TestQuickHealDrinksLeastWastefulPotion(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.HP = 15 // 5 missing: a big potion (6) tops off, a small one (3) doesn't
+	carryPotions(gs, PotionHuge, PotionSmall, PotionBig)
+
+	if !gs.QuickHeal() {
+		t.Fatal("Expected quick-heal to drink a potion")
+	}
+	if gs.Player.HP != gs.Player.MaxHP {
+		t.Errorf("Expected to be topped off, got %d/%d", gs.Player.HP, gs.Player.MaxHP)
+	}
+	if len(gs.Inventory) != 2 || gs.Inventory[0].Tier != PotionHuge || gs.Inventory[1].Tier != PotionSmall {
+		t.Errorf("Expected the big potion to be drunk, leaving huge and small")
+	}
+}
+
+func TestQuickHealAtFullHealthKeepsPotions(t *testing.T) {
+	gs := newTestState(10, 10)
+	carryPotions(gs, PotionSmall)
+
+	if gs.QuickHeal() {
+		t.Error("Quick-heal at full health shouldn't drink anything")
+	}
+	if len(gs.Inventory) != 1 {
+		t.Errorf("Expected the potion to still be carried, got %d", len(gs.Inventory))
+	}
+}
+
+func TestWalkingOverPotionCarriesIt(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.HP = 10
+	gs.Potions = []*Entity{NewPotion(2, 1)}
+
+	gs.MovePlayer(1, 0)
+	if len(gs.Potions) != 0 || gs.carriedPotions() != 1 {
+		t.Errorf("Expected the potion to be carried, got %d on the floor and %d carried", len(gs.Potions), gs.carriedPotions())
+	}
+	if gs.LevelStats.PotionsUsed != 0 {
+		t.Error("Carrying a potion shouldn't count as using it")
+	}
+	if gs.Player.HP != 10 {
+		t.Errorf("Picking up a potion shouldn't heal, got HP %d", gs.Player.HP)
+	}
+}
+
+func TestBiggerPotionsHealMore(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.HP = 1
+	potion := NewPotion(2, 1)
+	potion.Tier = PotionHuge
+	gs.Potions = []*Entity{potion}
+
+	gs.MovePlayer(1, 0)
+	gs.UseItem(0)
+	if want := 1 + 3*PotionHealAmount; gs.Player.HP != want {
+		t.Errorf("A huge potion should heal %d, got HP %d", 3*PotionHealAmount, gs.Player.HP)
+	}
+}
+
+func TestPotionTiersOnlyWithOption(t *testing.T) {
+	tiered := 0
+	for seed := int64(1); seed <= 20; seed++ {
+		gs := NewGameState(nil, seed, 80, 40)
+		for _, potion := range gs.Potions {
+			if potion.Tier != PotionSmall {
+				t.Fatalf("Seed %d spawned a %s without --potion-tiers", seed, potion.Tier.Name())
+			}
+		}
+
+		gs = NewGameState(nil, seed, 80, 40, func(gs *GameState) { gs.PotionTiers = true })
+		for _, potion := range gs.Potions {
+			if potion.Tier != PotionSmall {
+				tiered++
+			}
+		}
+	}
+	if tiered == 0 {
+		t.Error("Expected some big or huge potions across 20 seeds with --potion-tiers")
+	}
+}
+
+func TestManualPickupLeavesPotionOnFloor(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.ManualPickup = true
+	gs.Player.HP = 10
+	gs.Potions = []*Entity{NewPotion(2, 1)}
+
+	gs.MovePlayer(1, 0)
+	if len(gs.Potions) != 1 || gs.Player.HP != 10 {
+		t.Fatalf("Walking over a potion with manual pickup shouldn't touch it, got %d on the floor and HP %d", len(gs.Potions), gs.Player.HP)
+	}
+
+	if !gs.PickUp() {
+		t.Fatal("Expected the pickup key to pick up the potion underfoot")
+	}
+	if len(gs.Potions) != 0 || gs.carriedPotions() != 1 {
+		t.Errorf("Expected the potion to be carried, got %d on the floor and %d carried", len(gs.Potions), gs.carriedPotions())
+	}
+}
+
+func TestPickUpWithNothingUnderfoot(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Potions = []*Entity{NewPotion(2, 1)}
+
+	if gs.PickUp() {
+		t.Error("There's nothing to pick up away from the potion")
+	}
+	if len(gs.Potions) != 1 {
+		t.Error("The potion should stay where it is")
+	}
+}
+
+func TestPickupKeyWithManualPickupSetting(t *testing.T) {
+	g := newSettingsTestGame(t)
+	g.settings.ManualPickup = true
+	g.applySettings()
+	g.state.Potions = []*Entity{NewPotion(2, 1)}
+
+	g.handleKey(runeKey('l'))
+	if len(g.state.Potions) != 1 {
+		t.Fatal("Walking onto the potion shouldn't pick it up with manual pickup on")
+	}
+	g.handleKey(runeKey(','))
+	if g.state.carriedPotions() != 1 {
+		t.Error("',' should pick up the potion underfoot")
+	}
+}
+
+func TestFullInventoryLeavesPotionOnFloor(t *testing.T) {
+	gs := newTestState(10, 10)
+	carryPotions(gs, slices.Repeat([]PotionTier{PotionSmall}, MaxInventory)...)
+	gs.Potions = []*Entity{NewPotion(2, 1)}
+
+	gs.MovePlayer(1, 0)
+	if len(gs.Potions) != 1 || len(gs.Inventory) != MaxInventory {
+		t.Errorf("Expected the potion to stay on the floor, got %d on the floor and %d carried", len(gs.Potions), len(gs.Inventory))
+	}
+	if !strings.Contains(gs.Message, "inventory is full") {
+		t.Errorf("Expected an inventory full message, got %q", gs.Message)
+	}
+}
+
+func TestUseItemDrinksSlot(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.HP = 5
+	carryPotions(gs, PotionSmall, PotionBig)
+
+	if !gs.UseItem(1) {
+		t.Fatal("Expected the potion in slot 2 to be drunk")
+	}
+	if want := 5 + 2*PotionHealAmount; gs.Player.HP != want {
+		t.Errorf("Expected HP %d after a big potion, got %d", want, gs.Player.HP)
+	}
+	if len(gs.Inventory) != 1 || gs.Inventory[0].Tier != PotionSmall {
+		t.Error("Expected only the small potion to be left")
+	}
+	if gs.UseItem(1) {
+		t.Error("Slot 2 is empty now")
+	}
+}
+
+func TestNumberKeysUseInventorySlots(t *testing.T) {
+	g := newSettingsTestGame(t)
+	g.state.Player.HP = 5
+	carryPotions(g.state, PotionSmall, PotionHuge)
+
+	g.handleKey(runeKey('2'))
+	if len(g.state.Inventory) != 1 || g.state.Inventory[0].Tier != PotionSmall {
+		t.Error("'2' should drink the potion in the second slot")
+	}
+	if label := g.state.InventoryLabel(); label != fmt.Sprintf("1:+%d", PotionHealAmount) {
+		t.Errorf("Unexpected inventory label %q", label)
+	}
+}
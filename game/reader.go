@@ -0,0 +1,80 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DescribeSurroundings describes the player's surroundings in words, one
+// line each: HP, the enemies in view from nearest to farthest with where they
+// are, the door or any potions next to the player, and the current message.
+// With --screen-reader it is drawn in place of the map.
+func (gs *GameState) DescribeSurroundings() string {
+	player := gs.Player
+	lines := []string{fmt.Sprintf("HP %d of %d, level %d.", player.HP, player.MaxHP, gs.Level)}
+
+	var enemies []*Entity
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() && gs.Visible[enemy.Y][enemy.X] {
+			enemies = append(enemies, enemy)
+		}
+	}
+	sort.SliceStable(enemies, func(i, j int) bool {
+		return tileDistance(player, enemies[i].X, enemies[i].Y) < tileDistance(player, enemies[j].X, enemies[j].Y)
+	})
+	if len(enemies) == 0 {
+		lines = append(lines, "No enemies in sight.")
+	}
+	for _, enemy := range enemies {
+		name := enemy.Name()
+		lines = append(lines, fmt.Sprintf("%s%s, %d HP, %s.",
+			strings.ToUpper(name[:1]), name[1:], enemy.HP, describeOffset(enemy.X-player.X, enemy.Y-player.Y)))
+	}
+
+	if tileDistance(player, gs.DoorX, gs.DoorY) == 1 {
+		lines = append(lines, fmt.Sprintf("The door is to the %s.", compassDirection(gs.DoorX-player.X, gs.DoorY-player.Y)))
+	}
+	for _, potion := range gs.Potions {
+		if tileDistance(player, potion.X, potion.Y) == 1 {
+			lines = append(lines, fmt.Sprintf("A potion is to the %s.", compassDirection(potion.X-player.X, potion.Y-player.Y)))
+		}
+	}
+
+	if gs.Message != "" {
+		lines = append(lines, gs.Message)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tileDistance is how many moves away a tile is from an entity, counting diagonal steps
+func tileDistance(e *Entity, x, y int) int {
+	return max(abs(x-e.X), abs(y-e.Y))
+}
+
+// describeOffset says how far away and in which direction an offset is, e.g. "2 tiles northeast"
+func describeOffset(dx, dy int) string {
+	distance := max(abs(dx), abs(dy))
+	if distance == 1 {
+		return "1 tile " + compassDirection(dx, dy)
+	}
+	return fmt.Sprintf("%d tiles %s", distance, compassDirection(dx, dy))
+}
+
+// drawDescription draws DescribeSurroundings as lines of text instead of the
+// map, keeping the overlays such as the end screen and menus
+func (g *Game) drawDescription(width, height int) {
+	style := g.theme.palette().ui
+	for y, line := range strings.Split(g.state.DescribeSurroundings(), "\n") {
+		if y >= height {
+			break
+		}
+		for x, ch := range []rune(line) {
+			if x >= width {
+				break
+			}
+			g.frame.SetContent(x, y, ch, nil, style)
+		}
+	}
+	g.drawOverlays(width, height)
+}
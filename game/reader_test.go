@@ -0,0 +1,53 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDescribeSurroundingsWithAdjacentEnemy(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.X, gs.Player.Y = 4, 4
+	bug := NewBug(5, 4)
+	gs.Enemies = []*Entity{bug}
+	gs.DoorX, gs.DoorY = 3, 5
+	gs.Potions = []*Entity{NewPotion(4, 3), NewPotion(8, 8)}
+	gs.updateVisibility()
+	gs.SetMessage("You feel watched.")
+
+	want := strings.Join([]string{
+		fmt.Sprintf("HP %d of %d, level 1.", gs.Player.HP, gs.Player.MaxHP),
+		fmt.Sprintf("Bug, %d HP, 1 tile east.", bug.HP),
+		"The door is to the southwest.",
+		"A potion is to the north.",
+		"You feel watched.",
+	}, "\n")
+	if got := gs.DescribeSurroundings(); got != want {
+		t.Errorf("DescribeSurroundings() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDescribeSurroundingsLeavesOutUnseenEnemies(t *testing.T) {
+	gs := newTestState(30, 10)
+	gs.Enemies = []*Entity{NewBug(28, 8)}
+	gs.updateVisibility()
+
+	if got := gs.DescribeSurroundings(); !strings.Contains(got, "No enemies in sight.") {
+		t.Errorf("An enemy out of sight shouldn't be described, got:\n%s", got)
+	}
+}
+
+func TestScreenReaderDrawsDescription(t *testing.T) {
+	g := &Game{state: newTestState(10, 10), screenReader: true}
+	g.state.updateVisibility()
+	g.frame = newFrameBuffer(40, 10)
+	g.drawDescription(40, 10)
+
+	want := g.state.DescribeSurroundings()
+	for x, ch := range want[:strings.Index(want, "\n")] {
+		if got, _, _, _ := g.frame.GetContent(x, 0); got != ch {
+			t.Fatalf("Row 0 should read %q, found %q at column %d", want, got, x)
+		}
+	}
+}
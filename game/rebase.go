@@ -0,0 +1,39 @@
+package game
+
+// Rebases don't walk. A rebase that can see the player jumps to a random
+// free tile within RebaseTeleportRange of them, then waits
+// RebaseTeleportCooldown turns before it can jump again.
+const (
+	RebaseChance           = 0.05 // Chance per enemy slot, taken from bugs
+	RebaseTeleportCooldown = 3
+	RebaseTeleportRange    = 2
+)
+
+// teleportRebase moves a rebase close to the target it can see on its
+// teleport turn, and reports whether it teleported
+func (gs *GameState) teleportRebase(enemy, target *Entity) bool {
+	if enemy.TeleportCooldown > 0 {
+		enemy.TeleportCooldown--
+		return false
+	}
+	if enemy.DistanceTo(target) <= 1 {
+		return false // Already close enough to attack
+	}
+
+	var spots [][2]int
+	for y := target.Y - RebaseTeleportRange; y <= target.Y+RebaseTeleportRange; y++ {
+		for x := target.X - RebaseTeleportRange; x <= target.X+RebaseTeleportRange; x++ {
+			if gs.canEnemyMoveTo(x, y, enemy) {
+				spots = append(spots, [2]int{x, y})
+			}
+		}
+	}
+	if len(spots) == 0 {
+		return false
+	}
+
+	spot := spots[gs.RNG.Intn(len(spots))]
+	enemy.X, enemy.Y = spot[0], spot[1]
+	enemy.TeleportCooldown = RebaseTeleportCooldown
+	return true
+}
@@ -0,0 +1,76 @@
+package game
+
+import "testing"
+
+func TestRebaseTeleportsNearPlayer(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 10, 5
+	gs.Dungeon.Tiles[5][11] = TileWall
+	rebase := NewRebase(2, 5)
+	gs.Enemies = []*Entity{rebase}
+
+	if !gs.teleportRebase(rebase, gs.Player) {
+		t.Fatal("Expected the rebase to teleport on its teleport turn")
+	}
+	if rebase.DistanceTo(gs.Player) > RebaseTeleportRange {
+		t.Errorf("Rebase landed %d tiles from the player, want at most %d", rebase.DistanceTo(gs.Player), RebaseTeleportRange)
+	}
+	if !gs.Dungeon.IsWalkable(rebase.X, rebase.Y) {
+		t.Errorf("Rebase landed on an unwalkable tile at (%d, %d)", rebase.X, rebase.Y)
+	}
+	if rebase.X == gs.Player.X && rebase.Y == gs.Player.Y {
+		t.Error("Rebase landed on the player")
+	}
+}
+
+func TestRebaseTeleportHasCooldown(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 10, 5
+	rebase := NewRebase(2, 5)
+	gs.Enemies = []*Entity{rebase}
+
+	gs.teleportRebase(rebase, gs.Player)
+	for turn := 0; turn < RebaseTeleportCooldown; turn++ {
+		rebase.X, rebase.Y = 2, 5
+		if gs.teleportRebase(rebase, gs.Player) {
+			t.Fatalf("Rebase teleported again after %d turns, want a %d turn cooldown", turn+1, RebaseTeleportCooldown)
+		}
+	}
+	if !gs.teleportRebase(rebase, gs.Player) {
+		t.Error("Expected the rebase to teleport once its cooldown ran out")
+	}
+}
+
+func TestRebaseDoesNotWalk(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 10, 5
+	rebase := NewRebase(2, 5)
+	rebase.TeleportCooldown = 2
+	gs.Enemies = []*Entity{rebase}
+
+	gs.moveEnemies()
+	if !rebase.Alerted {
+		t.Fatal("Expected the rebase to spot the player")
+	}
+	if rebase.X != 2 || rebase.Y != 5 {
+		t.Errorf("Rebase on cooldown should stay put, moved to (%d, %d)", rebase.X, rebase.Y)
+	}
+}
+
+func TestRebaseStaysPutWithNowhereToLand(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 10, 5
+	for y := 5 - RebaseTeleportRange; y <= 5+RebaseTeleportRange; y++ {
+		for x := 10 - RebaseTeleportRange; x <= 10+RebaseTeleportRange; x++ {
+			if x != 10 || y != 5 {
+				gs.Dungeon.Tiles[y][x] = TileWall
+			}
+		}
+	}
+	rebase := NewRebase(2, 5)
+	gs.Enemies = []*Entity{rebase}
+
+	if gs.teleportRebase(rebase, gs.Player) {
+		t.Errorf("Rebase shouldn't teleport with no free tile near the player, landed at (%d, %d)", rebase.X, rebase.Y)
+	}
+}
@@ -0,0 +1,29 @@
+package game
+
+// regenerate heals the player 1 HP for every so many turns in a row (set by
+// the difficulty) spent out of reach of enemies and off the merge conflict.
+// Standing next to an enemy or on the conflict starts the count over. In
+// co-op each player counts their own turns.
+func (gs *GameState) regenerate() {
+	player := gs.Player
+	resting := player.IsAlive() && !gs.OnMergeConflict && !gs.enemyAdjacent(player)
+	if !resting || player.HP >= player.MaxHP {
+		player.RegenTurns = 0
+		return
+	}
+	player.RegenTurns++
+	if player.RegenTurns >= gs.Difficulty.rules().regenTurns {
+		player.RegenTurns = 0
+		player.Heal(1)
+	}
+}
+
+// enemyAdjacent reports whether a living enemy is next to player
+func (gs *GameState) enemyAdjacent(player *Entity) bool {
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() && enemy.IsAdjacent(player) {
+			return true
+		}
+	}
+	return false
+}
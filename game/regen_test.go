@@ -0,0 +1,74 @@
+package game
+
+import "testing"
+
+func TestRestingRegeneratesHP(t *testing.T) {
+	for _, d := range []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard} {
+		gs := newTestState(20, 10)
+		gs.Difficulty = d
+		gs.Player.HP = 10
+
+		n := d.rules().regenTurns
+		for turn := 1; turn < n; turn++ {
+			gs.Wait()
+		}
+		if gs.Player.HP != 10 {
+			t.Errorf("%s: healed to %d HP before resting %d turns", d, gs.Player.HP, n)
+		}
+		gs.Wait()
+		if gs.Player.HP != 11 {
+			t.Errorf("%s: resting %d turns away from enemies should restore 1 HP, got %d", d, n, gs.Player.HP)
+		}
+	}
+}
+
+func TestRegenerationStopsAtMaxHP(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.HP = gs.Player.MaxHP
+	for turn := 0; turn < 3*DifficultyNormal.rules().regenTurns; turn++ {
+		gs.Wait()
+	}
+	if gs.Player.HP != gs.Player.MaxHP {
+		t.Errorf("Regeneration went past max HP: %d of %d", gs.Player.HP, gs.Player.MaxHP)
+	}
+}
+
+func TestNoRegenerationNextToEnemiesOrOnMergeConflict(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.HP = 10
+	gs.Player.Damage = 0 // The bug survives being auto-attacked
+	gs.Invulnerable = true
+	gs.Enemies = []*Entity{NewBug(2, 2)}
+	for turn := 0; turn < 2*DifficultyNormal.rules().regenTurns; turn++ {
+		gs.Wait()
+	}
+	if gs.Player.HP != 10 {
+		t.Errorf("The player shouldn't regenerate next to an enemy, got %d HP", gs.Player.HP)
+	}
+
+	gs.Enemies = nil
+	gs.OnMergeConflict = true
+	gs.regenerate()
+	if gs.Player.RegenTurns != 0 {
+		t.Error("Standing on the merge conflict should stop the player resting")
+	}
+}
+
+func TestCoopPlayersRestSeparately(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player2 = NewPlayer(1, 3)
+	gs.Player.HP, gs.Player2.HP = 10, 10
+	regenTurns := DifficultyNormal.rules().regenTurns
+
+	// Player 2 resting as often as player 1 doesn't speed player 1 up
+	for turn := 0; turn < regenTurns-1; turn++ {
+		gs.Wait()
+		gs.MovePlayer2(0, 0)
+	}
+	if gs.Player.HP != 10 || gs.Player2.HP != 10 {
+		t.Fatalf("Neither player should have regenerated yet, got %d and %d HP", gs.Player.HP, gs.Player2.HP)
+	}
+	if gs.Player.RegenTurns != regenTurns-1 || gs.Player2.RegenTurns != regenTurns-1 {
+		t.Errorf("Expected each player to have rested %d turns, got %d and %d", regenTurns-1, gs.Player.RegenTurns, gs.Player2.RegenTurns)
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+// Merge conflict resolver: with a cooldown set, each tile a triggered merge
+// marker tore apart heals back to floor after that many turns with the player
+// off the marker. The edge of the damage heals first, so the fire shrinks in
+// toward the marker rather than vanishing all at once. Fire that chased the
+// player off a merge conflict trap goes out the same way, each tile it spread
+// to burning for that many turns once no player is on the trap.
+
+// startMergeCooldown (re)starts the timer of a tile the merge marker just
+// tore apart. Tiles further from the marker get less time.
+func (gs *GameState) startMergeCooldown(x, y int) {
+	if gs.MergeCooldown <= 0 {
+		return
+	}
+	if gs.MergeCooldownLeft == nil {
+		gs.MergeCooldownLeft = make(map[[2]int]int)
+	}
+	dist := max(abs(x-gs.MergeMarkerX), abs(y-gs.MergeMarkerY))
+	gs.MergeCooldownLeft[[2]int{x, y}] = gs.MergeCooldown + 1 - dist
+}
+
+// startChaseCooldown starts the timer of a tile chasing fire just spread to
+func (gs *GameState) startChaseCooldown(tile [2]int) {
+	if gs.MergeCooldown <= 0 {
+		return
+	}
+	if gs.MergeChaseLeft == nil {
+		gs.MergeChaseLeft = make(map[[2]int]int)
+	}
+	gs.MergeChaseLeft[tile] = gs.MergeCooldown
+}
+
+// coolMergeFire counts down the merge-affected tiles' timers, clearing the
+// tiles whose time is up. Nothing cools while the player stands on the marker.
+func (gs *GameState) coolMergeFire() {
+	if gs.MergeCooldown <= 0 {
+		return
+	}
+	gs.coolChasingFire()
+	if gs.livingPlayerAt(gs.MergeMarkerX, gs.MergeMarkerY) != nil {
+		return
+	}
+	for tile := range gs.MergeAffectedTiles {
+		left, ok := gs.MergeCooldownLeft[tile]
+		if !ok {
+			continue // Not torn apart by the marker, so nothing to resolve
+		}
+		if left > 1 {
+			gs.MergeCooldownLeft[tile] = left - 1
+			continue
+		}
+		delete(gs.MergeAffectedTiles, tile)
+		delete(gs.MergeCooldownLeft, tile)
+	}
+}
+
+// coolChasingFire counts down the timers of the tiles chasing fire spread to,
+// putting out the ones whose time is up. The fire keeps burning while a
+// player stands on the trap.
+func (gs *GameState) coolChasingFire() {
+	if gs.livingPlayerAt(gs.MergeConflictX, gs.MergeConflictY) != nil {
+		return
+	}
+	kept := gs.MergeConflictSpread[:0]
+	for _, tile := range gs.MergeConflictSpread {
+		left, ok := gs.MergeChaseLeft[tile]
+		if !ok {
+			kept = append(kept, tile) // Part of the trap's own fire
+			continue
+		}
+		if left > 1 {
+			gs.MergeChaseLeft[tile] = left - 1
+			kept = append(kept, tile)
+			continue
+		}
+		delete(gs.MergeChaseLeft, tile)
+	}
+	gs.MergeConflictSpread = kept
+}
@@ -0,0 +1,107 @@
+package game
+
+import "testing"
+
+func newResolverState(cooldown int) *GameState {
+	gs := newTestState(20, 10)
+	gs.MergeCooldown = cooldown
+	gs.MergeMarkerX, gs.MergeMarkerY = 10, 5
+	gs.Player.X, gs.Player.Y = 10, 5
+	gs.triggerMergeConflict()
+	return gs
+}
+
+func TestMergeTilesClearAfterCooldown(t *testing.T) {
+	gs := newResolverState(3)
+	gs.Player.X, gs.Player.Y = 2, 2
+
+	for turn := 1; turn < 3; turn++ {
+		gs.coolMergeFire()
+		if len(gs.MergeAffectedTiles) != 9 {
+			t.Fatalf("After %d turns expected all 9 tiles still affected, got %d", turn, len(gs.MergeAffectedTiles))
+		}
+	}
+
+	gs.coolMergeFire()
+	if len(gs.MergeAffectedTiles) != 1 || !gs.IsMergeAffected(10, 5) {
+		t.Fatalf("After the cooldown only the marker should still be affected, got %v", gs.MergeAffectedTiles)
+	}
+
+	gs.coolMergeFire()
+	if len(gs.MergeAffectedTiles) != 0 {
+		t.Errorf("Expected every tile to have cleared, got %v", gs.MergeAffectedTiles)
+	}
+}
+
+func TestMergeTilesPersistWhileOnMarker(t *testing.T) {
+	gs := newResolverState(2)
+
+	for turn := 0; turn < 10; turn++ {
+		gs.coolMergeFire()
+	}
+	if len(gs.MergeAffectedTiles) != 9 {
+		t.Errorf("Tiles shouldn't cool while the player stands on the marker, got %d affected", len(gs.MergeAffectedTiles))
+	}
+}
+
+func TestMergeTilesPersistWithoutCooldown(t *testing.T) {
+	gs := newResolverState(0)
+	gs.Player.X, gs.Player.Y = 2, 2
+
+	for turn := 0; turn < 10; turn++ {
+		gs.processTurn()
+	}
+	if len(gs.MergeAffectedTiles) != 9 {
+		t.Errorf("Without a cooldown the tiles should stay affected, got %d", len(gs.MergeAffectedTiles))
+	}
+}
+
+func TestMergeTilesClearDuringPlay(t *testing.T) {
+	gs := newResolverState(2)
+	gs.Player.X, gs.Player.Y = 2, 2
+
+	for turn := 0; turn < 3; turn++ {
+		gs.MovePlayer(1, 0)
+	}
+	if len(gs.MergeAffectedTiles) != 0 {
+		t.Errorf("Expected the tiles to clear after moving away for 3 turns, got %v", gs.MergeAffectedTiles)
+	}
+}
+
+func TestChasingFireGoesOutAfterCooldown(t *testing.T) {
+	gs := newTestState(40, 20)
+	gs.MergeFireChase = true
+	gs.MergeCooldown = 2
+	gs.MergeConflictX, gs.MergeConflictY = 20, 10
+	gs.Player.X, gs.Player.Y = 35, 10
+	gs.Invulnerable = true
+	gs.generateMergeConflictSpread()
+	gs.MergeConflictTriggered = true
+	trapFire := len(gs.MergeConflictSpread)
+
+	gs.checkMergeConflict()
+	gs.coolMergeFire()
+	if len(gs.MergeConflictSpread) != trapFire+1 {
+		t.Fatalf("Chased fire should still burn after 1 turn, got %d tiles, expected %d", len(gs.MergeConflictSpread), trapFire+1)
+	}
+	gs.coolMergeFire()
+	if len(gs.MergeConflictSpread) != trapFire || len(gs.MergeChaseLeft) != 0 {
+		t.Errorf("Chased fire should go out after the cooldown, leaving the trap's %d tiles, got %d", trapFire, len(gs.MergeConflictSpread))
+	}
+}
+
+func TestChasingFireBurnsWhileOnTrap(t *testing.T) {
+	gs := newTestState(40, 20)
+	gs.MergeCooldown = 1
+	gs.MergeConflictX, gs.MergeConflictY = 20, 10
+	gs.Player.X, gs.Player.Y = 20, 10
+	gs.MergeConflictSpread = [][2]int{{24, 10}}
+	gs.startChaseCooldown([2]int{24, 10})
+
+	for turn := 0; turn < 5; turn++ {
+		gs.coolMergeFire()
+	}
+	if len(gs.MergeConflictSpread) != 1 {
+		t.Errorf("Chased fire shouldn't go out while the player stands on the trap, got %v", gs.MergeConflictSpread)
+	}
+}
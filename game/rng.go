@@ -0,0 +1,80 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// RNGAlgorithm selects the random number source behind a run's dungeons
+type RNGAlgorithm int
+
+const (
+	RNGStdlib   RNGAlgorithm = iota // Go's math/rand source
+	RNGXorshift                     // Self-contained xorshift64*, identical on every Go version and platform
+)
+
+// ParseRNGAlgorithm converts an --rng value into an RNGAlgorithm
+func ParseRNGAlgorithm(s string) (RNGAlgorithm, error) {
+	switch s {
+	case "stdlib":
+		return RNGStdlib, nil
+	case "xorshift":
+		return RNGXorshift, nil
+	}
+	return RNGStdlib, fmt.Errorf("unknown RNG algorithm %q (want stdlib or xorshift)", s)
+}
+
+// String returns the algorithm's name as given to --rng
+func (a RNGAlgorithm) String() string {
+	if a == RNGXorshift {
+		return "xorshift"
+	}
+	return "stdlib"
+}
+
+// newRNG creates the random number generator for a run. The xorshift source
+// only replaces the raw bit stream; math/rand's helpers (Intn, Float64,
+// Shuffle) are frozen by the Go 1 compatibility promise.
+func newRNG(seed int64, algorithm RNGAlgorithm) *rand.Rand {
+	if algorithm == RNGXorshift {
+		return rand.New(newXorshiftSource(seed))
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// xorshiftSource is a xorshift64* generator implementing rand.Source64
+type xorshiftSource struct {
+	state uint64
+}
+
+func newXorshiftSource(seed int64) *xorshiftSource {
+	src := &xorshiftSource{}
+	src.Seed(seed)
+	return src
+}
+
+// Seed scrambles the seed with splitmix64 so nearby seeds give unrelated
+// sequences and the state is never the all-zero value xorshift can't leave
+func (s *xorshiftSource) Seed(seed int64) {
+	z := uint64(seed) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	if z == 0 {
+		z = 0x9e3779b97f4a7c15
+	}
+	s.state = z
+}
+
+// Uint64 advances the generator and returns the next 64 random bits
+func (s *xorshiftSource) Uint64() uint64 {
+	s.state ^= s.state >> 12
+	s.state ^= s.state << 25
+	s.state ^= s.state >> 27
+	return s.state * 0x2545f4914f6cdd1d
+}
+
+// Int63 returns a non-negative 63-bit value, as rand.Source requires
+func (s *xorshiftSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
@@ -0,0 +1,67 @@
+package game
+
+import "testing"
+
+func TestXorshiftSourceKnownSequence(t *testing.T) {
+	// Reference values computed independently of Go; any change here
+	// changes every dungeon generated with --rng xorshift
+	src := newXorshiftSource(12345)
+	want := []uint64{0x47edfd1cd809b6dc, 0x34d004209d31c6ba, 0x38b855ac9296d1e9, 0xeeafe2de7f2488e4}
+	for i, w := range want {
+		if got := src.Uint64(); got != w {
+			t.Errorf("Value %d: got %#x, expected %#x", i, got, w)
+		}
+	}
+}
+
+func TestXorshiftSourceReseedRestartsSequence(t *testing.T) {
+	src := newXorshiftSource(7)
+	first := []int64{src.Int63(), src.Int63(), src.Int63()}
+
+	src.Seed(7)
+	for i, w := range first {
+		if got := src.Int63(); got != w {
+			t.Errorf("Value %d after reseed: got %d, expected %d", i, got, w)
+		}
+		if w < 0 {
+			t.Errorf("Int63 must not be negative, got %d", w)
+		}
+	}
+}
+
+func TestXorshiftSourceZeroSeed(t *testing.T) {
+	src := newXorshiftSource(0)
+	if src.Uint64() == 0 && src.Uint64() == 0 {
+		t.Error("Seed 0 should still produce a non-degenerate sequence")
+	}
+}
+
+func TestXorshiftDungeonsAreReproducible(t *testing.T) {
+	withXorshift := func(gs *GameState) { gs.RNGAlgorithm = RNGXorshift }
+	a := NewGameState(nil, 2024, 80, 40, withXorshift)
+	b := NewGameState(nil, 2024, 80, 40, withXorshift)
+
+	if a.DoorX != b.DoorX || a.DoorY != b.DoorY || a.Player.X != b.Player.X || a.Player.Y != b.Player.Y {
+		t.Errorf("Same seed should give the same level: door %d,%d vs %d,%d", a.DoorX, a.DoorY, b.DoorX, b.DoorY)
+	}
+	if len(a.Enemies) != len(b.Enemies) {
+		t.Errorf("Same seed should give the same enemies: %d vs %d", len(a.Enemies), len(b.Enemies))
+	}
+
+	stdlib := NewGameState(nil, 2024, 80, 40)
+	if stdlib.RNG.Int63() == a.RNG.Int63() {
+		t.Error("xorshift and stdlib should be different random sources")
+	}
+}
+
+func TestParseRNGAlgorithm(t *testing.T) {
+	if algo, err := ParseRNGAlgorithm("xorshift"); err != nil || algo != RNGXorshift {
+		t.Errorf("Expected xorshift, got %v (err %v)", algo, err)
+	}
+	if algo, err := ParseRNGAlgorithm("stdlib"); err != nil || algo != RNGStdlib {
+		t.Errorf("Expected stdlib, got %v (err %v)", algo, err)
+	}
+	if _, err := ParseRNGAlgorithm("mersenne"); err == nil {
+		t.Error("Unknown algorithms should be rejected")
+	}
+}
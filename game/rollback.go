@@ -0,0 +1,138 @@
+package game
+
+import "fmt"
+
+// MaxRollbacks is how many times a run with rollbacks enabled can return to a checkpoint
+const MaxRollbacks = 1
+
+// Checkpoint is the player's progress as it stood at the start of a level.
+// Levels are generated from the run seed and level number alone, so the
+// level itself doesn't need saving: it is rebuilt exactly on rollback.
+type Checkpoint struct {
+	Level          int
+	EndlessDepth   int
+	HP             int
+	MaxHP          int
+	EnemiesKilled  int
+	HotfixesHeld   int
+	PotionsSpawned int // Before the level's own potions, which rebuilding it spawns again
+	CodeRead       int
+	TechDebt       int
+	Armor          int
+	TorchTurnsLeft int
+	EquippedWeapon *Weapon
+	Damage         int
+	Player2HP      int
+	Player2MaxHP   int
+	Player2Damage  int
+	Player2Armor   int
+	Inventory      []*Entity
+	CarriedPotions []*Entity // Leftover potions brought onto the level in survival mode
+	Turns          int
+	DamageTaken    int
+	XP             int
+	Gold           int
+	LevelStats     LevelStats
+}
+
+// saveCheckpoint records the start of the current level, if rollbacks are
+// enabled, along with the potions carried onto it and how many potions the
+// run had spawned before the level's own
+func (gs *GameState) saveCheckpoint(carried []*Entity, potionsSpawned int) {
+	if gs.RollbacksLeft == 0 || gs.Player == nil {
+		return
+	}
+	gs.Checkpoint = &Checkpoint{
+		Level:          gs.Level,
+		EndlessDepth:   gs.EndlessDepth,
+		HP:             gs.Player.HP,
+		MaxHP:          gs.Player.MaxHP,
+		Damage:         gs.Player.Damage,
+		EnemiesKilled:  gs.EnemiesKilled,
+		HotfixesHeld:   gs.HotfixesHeld,
+		PotionsSpawned: potionsSpawned,
+		CodeRead:       gs.CodeRead,
+		TechDebt:       gs.TechDebt,
+		Armor:          gs.Player.Defense,
+		TorchTurnsLeft: gs.TorchTurnsLeft,
+		EquippedWeapon: gs.EquippedWeapon,
+		Inventory:      append([]*Entity(nil), gs.Inventory...),
+		CarriedPotions: copyEntities(carried),
+		Turns:          gs.Turns,
+		DamageTaken:    gs.DamageTaken,
+		XP:             gs.XP,
+		Gold:           gs.Gold,
+		LevelStats:     gs.LevelStats,
+	}
+	if gs.Player2 != nil {
+		gs.Checkpoint.Player2HP = gs.Player2.HP
+		gs.Checkpoint.Player2MaxHP = gs.Player2.MaxHP
+		gs.Checkpoint.Player2Damage = gs.Player2.Damage
+		gs.Checkpoint.Player2Armor = gs.Player2.Defense
+	}
+}
+
+// CanRollback reports whether a dead player can still roll back to a checkpoint
+func (gs *GameState) CanRollback() bool {
+	return gs.GameOver && gs.RollbacksLeft > 0 && gs.Checkpoint != nil
+}
+
+// Rollback undoes a death by rebuilding the checkpoint's level and restoring
+// the player's progress as it was when they arrived there. Returns false if
+// no rollback is available.
+func (gs *GameState) Rollback() bool {
+	if !gs.CanRollback() {
+		return false
+	}
+	cp := *gs.Checkpoint
+	gs.RollbacksLeft--
+
+	gs.Level = cp.Level
+	gs.EndlessDepth = cp.EndlessDepth
+	gs.GameOver = false
+	gs.KilledBy = ""
+	// The level's debt enemies come from the debt as it stood on arrival,
+	// not what the dead run ran up since
+	gs.TechDebt = cp.TechDebt
+	gs.PotionsSpawned = cp.PotionsSpawned
+	gs.Potions = copyEntities(cp.CarriedPotions)
+	gs.generateLevel()
+
+	// Regenerating checkpointed the dead player
+	gs.Player.HP = cp.HP
+	gs.Player.MaxHP = cp.MaxHP
+	gs.Player.Damage = cp.Damage
+	gs.EnemiesKilled = cp.EnemiesKilled
+	gs.HotfixesHeld = cp.HotfixesHeld
+	gs.CodeRead = cp.CodeRead
+	gs.Player.Defense = cp.Armor
+	gs.TorchTurnsLeft = cp.TorchTurnsLeft
+	gs.EquippedWeapon = cp.EquippedWeapon
+	gs.LevelStats = cp.LevelStats
+	gs.Inventory = append([]*Entity(nil), cp.Inventory...)
+	gs.Turns = cp.Turns
+	gs.DamageTaken = cp.DamageTaken
+	gs.XP = cp.XP
+	gs.Gold = cp.Gold
+	if gs.Player2 != nil {
+		gs.Player2.HP = cp.Player2HP
+		gs.Player2.MaxHP = cp.Player2MaxHP
+		gs.Player2.Damage = cp.Player2Damage
+		gs.Player2.Defense = cp.Player2Armor
+	}
+	gs.Checkpoint = &cp
+
+	gs.SetMessage(fmt.Sprintf("git reset --hard: rolled back to the start of level %d.", cp.Level))
+	return true
+}
+
+// copyEntities copies each entity, so a checkpoint keeps them as they were
+// however the run goes on to move or use them
+func copyEntities(entities []*Entity) []*Entity {
+	var copies []*Entity
+	for _, e := range entities {
+		c := *e
+		copies = append(copies, &c)
+	}
+	return copies
+}
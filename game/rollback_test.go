@@ -0,0 +1,141 @@
+package game
+
+import "testing"
+
+func newRollbackState() *GameState {
+	return NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.RollbacksLeft = MaxRollbacks
+	})
+}
+
+// descend walks the player onto the door, reaching the next level
+func descend(gs *GameState) {
+	gs.Enemies = nil
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.MovePlayer(1, 0)
+}
+
+func TestRollbackRestoresCheckpoint(t *testing.T) {
+	gs := newRollbackState()
+	gs.Player.HP = 13
+	gs.EnemiesKilled = 4
+	descend(gs)
+	if gs.Level != 2 {
+		t.Fatalf("Expected to reach level 2, got %d", gs.Level)
+	}
+	startX, startY := gs.Player.X, gs.Player.Y
+	doorX, doorY := gs.DoorX, gs.DoorY
+
+	// Wander off, take a beating and die
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.EnemiesKilled = 9
+	gs.LevelStats = LevelStats{Kills: 5, Turns: 30, DamageTaken: 13}
+	gs.Player.TakeDamage(gs.Player.HP)
+	gs.GameOver = true
+	gs.KilledBy = "bug"
+
+	if !gs.Rollback() {
+		t.Fatal("Expected a rollback to be available")
+	}
+	if gs.GameOver || gs.KilledBy != "" {
+		t.Error("Rolling back should undo the death")
+	}
+	if gs.Level != 2 {
+		t.Errorf("Expected to be back on level 2, got %d", gs.Level)
+	}
+	if gs.Player.HP != 13 {
+		t.Errorf("Expected HP 13 from the checkpoint, got %d", gs.Player.HP)
+	}
+	if gs.Player.X != startX || gs.Player.Y != startY {
+		t.Errorf("Expected to be back at the level start %d,%d, got %d,%d", startX, startY, gs.Player.X, gs.Player.Y)
+	}
+	if gs.DoorX != doorX || gs.DoorY != doorY {
+		t.Errorf("Rollback should rebuild the same level, door moved from %d,%d to %d,%d", doorX, doorY, gs.DoorX, gs.DoorY)
+	}
+	if gs.EnemiesKilled != 4 {
+		t.Errorf("Expected the kill count from the checkpoint (4), got %d", gs.EnemiesKilled)
+	}
+	if gs.LevelStats != gs.Checkpoint.LevelStats {
+		t.Errorf("Expected the level stats from the checkpoint %+v, got %+v", gs.Checkpoint.LevelStats, gs.LevelStats)
+	}
+}
+
+func TestRollbackOncePerRun(t *testing.T) {
+	gs := newRollbackState()
+
+	for i := 0; i < MaxRollbacks; i++ {
+		gs.Player.TakeDamage(gs.Player.HP)
+		gs.GameOver = true
+		if !gs.Rollback() {
+			t.Fatalf("Rollback %d should be allowed", i+1)
+		}
+	}
+
+	gs.Player.TakeDamage(gs.Player.HP)
+	gs.GameOver = true
+	if gs.CanRollback() || gs.Rollback() {
+		t.Errorf("Only %d rollback(s) should be allowed per run", MaxRollbacks)
+	}
+	if !gs.GameOver {
+		t.Error("A refused rollback should leave the player dead")
+	}
+}
+
+func TestNoRollbackWhenDisabled(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	gs.Player.TakeDamage(gs.Player.HP)
+	gs.GameOver = true
+
+	if gs.Checkpoint != nil || gs.Rollback() {
+		t.Error("Runs without --rollback shouldn't checkpoint or roll back")
+	}
+}
+
+func TestRollbackKey(t *testing.T) {
+	g := &Game{state: newRollbackState()}
+	g.state.Player.TakeDamage(g.state.Player.HP)
+	g.state.GameOver = true
+
+	if g.handleKey(runeKey('r')) {
+		t.Error("r should roll back, not exit")
+	}
+	if g.state.GameOver {
+		t.Error("Expected r to roll back the death")
+	}
+}
+
+func TestRollbackSpawnsCheckpointDebt(t *testing.T) {
+	gs := newRollbackState()
+	enemies := len(gs.Enemies)
+
+	// Debt run up after the checkpoint dies with the player
+	gs.TechDebt = TechDebtThreshold * 2
+	gs.Player.TakeDamage(gs.Player.HP)
+	gs.GameOver = true
+	gs.Rollback()
+
+	if gs.TechDebt != 0 || len(gs.Enemies) != enemies {
+		t.Errorf("Expected the level rebuilt with no debt and %d enemies, got debt %d and %d enemies", enemies, gs.TechDebt, len(gs.Enemies))
+	}
+}
+
+func TestRollbackBringsBackCarriedPotions(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.RollbacksLeft = MaxRollbacks
+		gs.PotionBudget = 7
+	})
+	gs.Potions = gs.Potions[:1]
+	gs.Level++
+	gs.generateLevel()
+	potions := len(gs.Potions)
+
+	// The dead run drank everything on the floor
+	gs.Potions = nil
+	gs.Player.TakeDamage(gs.Player.HP)
+	gs.GameOver = true
+	gs.Rollback()
+
+	if len(gs.Potions) != potions {
+		t.Errorf("Expected the level rebuilt with its %d potions, carried one included, got %d", potions, len(gs.Potions))
+	}
+}
@@ -0,0 +1,225 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// saveVersion is bumped whenever a change to GameState would make older
+// saves load wrongly
+const saveVersion = 2
+
+// saveFile is the JSON form of a saved game. Most of the state is stored as
+// is; the fields JSON can't hold (maps keyed by tiles or rooms, and entities
+// shared with other fields) are stored alongside it as tiles and indices.
+type saveFile struct {
+	Version           int          `json:"version"`
+	State             *GameState   `json:"state"`
+	Connections       [][2]int     `json:"connections"`         // Indices into Dungeon.Rooms
+	MergeAffected     [][2]int     `json:"merge_affected"`      // Tiles in MergeAffectedTiles
+	MergeCooldownLeft []savedTimer `json:"merge_cooldown_left"` // Entries of MergeCooldownLeft
+	MergeChaseLeft    []savedTimer `json:"merge_chase_left"`    // Entries of MergeChaseLeft
+	CodeSmells        []savedSmell `json:"code_smells"`         // Entries of CodeSmells
+	MergeQueueWave    []int        `json:"merge_queue_wave"`    // Indices into Enemies
+}
+
+// savedTimer is a tile with turns left on it
+type savedTimer struct {
+	X, Y  int
+	Turns int
+}
+
+// savedSmell is a hazed room and the enemies it started with that are still alive
+type savedSmell struct {
+	Room    int   // Index into Dungeon.Rooms
+	Enemies []int // Indices into Enemies
+}
+
+// SavePath returns where a game saved in play is kept for --continue
+func SavePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gh-dungeons", "save.json"), nil
+}
+
+// Save writes the whole run to path as JSON, creating its directory if needed
+func (gs *GameState) Save(path string) error {
+	save := saveFile{Version: saveVersion, State: gs}
+	rooms := gs.Dungeon.Rooms
+	for _, conn := range gs.Dungeon.Connections {
+		save.Connections = append(save.Connections, [2]int{slices.Index(rooms, conn[0]), slices.Index(rooms, conn[1])})
+	}
+	for tile, affected := range gs.MergeAffectedTiles {
+		if affected {
+			save.MergeAffected = append(save.MergeAffected, tile)
+		}
+	}
+	for tile, turns := range gs.MergeCooldownLeft {
+		save.MergeCooldownLeft = append(save.MergeCooldownLeft, savedTimer{X: tile[0], Y: tile[1], Turns: turns})
+	}
+	for tile, turns := range gs.MergeChaseLeft {
+		save.MergeChaseLeft = append(save.MergeChaseLeft, savedTimer{X: tile[0], Y: tile[1], Turns: turns})
+	}
+	for room, enemies := range gs.CodeSmells {
+		smell := savedSmell{Room: slices.Index(rooms, room)}
+		for _, enemy := range enemies {
+			// Dead enemies have left Enemies, and only the living matter for clearing the haze
+			if i := slices.Index(gs.Enemies, enemy); i >= 0 {
+				smell.Enemies = append(smell.Enemies, i)
+			}
+		}
+		save.CodeSmells = append(save.CodeSmells, smell)
+	}
+	for _, enemy := range gs.MergeQueueWave {
+		if i := slices.Index(gs.Enemies, enemy); i >= 0 {
+			save.MergeQueueWave = append(save.MergeQueueWave, i)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(save)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Load reads a run saved by Save. The random number generator's internal
+// state can't be saved, so it is reseeded from the run seed, level and turn
+// count: a loaded game always plays out the same way from the save, though
+// not with the rolls the unsaved run would have had. Code files for later
+// levels aren't saved either; the caller supplies them as for a new run.
+func Load(path string) (*GameState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	save := saveFile{State: &GameState{}}
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if save.Version != saveVersion {
+		return nil, fmt.Errorf("%s was saved by an incompatible version of the game", path)
+	}
+	gs := save.State
+	if gs.Player == nil || gs.Dungeon == nil || len(gs.Visible) != gs.Dungeon.Height || len(gs.Explored) != gs.Dungeon.Height {
+		return nil, fmt.Errorf("%s is not a complete saved game", path)
+	}
+
+	rooms := gs.Dungeon.Rooms
+	room := func(i int) (*Room, error) {
+		if i < 0 || i >= len(rooms) {
+			return nil, fmt.Errorf("%s refers to room %d of %d", path, i, len(rooms))
+		}
+		return rooms[i], nil
+	}
+	enemy := func(i int) (*Entity, error) {
+		if i < 0 || i >= len(gs.Enemies) {
+			return nil, fmt.Errorf("%s refers to enemy %d of %d", path, i, len(gs.Enemies))
+		}
+		return gs.Enemies[i], nil
+	}
+
+	for _, conn := range save.Connections {
+		a, err := room(conn[0])
+		if err != nil {
+			return nil, err
+		}
+		b, err := room(conn[1])
+		if err != nil {
+			return nil, err
+		}
+		gs.Dungeon.Connections = append(gs.Dungeon.Connections, [2]*Room{a, b})
+	}
+	gs.MergeAffectedTiles = make(map[[2]int]bool)
+	for _, tile := range save.MergeAffected {
+		gs.MergeAffectedTiles[tile] = true
+	}
+	gs.MergeCooldownLeft = make(map[[2]int]int)
+	for _, timer := range save.MergeCooldownLeft {
+		gs.MergeCooldownLeft[[2]int{timer.X, timer.Y}] = timer.Turns
+	}
+	gs.MergeChaseLeft = make(map[[2]int]int)
+	for _, timer := range save.MergeChaseLeft {
+		gs.MergeChaseLeft[[2]int{timer.X, timer.Y}] = timer.Turns
+	}
+	gs.CodeSmells = make(map[*Room][]*Entity)
+	for _, smell := range save.CodeSmells {
+		r, err := room(smell.Room)
+		if err != nil {
+			return nil, err
+		}
+		gs.CodeSmells[r] = []*Entity{}
+		for _, i := range smell.Enemies {
+			e, err := enemy(i)
+			if err != nil {
+				return nil, err
+			}
+			gs.CodeSmells[r] = append(gs.CodeSmells[r], e)
+		}
+	}
+	for _, i := range save.MergeQueueWave {
+		e, err := enemy(i)
+		if err != nil {
+			return nil, err
+		}
+		gs.MergeQueueWave = append(gs.MergeQueueWave, e)
+	}
+
+	gs.RNG = newRNG(levelSeed(gs.Seed, gs.Level)+int64(gs.Turns), gs.RNGAlgorithm)
+	return gs, nil
+}
+
+// saveGame saves the run in play so it can be picked up again with --continue
+func (g *Game) saveGame() {
+	if g.options.savePath == "" || g.demoMode || g.state.Tutorial {
+		g.state.SetMessage("This game can't be saved.")
+		return
+	}
+	if err := g.state.Save(g.options.savePath); err != nil {
+		g.state.SetMessage(fmt.Sprintf("Couldn't save the game: %v", err))
+		return
+	}
+	g.saved = true
+	g.state.SetMessage("Game saved. Pick it up again with --continue.")
+}
+
+// loadSavedState resumes the run saved at the save path, sized to the current screen
+func (g *Game) loadSavedState() (*GameState, error) {
+	state, err := Load(g.options.savePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.New("there is no saved game to continue")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range g.options.resumeOverrides {
+		override(state)
+	}
+	width, height := g.screen.Size()
+	state.Resize(width, height)
+	state.CodeLibrary = g.library
+	state.SetMessage("Welcome back! Your saved game has been restored.")
+	return state, nil
+}
+
+// discardFinishedSave deletes the save once the run it belongs to is over,
+// so --continue never resumes a game that has already ended
+func (g *Game) discardFinishedSave() {
+	if !g.saved || !(g.state.GameOver || g.state.Victory) {
+		return
+	}
+	g.saved = false
+	if err := os.Remove(g.options.savePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		g.state.SetMessage(fmt.Sprintf("Couldn't remove the saved game: %v", err))
+	}
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.RollbacksLeft = MaxRollbacks
+	})
+	if len(gs.Enemies) == 0 || len(gs.Dungeon.Rooms) < 2 {
+		t.Fatal("Test level should have enemies and more than one room")
+	}
+	for _, dir := range [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+		gs.MovePlayer(dir[0], dir[1])
+	}
+	carryPotions(gs, PotionSmall, PotionHuge)
+	gs.MergeAffectedTiles[[2]int{3, 4}] = true
+	gs.MergeCooldownLeft = map[[2]int]int{{3, 4}: 5}
+	gs.MergeChaseLeft = map[[2]int]int{{3, 4}: 2}
+	gs.CodeSmells = map[*Room][]*Entity{gs.Dungeon.Rooms[1]: {gs.Enemies[0]}}
+	gs.MergeQueueWave = []*Entity{gs.Enemies[len(gs.Enemies)-1]}
+
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := gs.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"Player", loaded.Player, gs.Player},
+		{"Enemies", loaded.Enemies, gs.Enemies},
+		{"Potions", loaded.Potions, gs.Potions},
+		{"Inventory", loaded.Inventory, gs.Inventory},
+		{"Tiles", loaded.Dungeon.Tiles, gs.Dungeon.Tiles},
+		{"Rooms", loaded.Dungeon.Rooms, gs.Dungeon.Rooms},
+		{"Explored", loaded.Explored, gs.Explored},
+		{"Visible", loaded.Visible, gs.Visible},
+		{"Checkpoint", loaded.Checkpoint, gs.Checkpoint},
+		{"MergeAffectedTiles", loaded.MergeAffectedTiles, gs.MergeAffectedTiles},
+		{"MergeCooldownLeft", loaded.MergeCooldownLeft, gs.MergeCooldownLeft},
+		{"MergeChaseLeft", loaded.MergeChaseLeft, gs.MergeChaseLeft},
+		{"Level", loaded.Level, gs.Level},
+		{"Seed", loaded.Seed, gs.Seed},
+		{"Turns", loaded.Turns, gs.Turns},
+		{"Door", [2]int{loaded.DoorX, loaded.DoorY}, [2]int{gs.DoorX, gs.DoorY}},
+		{"Message", loaded.Message, gs.Message},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s changed in the round trip: got %+v, want %+v", c.name, c.got, c.want)
+		}
+	}
+
+	// Shared entities and rooms must point into the loaded state, not at copies
+	if len(loaded.Dungeon.Connections) != len(gs.Dungeon.Connections) {
+		t.Fatalf("Got %d connections, want %d", len(loaded.Dungeon.Connections), len(gs.Dungeon.Connections))
+	}
+	for _, conn := range loaded.Dungeon.Connections {
+		if !slices.Contains(loaded.Dungeon.Rooms, conn[0]) || !slices.Contains(loaded.Dungeon.Rooms, conn[1]) {
+			t.Fatal("Connections should join the loaded level's rooms")
+		}
+	}
+	enemies := loaded.CodeSmells[loaded.Dungeon.Rooms[1]]
+	if len(loaded.CodeSmells) != 1 || len(enemies) != 1 || enemies[0] != loaded.Enemies[0] {
+		t.Errorf("Code smell should be restored on room 1 with the first enemy, got %v", loaded.CodeSmells)
+	}
+	if len(loaded.MergeQueueWave) != 1 || loaded.MergeQueueWave[0] != loaded.Enemies[len(loaded.Enemies)-1] {
+		t.Error("The merge queue wave should be restored as the loaded enemies")
+	}
+	if loaded.RNG == nil {
+		t.Error("Load should seed a random number generator")
+	}
+}
+
+func TestLoadedGamesPlayOutTheSame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := NewGameState(nil, 99, 80, 40).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	var runs [2]*GameState
+	for i := range runs {
+		gs, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		for turn := 0; turn < 30; turn++ {
+			gs.MovePlayer([]int{1, 0, -1}[turn%3], []int{0, 1, -1}[turn%3])
+		}
+		runs[i] = gs
+	}
+	if !reflect.DeepEqual(runs[0].Player, runs[1].Player) || !reflect.DeepEqual(runs[0].Enemies, runs[1].Enemies) {
+		t.Error("The same save should play out the same way every time it is loaded")
+	}
+}
+
+func TestLoadRejectsBadSaves(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Loading a missing save should report it doesn't exist, got %v", err)
+	}
+	for name, content := range map[string]string{
+		"corrupt":    "{not json",
+		"old":        `{"version": 0, "state": {}}`,
+		"incomplete": `{"version": 1, "state": {"Level": 2}}`,
+	} {
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Loading a %s save should fail", name)
+		}
+	}
+}
+
+func TestSaveKeyThenContinue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	g, err := NewHeadless(WithSeed(7), WithSaveFile(path))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	g.Play(KeyEvents("lljS")...)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("S should save the game: %v", err)
+	}
+
+	resumed, err := NewHeadless(WithSaveFile(path), WithContinue(true))
+	if err != nil {
+		t.Fatalf("Continuing: %v", err)
+	}
+	want, got := g.State(), resumed.State()
+	if got.Seed != want.Seed || got.Turns != want.Turns || !reflect.DeepEqual(got.Player, want.Player) {
+		t.Errorf("Continued run at seed %d turn %d, player %+v; want seed %d turn %d, player %+v",
+			got.Seed, got.Turns, got.Player, want.Seed, want.Turns, want.Player)
+	}
+
+	// Once the saved run ends there is nothing left to continue
+	resumed.State().GameOver = true
+	resumed.Play(KeyEvents("x")...)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("The save should be removed when its run ends, got %v", err)
+	}
+	if _, err := NewHeadless(WithSaveFile(path), WithContinue(true)); err == nil {
+		t.Error("Continuing without a saved game should fail")
+	}
+}
+
+func TestContinueAppliesOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	g, err := NewHeadless(WithSeed(7), WithSaveFile(path), WithASCII(false), WithVisionRadius(4))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	g.Play(KeyEvents("S")...)
+
+	resumed, err := NewHeadless(WithSaveFile(path), WithContinue(true), WithResumeOverrides(func(gs *GameState) {
+		gs.ASCII = true
+	}))
+	if err != nil {
+		t.Fatalf("Continuing: %v", err)
+	}
+	if gs := resumed.State(); !gs.ASCII || gs.VisionRadius != 4 {
+		t.Errorf("Expected the override applied over the saved options, got ASCII %v and vision %d", gs.ASCII, gs.VisionRadius)
+	}
+}
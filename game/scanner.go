@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 )
 
 var codeExtensions = map[string]bool{
@@ -54,19 +58,67 @@ var codeExtensions = map[string]bool{
 }
 
 type CodeFile struct {
-	Path  string
-	Lines []string
-	SHA   string
+	Path    string
+	Lines   []string
+	SHA     string
+	ModTime time.Time
 }
 
-func findCodeFiles(root string, minLines, maxFiles int) ([]CodeFile, error) {
-	var candidates []CodeFile
+// ScanOrder controls which code files are picked for levels
+type ScanOrder int
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+const (
+	ScanByLines   ScanOrder = iota // Longest files first
+	ScanByRecency                  // Most recently modified files first
+)
+
+// ParseScanOrder converts a --scan-order value into a ScanOrder
+func ParseScanOrder(s string) (ScanOrder, error) {
+	switch s {
+	case "lines":
+		return ScanByLines, nil
+	case "recent":
+		return ScanByRecency, nil
+	}
+	return ScanByLines, fmt.Errorf("unknown scan order %q (want lines or recent)", s)
+}
+
+// CheckSourceDir makes sure dir is a directory code files can be read from
+func CheckSourceDir(dir string) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("source directory %s doesn't exist", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("source directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("source directory %s is not a directory", dir)
+	}
+	return nil
+}
+
+// walkCodeFiles calls found, in walk order, for every code file under root
+// with at least minLines lines, leaving out whatever root's ignore file lists
+func walkCodeFiles(root string, minLines int, found func(CodeFile)) error {
+	ignore, err := loadIgnoreFile(root)
+	if err != nil {
+		return err
+	}
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
+		// Skip what the ignore file lists, directories and all
+		if rel, err := filepath.Rel(root, path); err == nil && rel != "." && ignore.ignores(filepath.ToSlash(rel), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Skip hidden directories and common non-code directories
 		if info.IsDir() {
 			name := info.Name()
@@ -101,43 +153,45 @@ func findCodeFiles(root string, minLines, maxFiles int) ([]CodeFile, error) {
 			hash := sha256.Sum256([]byte(content))
 			sha := string(hash[:])
 
-			candidates = append(candidates, CodeFile{
-				Path:  path,
-				Lines: lines,
-				SHA:   sha,
+			found(CodeFile{
+				Path:    path,
+				Lines:   lines,
+				SHA:     sha,
+				ModTime: info.ModTime(),
 			})
 		}
 
 		return nil
 	})
+}
 
-	if err != nil {
-		return nil, err
-	}
-
-	// Sort by line count (prefer longer files for more interesting backgrounds)
-	sort.Slice(candidates, func(i, j int) bool {
-		return len(candidates[i].Lines) > len(candidates[j].Lines)
-	})
-
-	// Take up to maxFiles
-	if len(candidates) > maxFiles {
-		candidates = candidates[:maxFiles]
+// sortCodeFiles orders candidates so the preferred files come first
+func sortCodeFiles(files []CodeFile, order ScanOrder) {
+	switch order {
+	case ScanByRecency:
+		// Prefer what you've been working on lately
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].ModTime.After(files[j].ModTime)
+		})
+	default:
+		// Sort by line count (prefer longer files for more interesting backgrounds)
+		sort.SliceStable(files, func(i, j int) bool {
+			return len(files[i].Lines) > len(files[j].Lines)
+		})
 	}
-
-	return candidates, nil
 }
 
-func computeSeed(files []CodeFile) int64 {
+// computeSeed derives the run seed from the repository at root and the code files found in it
+func computeSeed(root string, files []CodeFile) int64 {
 	h := sha256.New()
 
 	// Include git repo identity (remote origin or repo name)
-	if repoID := getRepoIdentity(); repoID != "" {
+	if repoID := getRepoIdentity(root); repoID != "" {
 		h.Write([]byte(repoID))
 	}
 
 	// Include current git commit SHA
-	if commitSHA := getGitCommitSHA(); commitSHA != "" {
+	if commitSHA := getGitCommitSHA(root); commitSHA != "" {
 		h.Write([]byte(commitSHA))
 	}
 
@@ -150,10 +204,11 @@ func computeSeed(files []CodeFile) int64 {
 	return int64(binary.BigEndian.Uint64(sum[:8]))
 }
 
-// getRepoIdentity returns the git remote origin URL or repo root name
-func getRepoIdentity() string {
+// getRepoIdentity returns the git remote origin URL or repo root name of the repository in dir
+func getRepoIdentity(dir string) string {
 	// Try to get remote origin URL first (unique across forks)
 	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	cmd.Dir = dir
 	if output, err := cmd.Output(); err == nil {
 		if url := strings.TrimSpace(string(output)); url != "" {
 			return url
@@ -162,6 +217,7 @@ func getRepoIdentity() string {
 
 	// Fall back to repo root directory name
 	cmd = exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
 	if output, err := cmd.Output(); err == nil {
 		if root := strings.TrimSpace(string(output)); root != "" {
 			return filepath.Base(root)
@@ -171,9 +227,10 @@ func getRepoIdentity() string {
 	return ""
 }
 
-// getGitCommitSHA returns the current HEAD commit SHA
-func getGitCommitSHA() string {
+// getGitCommitSHA returns the HEAD commit SHA of the repository in dir
+func getGitCommitSHA(dir string) string {
 	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
 	if output, err := cmd.Output(); err == nil {
 		return strings.TrimSpace(string(output))
 	}
@@ -211,61 +268,70 @@ type MergeConflictLocation struct {
 	CenterLine int
 }
 
-// findMergeConflict searches for merge conflicts in the repository
-func findMergeConflict(root string) *MergeConflictLocation {
-	var result *MergeConflictLocation
+// findMergeConflicts searches the repository for files with unresolved merge
+// conflicts: a <<<<<<< line, then =======, then >>>>>>>. Each conflicted file
+// is reported once, at its first conflict, in the order the walk finds them.
+func findMergeConflicts(root string) []MergeConflictLocation {
+	var conflicts []MergeConflictLocation
 
 	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil || result != nil {
+		if err != nil {
 			return nil
 		}
 
 		// Skip hidden directories and common non-code directories
 		if info.IsDir() {
 			name := info.Name()
-			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "dist" || name == "build" {
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "dist" || name == "build") {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Check if file has merge conflict markers
-		file, err := os.Open(path)
-		if err != nil {
-			return nil
-		}
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		lineNum := 0
-		startLine := -1
-		endLine := -1
-
-		for scanner.Scan() {
-			lineNum++
-			line := scanner.Text()
-
-			if strings.HasPrefix(line, "<<<<<<<") {
-				startLine = lineNum
-			} else if strings.HasPrefix(line, ">>>>>>>") && startLine != -1 {
-				endLine = lineNum
-				// Found a complete merge conflict
-				relPath, _ := filepath.Rel(root, path)
-				if relPath == "" {
-					relPath = path
-				}
-				result = &MergeConflictLocation{
-					File:       relPath,
-					StartLine:  startLine,
-					EndLine:    endLine,
-					CenterLine: (startLine + endLine) / 2,
-				}
-				return nil
+		if conflict, ok := fileMergeConflict(path); ok {
+			relPath, err := filepath.Rel(root, path)
+			if err != nil {
+				relPath = path
 			}
+			conflict.File = relPath
+			conflicts = append(conflicts, conflict)
 		}
-
 		return nil
 	})
 
-	return result
+	return conflicts
+}
+
+// fileMergeConflict returns the first complete set of conflict markers in a file
+func fileMergeConflict(path string) (MergeConflictLocation, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return MergeConflictLocation{}, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	startLine := -1
+	separated := false
+
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<"):
+			startLine = lineNum
+			separated = false
+		case strings.HasPrefix(line, "=======") && startLine != -1:
+			separated = true
+		case strings.HasPrefix(line, ">>>>>>>") && separated:
+			return MergeConflictLocation{
+				StartLine:  startLine,
+				EndLine:    lineNum,
+				CenterLine: (startLine + lineNum) / 2,
+			}, true
+		}
+	}
+	return MergeConflictLocation{}, false
 }
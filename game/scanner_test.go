@@ -0,0 +1,275 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCodeFile writes a Go file with the given number of lines and modification time
+func writeCodeFile(t *testing.T, dir, name string, lines int, modTime time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	content := strings.Repeat("// line\n", lines)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScanCodeLibraryRecencyPrefersRecentFile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	older := writeCodeFile(t, dir, "older.go", 200, now.Add(-30*24*time.Hour))
+	recent := writeCodeFile(t, dir, "recent.go", 80, now.Add(-time.Hour))
+
+	files, err := scanCodeLibrary(dir, 60, QuickScanFiles, 1, ScanByRecency).QuickFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	if files[0].Path != recent {
+		t.Errorf("Recency mode should prefer the recently modified shorter file, got %v", files[0].Path)
+	}
+
+	files, err = scanCodeLibrary(dir, 60, QuickScanFiles, 1, ScanByLines).QuickFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	if files[0].Path != older {
+		t.Errorf("Line mode should prefer the longer file, got %v", files[0].Path)
+	}
+}
+
+func TestCodeLibraryRecencyOrdersByModTime(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	week := writeCodeFile(t, dir, "week.go", 300, now.Add(-7*24*time.Hour))
+	hour := writeCodeFile(t, dir, "hour.go", 70, now.Add(-time.Hour))
+	day := writeCodeFile(t, dir, "day.go", 150, now.Add(-24*time.Hour))
+
+	// What the game builds levels from, not just the ranking underneath it
+	files, err := scanCodeLibrary(dir, 60, QuickScanFiles, MaxCodeFiles, ScanByRecency).QuickFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{hour, day, week}
+	if len(files) != len(want) {
+		t.Fatalf("Expected %d files, got %d", len(want), len(files))
+	}
+	for i, path := range want {
+		if files[i].Path != path {
+			t.Errorf("File %d should be %s, got %s", i, filepath.Base(path), filepath.Base(files[i].Path))
+		}
+	}
+}
+
+func TestParseScanOrder(t *testing.T) {
+	if order, err := ParseScanOrder("lines"); err != nil || order != ScanByLines {
+		t.Errorf("ParseScanOrder(lines) = %v, %v", order, err)
+	}
+	if order, err := ParseScanOrder("recent"); err != nil || order != ScanByRecency {
+		t.Errorf("ParseScanOrder(recent) = %v, %v", order, err)
+	}
+	if _, err := ParseScanOrder("random"); err == nil {
+		t.Error("Unknown scan orders should be rejected")
+	}
+}
+
+// writeConflictFixture fills dir with two conflicted files and a few near misses
+func writeConflictFixture(t *testing.T, dir string) {
+	t.Helper()
+	conflicted := "package main\n<<<<<<< HEAD\nfunc a() {}\n=======\nfunc b() {}\n>>>>>>> feature\n"
+	files := map[string]string{
+		"main.go":        conflicted,
+		"docs/README.md": "# Docs\n\n" + conflicted,
+		"clean.go":       "package main\n\nfunc main() {}\n",
+		"half.go":        "<<<<<<< HEAD\nno separator\n>>>>>>> feature\n",
+		".git/ORIG_HEAD": conflicted,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindMergeConflictsDetectsConflictedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeConflictFixture(t, dir)
+
+	conflicts := findMergeConflicts(dir)
+	files := conflictedFiles(conflicts)
+	want := []string{filepath.Join("docs", "README.md"), "main.go"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected conflicted files %v, got %v", want, files)
+	}
+	if c := conflicts[1]; c.StartLine != 2 || c.EndLine != 6 || c.CenterLine != 4 {
+		t.Errorf("main.go conflict should span lines 2-6, got %+v", c)
+	}
+}
+
+func TestMergeModeReportsConflictedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeConflictFixture(t, dir)
+	t.Chdir(dir)
+
+	g, err := NewHeadless(WithMergeMode(true))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	if !g.mergeMode {
+		t.Fatal("Merge mode should stay on when conflicts are found")
+	}
+	if !strings.HasPrefix(g.state.Message, "2 conflicted files detected") {
+		t.Errorf("Expected the conflict count in the message, got %q", g.state.Message)
+	}
+	if got := g.state.ConflictedFiles(); len(got) != 2 || got[1] != "main.go" {
+		t.Errorf("The state should expose the conflicted files, got %v", got)
+	}
+}
+
+func TestEachConflictedFileGetsItsOwnLevel(t *testing.T) {
+	conflicts := []MergeConflictLocation{{File: "a.go"}, {File: "b.go"}}
+	for level, want := range map[int]string{1: "a.go", 2: "b.go", 3: ""} {
+		gs := NewGameState(nil, 42, 80, 24, func(gs *GameState) {
+			gs.MergeConflicts = conflicts
+			gs.Level = level
+		})
+		got := ""
+		if gs.MergeConflict != nil {
+			got = gs.MergeConflict.File
+		}
+		if got != want {
+			t.Errorf("Level %d: expected the conflict in %q, got %q", level, want, got)
+		}
+		if hasMarker := gs.MergeMarkerX >= 0; hasMarker != (want != "") {
+			t.Errorf("Level %d: marker placed = %v, want %v", level, hasMarker, want != "")
+		}
+	}
+}
+
+func TestIgnoreFileExcludesMatchingFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for _, sub := range []string{"generated", "src/gen", "src/app"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCodeFile(t, dir, "generated/api.go", 500, now)
+	writeCodeFile(t, dir, "src/gen/models.go", 400, now)
+	writeCodeFile(t, dir, "src/app/schema.pb.go", 300, now)
+	writeCodeFile(t, dir, "src/app/keep.pb.go", 200, now)
+	writeCodeFile(t, dir, "src/app/main.go", 100, now)
+	writeCodeFile(t, dir, "huge.go", 900, now)
+	ignore := "# Generated code\ngenerated/\n/src/gen\n*.pb.go\n!keep.pb.go\n/huge.go\n"
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte(ignore), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := scanCodeLibrary(dir, 60, QuickScanFiles, 10, ScanByLines).QuickFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{"src/app/keep.pb.go", "src/app/main.go"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected only %v to be left after the ignore file, got %v", want, got)
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	var rules ignoreRules
+	for _, line := range []string{"build-output/", "docs/**/*.js", "/root.go", "*_test.go", "!keep_test.go"} {
+		rule, ok := parseIgnoreRule(line)
+		if !ok {
+			t.Fatalf("%q should parse as a rule", line)
+		}
+		rules = append(rules, rule)
+	}
+	for _, c := range []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"build-output", true, true},
+		{"a/b/build-output", true, true},
+		{"build-output", false, false}, // A trailing slash only matches directories
+		{"docs/site.js", false, true},
+		{"docs/a/b/site.js", false, true},
+		{"src/docs/site.js", false, false},
+		{"root.go", false, true},
+		{"sub/root.go", false, false},
+		{"pkg/game_test.go", false, true},
+		{"pkg/keep_test.go", false, false},
+	} {
+		if got := rules.ignores(c.path, c.isDir); got != c.want {
+			t.Errorf("ignores(%q, dir=%v) = %v, want %v", c.path, c.isDir, got, c.want)
+		}
+	}
+	for _, line := range []string{"", "   ", "# comment", "/"} {
+		if _, ok := parseIgnoreRule(line); ok {
+			t.Errorf("%q shouldn't parse as a rule", line)
+		}
+	}
+}
+
+func TestScanCodeLibraryHonorsCustomRoot(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for _, sub := range []string{"other", "target/pkg"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCodeFile(t, dir, "other/outside.go", 500, now)
+	inside := writeCodeFile(t, dir, "target/pkg/inside.go", 100, now)
+
+	files, err := scanCodeLibrary(filepath.Join(dir, "target"), 60, QuickScanFiles, 5, ScanByLines).QuickFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != inside {
+		t.Errorf("Expected only the file under the root, got %d files", len(files))
+	}
+}
+
+func TestSourceDirOption(t *testing.T) {
+	dir := t.TempDir()
+	source := writeCodeFile(t, dir, "source.go", 100, time.Now())
+	t.Chdir(t.TempDir()) // No code in the working directory
+
+	g, err := NewHeadless(WithSourceDir(dir))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	if files := g.library.Files(); len(files) != 1 || files[0].Path != source {
+		t.Errorf("Expected the level code to come from the source directory, got %d files", len(files))
+	}
+
+	if _, err := NewHeadless(WithSourceDir(filepath.Join(dir, "missing"))); err == nil || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Errorf("A missing source directory should fail clearly, got %v", err)
+	}
+	if _, err := NewHeadless(WithSourceDir(source)); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("A file given as the source directory should fail clearly, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Final score: points for every level cleared and enemy killed, a bonus for
+// finishing each level quickly and for the damage avoided along the way, and
+// bonuses for achievements earned by a winning run
+const (
+	ScoreLevelPoints     = 1000
+	ScoreKillPoints      = 50
+	ScoreParTurns        = 150 // Turns per cleared level before the speed bonus runs out
+	ScoreTurnPoints      = 2   // Per turn under par
+	ScoreDamageAllowance = 100 // HP that can be lost before the damage bonus runs out
+	ScoreDamagePoints    = 5   // Per HP of the allowance not lost
+	ScoreAchievement     = 500
+)
+
+// Grade thresholds: a run scoring at least this much earns the grade
+const (
+	GradeSScore = 8000
+	GradeAScore = 5000
+	GradeBScore = 2000
+)
+
+// RunScore is how a finished run scored
+type RunScore struct {
+	Points       int
+	Grade        string   // S, A, B or C
+	Achievements []string // Bonuses earned, in the order they're checked
+}
+
+// LevelsCleared is how many levels the player got through: all of them on
+// victory, otherwise every level before the one they're on
+func (gs *GameState) LevelsCleared() int {
+	if gs.Victory {
+		return gs.Level
+	}
+	return gs.Level - 1
+}
+
+// achievements lists the bonuses a run has earned. Only a winning run earns any.
+func (gs *GameState) achievements() []string {
+	if !gs.Victory {
+		return nil
+	}
+	var earned []string
+	if gs.EnemiesKilled == 0 {
+		earned = append(earned, "Pacifist")
+	}
+	if gs.DamageTaken == 0 {
+		earned = append(earned, "Untouchable")
+	}
+	if gs.TechDebt == 0 {
+		earned = append(earned, "Debt free")
+	}
+	return earned
+}
+
+// FinalScore scores the run as it stands
+func (gs *GameState) FinalScore() RunScore {
+	cleared := gs.LevelsCleared()
+	achievements := gs.achievements()
+
+	points := cleared*ScoreLevelPoints + gs.EnemiesKilled*ScoreKillPoints
+	points += max(cleared*ScoreParTurns-gs.Turns, 0) * ScoreTurnPoints
+	if cleared > 0 {
+		points += max(ScoreDamageAllowance-gs.DamageTaken, 0) * ScoreDamagePoints
+	}
+	points += len(achievements) * ScoreAchievement
+
+	return RunScore{Points: points, Grade: scoreGrade(points), Achievements: achievements}
+}
+
+// scoreGrade turns a score into a letter grade
+func scoreGrade(points int) string {
+	switch {
+	case points >= GradeSScore:
+		return "S"
+	case points >= GradeAScore:
+		return "A"
+	case points >= GradeBScore:
+		return "B"
+	}
+	return "C"
+}
+
+// ScoreEntry is one finished run in the high-score file
+type ScoreEntry struct {
+	Username      string    `json:"username"`
+	LevelsCleared int       `json:"levels_cleared"`
+	EnemiesKilled int       `json:"enemies_killed"`
+	Score         int       `json:"score"`
+	Grade         string    `json:"grade"`
+	Victory       bool      `json:"victory"`
+	Time          time.Time `json:"time"`
+}
+
+// ScoresPath returns where finished runs are recorded
+func ScoresPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gh-dungeons", "scores.json"), nil
+}
+
+// LoadScores reads every recorded run, oldest first. A missing file means no
+// runs have been recorded yet.
+func LoadScores() ([]ScoreEntry, error) {
+	path, err := ScoresPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var scores []ScoreEntry
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return scores, nil
+}
+
+// SaveScore appends the run as it stands to the high-score file. A file that
+// can't be read back is started afresh rather than blocking the save.
+func SaveScore(gs *GameState) error {
+	path, err := ScoresPath()
+	if err != nil {
+		return err
+	}
+	scores, _ := LoadScores()
+	score := gs.FinalScore()
+	scores = append(scores, ScoreEntry{
+		Username:      gs.Username,
+		LevelsCleared: gs.LevelsCleared(),
+		EnemiesKilled: gs.EnemiesKilled,
+		Score:         score.Points,
+		Grade:         score.Grade,
+		Victory:       gs.Victory,
+		Time:          time.Now(),
+	})
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// bestKills is the most enemies killed in any recorded run
+func bestKills(scores []ScoreEntry) int {
+	best := 0
+	for _, s := range scores {
+		best = max(best, s.EnemiesKilled)
+	}
+	return best
+}
+
+// recordScore saves the run to the high-score file the first time it ends,
+// noting whether it beat the best kill count so far. Rolling back un-ends the
+// run, so dying again afterwards records it again.
+func (g *Game) recordScore() {
+	if !g.state.GameOver && !g.state.Victory {
+		g.scoreRecorded = false
+		return
+	}
+	if !g.options.highScores || g.demoMode || g.state.Tutorial || g.scoreRecorded {
+		return
+	}
+	g.scoreRecorded = true
+
+	scores, _ := LoadScores()
+	g.newHighScore = g.state.EnemiesKilled > bestKills(scores)
+	if err := SaveScore(g.state); err != nil {
+		g.state.SetMessage(fmt.Sprintf("Couldn't save score: %v", err))
+	}
+}
@@ -0,0 +1,196 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFinalScore(t *testing.T) {
+	tests := []struct {
+		name         string
+		level        int
+		victory      bool
+		kills        int
+		turns        int
+		damage       int
+		debt         int
+		points       int
+		grade        string
+		achievements []string
+	}{
+		{
+			name: "clean victory", level: 5, victory: true, kills: 40, turns: 500, damage: 30,
+			points: 5000 + 2000 + 250*2 + 70*5 + 500, grade: "S", achievements: []string{"Debt free"},
+		},
+		{
+			name: "pacifist victory", level: 5, victory: true, kills: 0, turns: 400, damage: 60, debt: 25,
+			points: 5000 + 350*2 + 40*5 + 500, grade: "A", achievements: []string{"Pacifist"},
+		},
+		{
+			name: "died on level 3", level: 3, kills: 12, turns: 280, damage: 45, debt: 4,
+			points: 2000 + 600 + 20*2 + 55*5, grade: "B",
+		},
+		{
+			name: "slow and battered", level: 4, kills: 20, turns: 900, damage: 150, debt: 9,
+			points: 3000 + 1000, grade: "B",
+		},
+		{
+			name: "zero-kill death on level 1", level: 1, kills: 0, turns: 10, damage: 20,
+			points: 0, grade: "C",
+		},
+		{
+			name: "flawless pacifist", level: 5, victory: true, kills: 0, turns: 750, damage: 0,
+			points: 5000 + 100*5 + 3*500, grade: "A", achievements: []string{"Pacifist", "Untouchable", "Debt free"},
+		},
+	}
+	for _, tt := range tests {
+		gs := newTestState(10, 10)
+		gs.Level = tt.level
+		gs.Victory = tt.victory
+		gs.GameOver = !tt.victory
+		gs.EnemiesKilled = tt.kills
+		gs.Turns = tt.turns
+		gs.DamageTaken = tt.damage
+		gs.TechDebt = tt.debt
+
+		score := gs.FinalScore()
+		if score.Points != tt.points {
+			t.Errorf("%s: expected %d points, got %d", tt.name, tt.points, score.Points)
+		}
+		if score.Grade != tt.grade {
+			t.Errorf("%s: expected grade %s, got %s", tt.name, tt.grade, score.Grade)
+		}
+		if !slices.Equal(score.Achievements, tt.achievements) {
+			t.Errorf("%s: expected achievements %v, got %v", tt.name, tt.achievements, score.Achievements)
+		}
+	}
+}
+
+func TestScoreGradeThresholds(t *testing.T) {
+	tests := []struct {
+		points int
+		want   string
+	}{
+		{GradeSScore, "S"},
+		{GradeSScore - 1, "A"},
+		{GradeAScore, "A"},
+		{GradeBScore, "B"},
+		{GradeBScore - 1, "C"},
+		{0, "C"},
+	}
+	for _, tt := range tests {
+		if got := scoreGrade(tt.points); got != tt.want {
+			t.Errorf("scoreGrade(%d) = %s, want %s", tt.points, got, tt.want)
+		}
+	}
+}
+
+func TestRunCountersTrackTurnsAndDamage(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	gs.hurtPlayer(gs.Player, 3)
+	descend(gs)
+	gs.hurtPlayer(gs.Player, 2)
+
+	if gs.Turns != 1 {
+		t.Errorf("Expected the step onto the door to count as a turn, got %d", gs.Turns)
+	}
+	if gs.DamageTaken != 5 {
+		t.Errorf("Run damage should add up across levels, got %d", gs.DamageTaken)
+	}
+}
+
+func TestSaveScoreAppendsRuns(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	gs := newTestState(20, 10)
+	gs.Username = "octocat"
+	gs.Level = 3
+	gs.EnemiesKilled = 4
+	gs.GameOver = true
+	for range 2 {
+		if err := SaveScore(gs); err != nil {
+			t.Fatalf("SaveScore failed: %v", err)
+		}
+	}
+
+	scores, err := LoadScores()
+	if err != nil {
+		t.Fatalf("LoadScores failed: %v", err)
+	}
+	if len(scores) != 2 {
+		t.Fatalf("Expected 2 recorded runs, got %d", len(scores))
+	}
+	got := scores[1]
+	if got.Username != "octocat" || got.LevelsCleared != 2 || got.EnemiesKilled != 4 || got.Time.IsZero() {
+		t.Errorf("Unexpected score entry %+v", got)
+	}
+}
+
+func TestLoadScoresMissingOrCorrupt(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	scores, err := LoadScores()
+	if err != nil || scores != nil {
+		t.Fatalf("Missing file should load as no scores, got %v, %v", scores, err)
+	}
+
+	path, err := ScoresPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadScores(); err == nil {
+		t.Error("Expected an error loading a corrupt scores file")
+	}
+
+	// Saving over a corrupt file starts it afresh
+	gs := newTestState(20, 10)
+	gs.GameOver = true
+	if err := SaveScore(gs); err != nil {
+		t.Fatalf("SaveScore failed: %v", err)
+	}
+	if scores, err := LoadScores(); err != nil || len(scores) != 1 {
+		t.Errorf("Expected the corrupt file to be replaced with 1 run, got %v, %v", scores, err)
+	}
+}
+
+func TestRecordScoreShowsNewHighScore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	g, err := New(WithHeadless(true), WithSeed(12345), WithHighScores(true))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer g.Close()
+
+	g.state.EnemiesKilled = 3
+	g.state.GameOver = true
+	g.recordScore()
+	g.recordScore()
+
+	scores, _ := LoadScores()
+	if len(scores) != 1 {
+		t.Fatalf("Expected the run to be recorded once, got %d entries", len(scores))
+	}
+	if !g.newHighScore || !strings.Contains(strings.Join(g.endScreenLines(), "\n"), "New high score!") {
+		t.Error("Expected a new high score banner for the first run with kills")
+	}
+
+	// The next run has to beat 3 kills
+	g.state = g.newState(1)
+	g.recordScore()
+	g.state.EnemiesKilled = 3
+	g.state.GameOver = true
+	g.recordScore()
+	if g.newHighScore {
+		t.Error("Tying the best kill count shouldn't be a new high score")
+	}
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Settings are the options that can be changed in game from the settings menu
+type Settings struct {
+	RevealMap    bool  `json:"reveal_map"`    // No fog of war: the whole level is visible
+	AutoAttack   bool  `json:"auto_attack"`   // Attack adjacent enemies at the end of each turn
+	Diagonals    bool  `json:"diagonals"`     // Allow diagonal movement for the player and enemies
+	EnemyColors  bool  `json:"enemy_colors"`  // Draw each enemy type in its own color
+	Flash        bool  `json:"flash"`         // Flash the screen for dramatic effects like a hotfix
+	ManualPickup bool  `json:"manual_pickup"` // Potions stay on the floor until picked up with ','
+	Theme        Theme `json:"theme"`         // Colors the dungeon is drawn in
+}
+
+// DefaultSettings matches how the game plays without a settings file
+func DefaultSettings() Settings {
+	return Settings{AutoAttack: true, Diagonals: true, Flash: true}
+}
+
+// settingsMenu lists the menu entries in display order. Each is either an
+// on/off value or a theme that's cycled through.
+var settingsMenu = []struct {
+	label string
+	value func(*Settings) *bool
+	theme func(*Settings) *Theme
+}{
+	{label: "Reveal map (no fog of war)", value: func(s *Settings) *bool { return &s.RevealMap }},
+	{label: "Auto-attack adjacent enemies", value: func(s *Settings) *bool { return &s.AutoAttack }},
+	{label: "Diagonal movement", value: func(s *Settings) *bool { return &s.Diagonals }},
+	{label: "Per-type enemy colors", value: func(s *Settings) *bool { return &s.EnemyColors }},
+	{label: "Screen flashes", value: func(s *Settings) *bool { return &s.Flash }},
+	{label: "Manual potion pickup (,)", value: func(s *Settings) *bool { return &s.ManualPickup }},
+	{label: "Palette", theme: func(s *Settings) *Theme { return &s.Theme }},
+}
+
+// LoadSettings reads settings from path, returning the defaults if the file doesn't exist yet.
+// A file that can't be read or parsed also gives the defaults, along with the error.
+func LoadSettings(path string) (Settings, error) {
+	settings := DefaultSettings()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return DefaultSettings(), fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// Save writes the settings to path, creating its directory if needed
+func (s Settings) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// applySettings makes the current settings take effect on the running game
+func (g *Game) applySettings() {
+	g.enemyColors = g.settings.EnemyColors
+	g.theme = g.settings.Theme
+	g.state.NoDiagonals = !g.settings.Diagonals
+	g.state.NoAutoAttack = !g.settings.AutoAttack
+	g.state.RevealMap = g.settings.RevealMap
+	g.state.ManualPickup = g.settings.ManualPickup
+	g.state.updateVisibility()
+}
+
+// toggleSetting flips a settings menu entry, or moves on to the next theme,
+// applies it right away and saves it so the next run starts with it too.
+// Only what's changed in the menu is saved: settings forced by command line
+// flags keep their saved values.
+func (g *Game) toggleSetting(index int) {
+	entry := settingsMenu[index]
+	if entry.value != nil {
+		value := !*entry.value(&g.settings)
+		*entry.value(&g.settings), *entry.value(&g.savedSettings) = value, value
+	} else {
+		theme := (*entry.theme(&g.settings) + 1) % Theme(len(themes))
+		*entry.theme(&g.settings), *entry.theme(&g.savedSettings) = theme, theme
+	}
+	g.applySettings()
+
+	if g.settingsPath == "" {
+		return
+	}
+	if err := g.savedSettings.Save(g.settingsPath); err != nil {
+		g.state.SetMessage(fmt.Sprintf("Couldn't save settings: %v", err))
+	}
+}
+
+// handleSettingsKey navigates and toggles the open settings menu
+func (g *Game) handleSettingsKey(ev *tcell.EventKey) {
+	switch {
+	case ev.Key() == tcell.KeyEscape || ev.Rune() == 'o':
+		g.showSettings = false
+	case ev.Key() == tcell.KeyUp || ev.Rune() == 'k' || ev.Rune() == 'w':
+		g.settingsRow = (g.settingsRow + len(settingsMenu) - 1) % len(settingsMenu)
+	case ev.Key() == tcell.KeyDown || ev.Rune() == 'j' || ev.Rune() == 's':
+		g.settingsRow = (g.settingsRow + 1) % len(settingsMenu)
+	case ev.Key() == tcell.KeyEnter || ev.Rune() == ' ':
+		g.toggleSetting(g.settingsRow)
+	}
+}
+
+// renderSettings draws the settings menu as a box in the middle of the screen
+func (g *Game) renderSettings(width, height int) {
+	lines := []string{"Settings", ""}
+	for i, entry := range settingsMenu {
+		cursor := "  "
+		if i == g.settingsRow {
+			cursor = "> "
+		}
+		if entry.theme != nil {
+			lines = append(lines, fmt.Sprintf("%s%s: %s", cursor, entry.label, *entry.theme(&g.settings)))
+			continue
+		}
+		check := "[ ]"
+		if *entry.value(&g.settings) {
+			check = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s", cursor, check, entry.label))
+	}
+	lines = append(lines, "", "Enter: change   o/Esc: close")
+	g.renderBox(lines, width, height)
+}
+
+// renderBox draws lines of text in a box in the middle of the screen
+func (g *Game) renderBox(lines []string, width, height int) {
+	boxWidth := 0
+	for _, line := range lines {
+		boxWidth = max(boxWidth, utf8.RuneCountInString(line))
+	}
+	boxWidth += 4
+	boxHeight := len(lines) + 2
+	startX := max((width-boxWidth)/2, 0)
+	startY := max((height-boxHeight)/2, 0)
+
+	style := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorNavy)
+	for y := 0; y < boxHeight; y++ {
+		for x := 0; x < boxWidth; x++ {
+			g.frame.SetContent(startX+x, startY+y, ' ', nil, style)
+		}
+	}
+	for i, line := range lines {
+		for j, ch := range []rune(line) {
+			g.frame.SetContent(startX+2+j, startY+1+i, ch, nil, style)
+		}
+	}
+}
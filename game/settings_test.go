@@ -0,0 +1,195 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newSettingsTestGame(t *testing.T) *Game {
+	g := &Game{
+		state:         newTestState(20, 10),
+		settings:      DefaultSettings(),
+		savedSettings: DefaultSettings(),
+		settingsPath:  filepath.Join(t.TempDir(), "settings.json"),
+	}
+	g.applySettings()
+	return g
+}
+
+func TestSettingsMenuTogglesDiagonals(t *testing.T) {
+	g := newSettingsTestGame(t)
+
+	g.handleKey(runeKey('o'))
+	if !g.showSettings {
+		t.Fatal("o should open the settings menu")
+	}
+	// Diagonal movement is the third entry
+	g.handleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	g.handleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	g.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+
+	if g.settings.Diagonals || !g.state.NoDiagonals {
+		t.Error("Toggling diagonal movement should turn on NoDiagonals right away")
+	}
+
+	// Keys go to the menu while it's open, so the player shouldn't move
+	g.handleKey(runeKey('n'))
+	if g.state.Player.X != 1 || g.state.Player.Y != 1 {
+		t.Errorf("Player moved to %d,%d while the settings menu was open", g.state.Player.X, g.state.Player.Y)
+	}
+
+	g.handleKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if g.showSettings {
+		t.Error("Esc should close the settings menu")
+	}
+}
+
+func TestSettingsAutoAttackOff(t *testing.T) {
+	g := newSettingsTestGame(t)
+	g.toggleSetting(1)
+
+	enemy := NewScopeCreep(3, 1)
+	g.state.Enemies = []*Entity{enemy}
+	g.state.processTurn()
+
+	if enemy.HP != enemy.MaxHP {
+		t.Errorf("Adjacent enemy shouldn't be auto-attacked, HP %d/%d", enemy.HP, enemy.MaxHP)
+	}
+}
+
+func TestSettingsRevealMap(t *testing.T) {
+	g := newSettingsTestGame(t)
+	g.toggleSetting(0)
+
+	if !g.state.Visible[9][19] || !g.state.Explored[9][19] {
+		t.Error("Revealing the map should make the far corner visible")
+	}
+}
+
+func TestSettingsPersistToDisk(t *testing.T) {
+	g := newSettingsTestGame(t)
+	g.toggleSetting(3) // Per-type enemy colors on
+	g.toggleSetting(4) // Screen flashes off
+
+	loaded, err := LoadSettings(g.settingsPath)
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	if loaded != g.settings {
+		t.Errorf("Loaded %+v, expected %+v", loaded, g.settings)
+	}
+	if !loaded.EnemyColors || loaded.Flash {
+		t.Errorf("Expected enemy colors on and flashes off, got %+v", loaded)
+	}
+}
+
+func TestLoadSettingsMissingFile(t *testing.T) {
+	settings, err := LoadSettings(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("A missing settings file shouldn't be an error: %v", err)
+	}
+	if settings != DefaultSettings() {
+		t.Errorf("Expected defaults, got %+v", settings)
+	}
+}
+
+func TestSettingsFromFlagsAreNotSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	g, err := NewHeadless(WithSourceDir(t.TempDir()), WithSettingsFile(path), WithNoDiagonals(true), WithEnemyColors(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	g.toggleSetting(4) // Screen flashes off
+	if g.settings.Diagonals || !g.settings.EnemyColors {
+		t.Errorf("Flags should still be in effect, got %+v", g.settings)
+	}
+	loaded, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultSettings()
+	want.Flash = false
+	if loaded != want {
+		t.Errorf("Only the menu change should be saved, got %+v", loaded)
+	}
+}
+
+func TestSettingsPalettePersists(t *testing.T) {
+	g := newSettingsTestGame(t)
+	g.toggleSetting(len(settingsMenu) - 1)
+	if g.theme != ThemeHighContrast {
+		t.Fatalf("Picking the palette entry should move on to the high-contrast theme, got %s", g.theme)
+	}
+
+	data, err := os.ReadFile(g.settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"theme": "high-contrast"`) {
+		t.Errorf("Expected the theme saved by name, got %s", data)
+	}
+
+	saved, err := NewHeadless(WithSourceDir(t.TempDir()), WithSettingsFile(g.settingsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer saved.Close()
+	if saved.theme != ThemeHighContrast {
+		t.Errorf("The next run should start with the saved theme, got %s", saved.theme)
+	}
+
+	flagged, err := NewHeadless(WithSourceDir(t.TempDir()), WithSettingsFile(g.settingsPath), WithTheme(ThemeColorblind))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer flagged.Close()
+	if flagged.theme != ThemeColorblind {
+		t.Errorf("--theme should win over the saved theme, got %s", flagged.theme)
+	}
+}
+
+func TestCorruptSettingsFallBackToDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(`{"flash": fals`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := NewHeadless(WithSourceDir(t.TempDir()), WithSettingsFile(path))
+	if err != nil {
+		t.Fatalf("A corrupt settings file shouldn't stop the game: %v", err)
+	}
+	defer g.Close()
+	if g.settings != DefaultSettings() {
+		t.Errorf("Expected the default settings, got %+v", g.settings)
+	}
+	found := strings.Contains(g.state.Message, "Couldn't load your settings")
+	for _, queued := range g.state.MessageQueue {
+		found = found || strings.Contains(queued.text, "Couldn't load your settings")
+	}
+	if !found {
+		t.Error("Expected a warning that the settings couldn't be loaded")
+	}
+}
+
+func TestRenderBoxFitsMultiByteText(t *testing.T) {
+	g := &Game{frame: newFrameBuffer(20, 5)}
+	g.renderBox([]string{"héllo ✓"}, 20, 5)
+
+	// 7 characters plus 2 columns of padding each side, centered: columns 4 to 14
+	for x, want := range map[int]bool{3: false, 4: true, 14: true, 15: false} {
+		_, _, style, _ := g.frame.GetContent(x, 2)
+		_, bg, _ := style.Decompose()
+		if (bg == tcell.ColorNavy) != want {
+			t.Errorf("Column %d: in the box = %v, want %v", x, bg == tcell.ColorNavy, want)
+		}
+	}
+	if ch, _, _, _ := g.frame.GetContent(12, 2); ch != '✓' {
+		t.Errorf("Expected the last character at column 12, got %q", ch)
+	}
+}
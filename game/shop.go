@@ -0,0 +1,111 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Shop upgrades and what they cost. The shop opens on arriving at each even level.
+const (
+	ShopHealPrice   = 10
+	ShopHealAmount  = 10
+	ShopMaxHPPrice  = 25
+	ShopMaxHPAmount = 5
+	ShopDamagePrice = 40
+)
+
+// shopItems lists what the shop sells, in menu order. Upgrades apply to both
+// players in pair programming mode, who share their gold, but a player who
+// has fallen stays down.
+var shopItems = []struct {
+	label string
+	price int
+	buy   func(gs *GameState) string // Applies the upgrade and describes it
+}{
+	{fmt.Sprintf("Heal %d HP", ShopHealAmount), ShopHealPrice, func(gs *GameState) string {
+		for _, p := range gs.livingPlayers() {
+			p.Heal(ShopHealAmount)
+		}
+		return "You feel much better."
+	}},
+	{fmt.Sprintf("+%d max HP", ShopMaxHPAmount), ShopMaxHPPrice, func(gs *GameState) string {
+		for _, p := range gs.livingPlayers() {
+			p.MaxHP += ShopMaxHPAmount
+			p.Heal(ShopMaxHPAmount)
+		}
+		return fmt.Sprintf("Your max HP is now %d.", gs.Player.MaxHP)
+	}},
+	{"+1 damage", ShopDamagePrice, func(gs *GameState) string {
+		for _, p := range gs.livingPlayers() {
+			p.Damage++
+		}
+		return fmt.Sprintf("You now hit for %d.", gs.PlayerDamage())
+	}},
+}
+
+// OpenShop sets up shop in front of the player. Until it's closed, keys go to
+// the shop menu instead of moving the player.
+func (gs *GameState) OpenShop() {
+	gs.ShopOpen = true
+	gs.SetMessage(fmt.Sprintf("A merchant has set up shop on the stairs. You have %d gold.", gs.Gold))
+}
+
+// CloseShop leaves the shop and carries on down into the level
+func (gs *GameState) CloseShop() {
+	gs.ShopOpen = false
+}
+
+// CanAfford reports whether the player has the gold for a shop item
+func (gs *GameState) CanAfford(index int) bool {
+	return index >= 0 && index < len(shopItems) && gs.Gold >= shopItems[index].price
+}
+
+// BuyShopItem buys the shop item at index, deducting its price from Gold.
+// Returns false, leaving Gold alone, if the player can't afford it.
+func (gs *GameState) BuyShopItem(index int) bool {
+	if index < 0 || index >= len(shopItems) {
+		return false
+	}
+	item := shopItems[index]
+	if !gs.CanAfford(index) {
+		gs.SetMessage(fmt.Sprintf("%s costs %d gold, and you only have %d.", item.label, item.price, gs.Gold))
+		return false
+	}
+	gs.Gold -= item.price
+	gs.SetMessage(fmt.Sprintf("Bought %s for %d gold. %s", item.label, item.price, item.buy(gs)))
+	return true
+}
+
+// handleShopKey navigates the open shop menu, buys and leaves
+func (g *Game) handleShopKey(ev *tcell.EventKey) {
+	switch {
+	case ev.Key() == tcell.KeyEscape || ev.Rune() == 'x':
+		g.state.CloseShop()
+		g.shopRow = 0
+	case ev.Key() == tcell.KeyUp || ev.Rune() == 'k' || ev.Rune() == 'w':
+		g.shopRow = (g.shopRow + len(shopItems) - 1) % len(shopItems)
+	case ev.Key() == tcell.KeyDown || ev.Rune() == 'j' || ev.Rune() == 's':
+		g.shopRow = (g.shopRow + 1) % len(shopItems)
+	case ev.Key() == tcell.KeyEnter || ev.Rune() == ' ':
+		g.state.BuyShopItem(g.shopRow)
+	}
+}
+
+// renderShop draws the shop menu as a box in the middle of the screen
+func (g *Game) renderShop(width, height int) {
+	lines := []string{"Shop", fmt.Sprintf("You have %d gold", g.state.Gold), ""}
+	for i, item := range shopItems {
+		cursor := "  "
+		if i == g.shopRow {
+			cursor = "> "
+		}
+		price := fmt.Sprintf("%d gold", item.price)
+		if !g.state.CanAfford(i) {
+			price += " (can't afford)"
+		}
+		lines = append(lines, fmt.Sprintf("%s%-12s %s", cursor, item.label, price))
+	}
+	lines = append(lines, "", "Enter: buy   x/Esc: leave the shop")
+	g.renderBox(lines, width, height)
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestBuyShopItemNeedsEnoughGold(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.MaxHP = 30
+	gs.Player.HP = 10
+	gs.Gold = ShopHealPrice - 1
+
+	if gs.CanAfford(0) || gs.BuyShopItem(0) {
+		t.Fatal("A heal shouldn't be affordable one gold short")
+	}
+	if gs.Gold != ShopHealPrice-1 || gs.Player.HP != 10 {
+		t.Errorf("A failed purchase should change nothing, got %d gold and %d HP", gs.Gold, gs.Player.HP)
+	}
+
+	gs.Gold = ShopHealPrice
+	if !gs.CanAfford(0) || !gs.BuyShopItem(0) {
+		t.Fatal("A heal should be affordable with exactly its price")
+	}
+	if gs.Gold != 0 || gs.Player.HP != 10+ShopHealAmount {
+		t.Errorf("Expected 0 gold and %d HP after buying a heal, got %d gold and %d HP", 10+ShopHealAmount, gs.Gold, gs.Player.HP)
+	}
+}
+
+func TestShopUpgrades(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Gold = ShopMaxHPPrice + ShopDamagePrice
+	maxHP, damage := gs.Player.MaxHP, gs.Player.Damage
+
+	if !gs.BuyShopItem(1) || !gs.BuyShopItem(2) {
+		t.Fatal("Expected to afford both upgrades")
+	}
+	if gs.Player.MaxHP != maxHP+ShopMaxHPAmount || gs.Player.Damage != damage+1 {
+		t.Errorf("Expected %d max HP and %d damage, got %d and %d", maxHP+ShopMaxHPAmount, damage+1, gs.Player.MaxHP, gs.Player.Damage)
+	}
+	if gs.Gold != 0 || gs.BuyShopItem(2) {
+		t.Error("With no gold left nothing more should be affordable")
+	}
+	if gs.CanAfford(-1) || gs.CanAfford(len(shopItems)) || gs.BuyShopItem(len(shopItems)) {
+		t.Error("Items outside the shop can't be bought")
+	}
+}
+
+func TestShopDoesNotReviveFallenPlayer(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player2 = NewPlayer(5, 5)
+	gs.Player2.HP = 0
+	gs.Player.HP = 10
+	gs.Gold = ShopHealPrice + ShopMaxHPPrice + ShopDamagePrice
+	maxHP, damage := gs.Player2.MaxHP, gs.Player2.Damage
+
+	for item := range shopItems {
+		if !gs.BuyShopItem(item) {
+			t.Fatalf("Expected to afford shop item %d", item)
+		}
+	}
+	if gs.Player2.IsAlive() || gs.Player2.MaxHP != maxHP || gs.Player2.Damage != damage {
+		t.Errorf("A fallen player shouldn't be upgraded, got HP %d/%d and damage %d", gs.Player2.HP, gs.Player2.MaxHP, gs.Player2.Damage)
+	}
+	if gs.Player.HP != 10+ShopHealAmount+ShopMaxHPAmount {
+		t.Errorf("The surviving player should still be healed, got %d HP", gs.Player.HP)
+	}
+}
+
+func TestShopOpensOnEvenLevels(t *testing.T) {
+	gs := NewGameState(nil, 5, 80, 24)
+	for level := 2; level <= 4; level++ {
+		gs.takeDoor()
+		if gs.Level != level {
+			t.Fatalf("Expected to reach level %d, on level %d", level, gs.Level)
+		}
+		if want := level%2 == 0; gs.ShopOpen != want {
+			t.Errorf("Level %d: shop open = %v, want %v", level, gs.ShopOpen, want)
+		}
+		gs.CloseShop()
+	}
+}
+
+func TestShopTakesKeysUntilLeft(t *testing.T) {
+	g := newSettingsTestGame(t)
+	g.state.Gold = ShopMaxHPPrice
+	g.state.OpenShop()
+	maxHP := g.state.Player.MaxHP
+
+	// Movement keys move the cursor, not the player
+	g.handleKey(runeKey('j'))
+	g.handleKey(runeKey('l'))
+	if g.state.Player.X != 1 || g.state.Player.Y != 1 {
+		t.Errorf("Player moved to %d,%d while the shop was open", g.state.Player.X, g.state.Player.Y)
+	}
+	g.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if g.state.Player.MaxHP != maxHP+ShopMaxHPAmount || g.state.Gold != 0 {
+		t.Errorf("Enter on the second entry should buy max HP, got %d max HP and %d gold", g.state.Player.MaxHP, g.state.Gold)
+	}
+
+	if g.handleKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)) {
+		t.Fatal("Esc should leave the shop, not quit the game")
+	}
+	if g.state.ShopOpen {
+		t.Fatal("Esc should close the shop")
+	}
+	g.handleKey(runeKey('l'))
+	if g.state.Player.X != 2 {
+		t.Error("Once the shop is closed the player should move again")
+	}
+}
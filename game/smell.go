@@ -0,0 +1,82 @@
+package game
+
+import "slices"
+
+// Code smell haze: with --code-smells, some rooms that start with enemies in
+// them are filled with a haze that cuts the player's sight while inside.
+// Killing every enemy in the room or picking up an item there clears it.
+const (
+	CodeSmellChance       = 0.25 // Chance for each eligible room to be hazed
+	CodeSmellVisionRadius = 2    // How far the player sees from inside a hazed room
+)
+
+// spawnCodeSmells fills some of the level's occupied rooms with haze, each
+// remembering the enemies that were in it. The start room is always left clear.
+func (gs *GameState) spawnCodeSmells() {
+	gs.CodeSmells = make(map[*Room][]*Entity)
+	if !gs.CodeSmellsEnabled {
+		return
+	}
+	start := gs.Dungeon.StartRoom()
+	for _, room := range gs.Dungeon.Rooms {
+		if room == start {
+			continue
+		}
+		var enemies []*Entity
+		for _, enemy := range gs.Enemies {
+			if room.Contains(enemy.X, enemy.Y) {
+				enemies = append(enemies, enemy)
+			}
+		}
+		if len(enemies) > 0 && gs.RNG.Float64() < CodeSmellChance {
+			gs.CodeSmells[room] = enemies
+		}
+	}
+}
+
+// smellyRoomAt returns the hazed room containing (x, y), or nil
+func (gs *GameState) smellyRoomAt(x, y int) *Room {
+	for room := range gs.CodeSmells {
+		if room.Contains(x, y) {
+			return room
+		}
+	}
+	return nil
+}
+
+// InCodeSmell reports whether (x, y) is inside a hazed room
+func (gs *GameState) InCodeSmell(x, y int) bool {
+	return gs.smellyRoomAt(x, y) != nil
+}
+
+// visionRadiusAt is how far a player standing on (x, y) can see
+func (gs *GameState) visionRadiusAt(x, y int) int {
+	if gs.BlindTurns > 0 {
+		return CIBlindRadius
+	}
+	if gs.InCodeSmell(x, y) {
+		return CodeSmellVisionRadius
+	}
+	return gs.visionRadius()
+}
+
+// clearCodeSmell airs out a hazed room
+func (gs *GameState) clearCodeSmell(room *Room) {
+	delete(gs.CodeSmells, room)
+	gs.SetMessage("You cleaned up the code smell. The haze lifts.")
+}
+
+// clearDefeatedCodeSmells clears the haze from rooms whose enemies have all
+// been killed, wherever they wandered off to
+func (gs *GameState) clearDefeatedCodeSmells() {
+	for room, enemies := range gs.CodeSmells {
+		if !slices.ContainsFunc(enemies, (*Entity).IsAlive) {
+			gs.clearCodeSmell(room)
+		}
+	}
+}
+
+// itemCount is the number of items lying on the level
+func (gs *GameState) itemCount() int {
+	return len(gs.Potions) + len(gs.Reverts) + len(gs.Hotfixes) + len(gs.Armor) + len(gs.Torches) + len(gs.Weapons)
+}
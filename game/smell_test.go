@@ -0,0 +1,75 @@
+package game
+
+import "testing"
+
+// newSmellTestState puts the player inside a hazed room holding one bug
+func newSmellTestState() (*GameState, *Room, *Entity) {
+	gs := newTestState(30, 12)
+	room := &Room{X: 10, Y: 2, W: 10, H: 6}
+	gs.Dungeon.Rooms = []*Room{room}
+	bug := NewBug(18, 6)
+	gs.Enemies = []*Entity{bug}
+	gs.CodeSmells = map[*Room][]*Entity{room: {bug}}
+	gs.Player.X, gs.Player.Y = 11, 3
+	return gs, room, bug
+}
+
+func TestCodeSmellReducesVision(t *testing.T) {
+	gs, _, _ := newSmellTestState()
+
+	gs.updateVisibility()
+	if gs.Visible[3][11+CodeSmellVisionRadius+1] {
+		t.Error("Inside a hazed room the player shouldn't see past the haze radius")
+	}
+	if !gs.Visible[3][11+CodeSmellVisionRadius] {
+		t.Error("The player should still see within the haze radius")
+	}
+
+	gs.Player.X, gs.Player.Y = 3, 3
+	gs.updateVisibility()
+	if !gs.Visible[3][3+DefaultVisionRadius] {
+		t.Error("Outside the hazed room the player should see as far as usual")
+	}
+}
+
+func TestKillingRoomEnemiesClearsCodeSmell(t *testing.T) {
+	gs, _, bug := newSmellTestState()
+	bug.X, bug.Y = 12, 3
+
+	gs.MovePlayer(1, 0)
+	if bug.IsAlive() {
+		t.Fatal("Expected the bug to die")
+	}
+	if gs.InCodeSmell(11, 3) {
+		t.Fatal("Killing the room's only enemy should clear the haze")
+	}
+	if !gs.Visible[3][11+DefaultVisionRadius] {
+		t.Error("Normal vision should be restored once the haze clears")
+	}
+}
+
+func TestFindingItemClearsCodeSmell(t *testing.T) {
+	gs, _, bug := newSmellTestState()
+	bug.HP = 100
+	gs.Potions = []*Entity{NewPotion(12, 3)}
+
+	gs.MovePlayer(1, 0)
+	if gs.InCodeSmell(12, 3) {
+		t.Error("Picking up an item in a hazed room should clear the haze")
+	}
+}
+
+func TestCodeSmellsOnlyFillOccupiedRooms(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		gs := NewGameState(nil, seed, 80, 40, func(gs *GameState) { gs.CodeSmellsEnabled = true })
+		start := gs.Dungeon.StartRoom()
+		for room, enemies := range gs.CodeSmells {
+			if room == start {
+				t.Errorf("Seed %d: the start room shouldn't be hazed", seed)
+			}
+			if len(enemies) == 0 {
+				t.Errorf("Seed %d: a hazed room should have enemies to clear it with", seed)
+			}
+		}
+	}
+}
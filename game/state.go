@@ -3,17 +3,22 @@ package game
 import (
 	"fmt"
 	"math/rand"
+	"path/filepath"
+	"sort"
 
 	"github.com/gdamore/tcell/v2"
 )
 
-const VisionRadius = 7
+// DefaultVisionRadius is how far the player sees unless --vision says otherwise
+const DefaultVisionRadius = 7
+
 const MergeConflictWarning = "WARNING: MERGE CONFLICT DETECTED. TREAD CAREFULLY."
 
 type GameState struct {
 	Player                 *Entity
 	Enemies                []*Entity
 	Potions                []*Entity
+	Reverts                []*Entity         // Rare items that restore the level to its pristine state
 	Dungeon                *Dungeon
 	Level                  int
 	MaxLevel               int
@@ -25,11 +30,14 @@ type GameState struct {
 	Victory                bool
 	EnemiesKilled          int
 	Message                string
-	MessageStyle           tcell.Style           // Style for the message (e.g., red for damage)
-	CodeFiles              []CodeFile
-	RNG                    *rand.Rand
+	MessageStyle           tcell.Style       `json:"-"` // Style for the message (e.g., red for damage)
+	CodeFiles              []CodeFile        `json:"-"`
+	CodeLibrary            *CodeLibrary      `json:"-"` // Where levels get their code in play, in place of CodeFiles
+	RNG                    *rand.Rand        `json:"-"`
 	TermWidth              int
 	TermHeight             int
+	LevelWidth             int               // Size every level is generated at instead of fitting the terminal (0 = fit)
+	LevelHeight            int
 	KonamiSequence         []string
 	Invulnerable           bool
 	MoveCount              int
@@ -45,24 +53,108 @@ type GameState struct {
 	MergeConflict          *MergeConflictLocation
 	MergeMarkerX           int
 	MergeMarkerY           int
-	MergeAffectedTiles     map[int]bool      // key: y*width + x
+	MergeAffectedTiles     map[[2]int]bool   `json:"-"` // key: {x, y} in world coordinates
 	MergeAnimationStep     int               // cycles merge conflict markers on each move
+	NoDiagonals            bool              // Restrict player and enemies to 4-directional movement
+	LevelFileName          string            // Base name of the code file driving the current level
+	PersistentEnemies      bool              // Alerted enemies keep hunting the player without line of sight
+	MergeQueue             bool              // Triggering the merge marker spawns a wave of conflicting commits
+	MergeQueueWave         []*Entity         `json:"-"` // Conflicting commits spawned by the merge queue this level
+	MergeFireDamage        bool              // Every merge conflict fire tile burns the player, not just the trap center
+	PotionBudget           int               // Total potions available across the whole run (0 = unlimited)
+	PotionsSpawned         int               // Potions spawned so far this run
+	MergeFocus             int               // Index into MergeConflictPositions being highlighted, -1 for none
+	Endless                bool              // Keep generating levels past MaxLevel instead of ending in victory
+	EndlessDepth           int               // Levels descended beyond MaxLevel in endless mode
+	Slowed                 bool              // Player stepped on a lint warning; enemies get an extra action this turn
+	RoomErosion            float64           // Chance (0-1) of carving each wall tile around a room for rougher shapes
+	Tutorial               bool              // Play the hand-authored tutorial levels instead of a generated dungeon
+	TutorialStep           int               // Current tutorial lesson (see tutorial.go)
+	TutorialLessonDone     bool              // The current lesson's action has been performed, so its door leads on
+	Seed                   int64             // Seed the run was started with
+	ExploredDir            string            // Directory to remember explored maps in per seed and level ("" = off)
+	PristineTiles          [][]Tile          // Level tiles as generated, restored by a revert
+	CrowdBlocksSight       bool              // Other living enemies block an enemy's line of sight to the player
+	Avatar                 rune              // Custom player symbol (0 = '@')
+	StackTrace             []StackFrame      // Damaging trail left by a fleeing boss
+	MergeFireChase         bool              // Merge conflict fire spreads toward the player each turn
+	BugMergeChance         float64           // Chance two adjacent bugs merge at the end of a turn
+	RNGAlgorithm           RNGAlgorithm      // Random source seeded from the run's seed
+	Hotfixes               []*Entity         // Rare items that wipe out every enemy on the level
+	HotfixesHeld           int               // Hotfixes picked up and not yet used
+	HotfixFlash            bool              // A hotfix was just deployed; the map flashes until the next key
+	NoAutoAttack           bool              // Adjacent enemies are only attacked by bumping into them
+	RevealMap              bool              // No fog of war: every tile is visible
+	CodeRead               int               // Code characters walked over this run; reveals map at thresholds
+	RollbacksLeft          int               // Times a death can still be rolled back to the level's checkpoint
+	Checkpoint             *Checkpoint       // Progress at the start of the current level, for rollbacks
+	MessageTurns           int               // Minimum turns a message stays up before a queued one replaces it
+	MessageAge             int               // Turns the current message has been shown
+	MessageQueue           []queuedMessage   `json:"-"` // Messages waiting for the current one to finish
+	CombatStance           bool              // Directional keys only attack; the player holds position
+	Coop                   bool              // Pair programming mode: two players share the dungeon
+	Player2                *Entity           // The second player in pair programming mode, nil when solo
+	TechDebt               int               // Enemies left alive on levels the player has descended from
+	PotionHealPercent      int               // Potions heal this percentage of max HP instead of PotionHealAmount (0 = flat)
+	PotionTiers            bool              // Potions may be big or huge as well as small
+	Armor                  []*Entity         // Armor lying on the current level
+	LeashDistance          int               // Alerted enemies this far from home give up and go back (0 = never)
+	CodeSmellsEnabled      bool              // Some rooms may be filled with vision-cutting code smell haze
+	CodeSmells             map[*Room][]*Entity `json:"-"` // Hazed rooms on the current level and the enemies they started with
+	LevelStats             LevelStats        // What has happened on the current level so far
+	ShowLevelSummary       bool              // Show the finished level's stats on the way down
+	LevelSummary           *LevelStats       // Stats of the level just finished, shown until dismissed
+	SummaryLevel           int               // Which level LevelSummary is for
+	ASCII                  bool              // Draw with plain ASCII instead of box-drawing and other Unicode glyphs
+	MergeCooldown          int               // Turns until merge-affected tiles heal back to floor (0 = never)
+	MergeCooldownLeft      map[[2]int]int    `json:"-"` // Turns left before each merge-affected tile heals
+	MergeChaseLeft         map[[2]int]int    `json:"-"` // Turns left before each tile chasing fire spread to goes out
+	Inventory              []*Entity         // Items the player is carrying
+	CITraps                bool              // Some levels hide a CI trap
+	BlindTurns             int               // Turns left with vision cut by a CI trap
+	ManualPickup           bool              // Potions are only picked up with PickUp, never by walking over them
+	Turns                  int               // Turns taken this run
+	DamageTaken            int               // HP lost this run
+	AStarChase             bool              // Enemies chasing a player they can see follow an A* path instead of stepping straight at them
+	XP                     int               // Experience from kills; every XPPerLevel levels the player up
+	Throws                 int               // Rubber ducks left to throw on this level
+	ThrowPath              []pathStep        `json:"-"` // Tiles the last thrown duck flew over, for the animation
+	Difficulty             Difficulty        // Scales enemies, potions and the player's starting HP
+	MergeConflicts         []MergeConflictLocation // Conflicted files found in merge mode; file i is the marker on level i+1
+	Gold                   int               // Gold picked up this run
+	GoldPiles              []*Entity         // Gold dropped by enemies on the current level
+	ShopOpen               bool              // The shop is open, so keys buy upgrades instead of moving
+	CornerCutting          bool              // Diagonal steps may squeeze between two wall corners
+	VisionRadius           int               // How far the player sees, before torches, code smells and CI traps
+	Torches                []*Entity         // Torches lying on the current level
+	TorchTurnsLeft         int               // Turns left on a lit torch's vision bonus
+	Weapons                []*Entity         // Weapons lying on the current level
+	EquippedWeapon         *Weapon           // The weapon the player attacks with (nil = bare hands)
+	CritChance             float64           // Chance a player's melee attack is a critical hit for double damage
+	MemoryDecay            int               // Turns an explored tile can go unseen before it's forgotten (0 = never)
+	LastSeen               [][]int           // Turn each tile was last in view, for MemoryDecay
 }
 
-// SetMessage sets a message with default (green) style
-func (gs *GameState) SetMessage(msg string) {
-	gs.Message = msg
-	gs.MessageStyle = tcell.Style{} // Clear custom style, use default
-}
+// DefaultMaxLevel is how many levels deep a normal run goes
+const DefaultMaxLevel = 5
+
+// Smallest dungeon generated, however small the terminal
+const (
+	MinDungeonWidth  = 40
+	MinDungeonHeight = 20
+)
 
-func NewGameState(codeFiles []CodeFile, seed int64, termWidth, termHeight int) *GameState {
-	rng := rand.New(rand.NewSource(seed))
+// StateOption configures a GameState before its first level is generated
+type StateOption func(*GameState)
 
+func NewGameState(codeFiles []CodeFile, seed int64, termWidth, termHeight int, opts ...StateOption) *GameState {
 	gs := &GameState{
 		Level:              1,
-		MaxLevel:           5,
+		MaxLevel:           DefaultMaxLevel,
+		Seed:               seed,
+		BugMergeChance:     DefaultBugMergeChance,
+		MessageTurns:       DefaultMessageTurns,
 		CodeFiles:          codeFiles,
-		RNG:                rng,
 		TermWidth:          termWidth,
 		TermHeight:         termHeight,
 		KonamiSequence:     make([]string, 0),
@@ -71,83 +163,227 @@ func NewGameState(codeFiles []CodeFile, seed int64, termWidth, termHeight int) *
 		Username:           getUsername(),
 		MergeMarkerX:       -1,
 		MergeMarkerY:       -1,
-		MergeAffectedTiles: make(map[int]bool),
+		MergeAffectedTiles: make(map[[2]int]bool),
+		MergeFocus:         -1,
+		VisionRadius:       DefaultVisionRadius,
+		CritChance:         DefaultCritChance,
 	}
 
+	for _, opt := range opts {
+		opt(gs)
+	}
+
+	// Options may pick the RNG algorithm, so seed it only once they've run
+	gs.RNG = newRNG(seed, gs.RNGAlgorithm)
 	gs.generateLevel()
 	return gs
 }
 
 func (gs *GameState) generateLevel() {
+	// Every level comes with a fresh supply of rubber ducks to throw
+	gs.Throws = ThrowsPerLevel
+
+	if gs.Tutorial {
+		gs.MaxLevel = len(tutorialLessons)
+		gs.loadTutorialLevel()
+		return
+	}
+
+	// Reseed per level so a level code always rebuilds the same layout
+	gs.RNG = newRNG(levelSeed(gs.Seed, gs.Level), gs.RNGAlgorithm)
+
 	// Reserve 3 lines for UI at bottom (status bar, message, buffer)
 	width := gs.TermWidth
 	height := gs.TermHeight - 3
-	if width < 40 {
-		width = 40
+	if gs.LevelWidth > 0 && gs.LevelHeight > 0 {
+		width, height = gs.LevelWidth, gs.LevelHeight
+	}
+	if width < MinDungeonWidth {
+		width = MinDungeonWidth
 	}
-	if height < 20 {
-		height = 20
+	if height < MinDungeonHeight {
+		height = MinDungeonHeight
 	}
 
 	// Pick a code file for this level
 	var codeFile *CodeFile
-	if len(gs.CodeFiles) > 0 {
+	gs.LevelFileName = ""
+	if gs.CodeLibrary != nil {
+		codeFile = gs.CodeLibrary.levelFile(gs.Level)
+	} else if len(gs.CodeFiles) > 0 {
 		codeFile = &gs.CodeFiles[(gs.Level-1)%len(gs.CodeFiles)]
 	}
+	if codeFile != nil {
+		gs.LevelFileName = filepath.Base(codeFile.Path)
+	}
 
-	gs.Dungeon = GenerateDungeon(width, height, gs.RNG, codeFile)
+	gs.Dungeon = GenerateDungeonWithRooms(width, height, gs.RNG, codeFile)
+	gs.Dungeon.ErodeRooms(gs.RNG, gs.RoomErosion)
 
 	// Initialize visibility arrays
 	gs.Visible = make([][]bool, height)
 	gs.Explored = make([][]bool, height)
+	gs.LastSeen = make([][]int, height)
 	for y := 0; y < height; y++ {
 		gs.Visible[y] = make([]bool, width)
 		gs.Explored[y] = make([]bool, width)
+		gs.LastSeen[y] = make([]int, width)
 	}
+	gs.loadExplored()
 
-	// Place player in first room
-	if len(gs.Dungeon.Rooms) > 0 {
-		room := gs.Dungeon.Rooms[0]
+	// Place player in the room farthest from the door
+	if room := gs.Dungeon.StartRoom(); room != nil {
 		px, py := room.Center()
 		if gs.Player == nil {
-			gs.Player = NewPlayer(px, py)
+			gs.Player = gs.newPlayer(px, py)
 		} else {
 			gs.Player.X, gs.Player.Y = px, py
 		}
 	}
+	if gs.Coop {
+		gs.placePlayer2()
+	}
 
 	// Place door
 	gs.DoorX, gs.DoorY = gs.Dungeon.PlaceDoor(gs.RNG)
 
+	// Link two rooms with a pull request portal pair
+	gs.Dungeon.PlacePortals(gs.RNG)
+
 	
 	// Place merge conflict trap (one per level) - place before enemies/potions
 	gs.MergeConflictX, gs.MergeConflictY = gs.randomFloorTile()
 	gs.OnMergeConflict = false
+
+	// Scatter lint warnings (minor hazards)
+	numLint := 2 + gs.RNG.Intn(3)
+	for i := 0; i < numLint; i++ {
+		x, y := gs.randomFloorTile()
+		if gs.Dungeon.Tiles[y][x] == TileFloor {
+			gs.Dungeon.Tiles[y][x] = TileLint
+		}
+	}
+	gs.placeCITrap()
 	
 	// Spawn enemies
 	gs.Enemies = nil
-	numEnemies := 3 + gs.Level*2
+	numEnemies := gs.Difficulty.scaleEnemies(3 + gs.Level*2)
 	for i := 0; i < numEnemies; i++ {
 		x, y := gs.randomFloorTile()
-		if gs.RNG.Float32() > 0.4 {
-			gs.Enemies = append(gs.Enemies, NewBug(x, y))
+		roll := gs.RNG.Float32()
+		var enemy *Entity
+		// Browser tests and rebases both take their share from the bugs
+		if roll > 0.4+BrowserTestChance+RebaseChance {
+			enemy = NewBug(x, y)
+		} else if roll > 0.4+RebaseChance {
+			enemy = NewBrowserTest(x, y)
+		} else if roll > 0.4 {
+			enemy = NewRebase(x, y)
+		} else if roll > 0.1 {
+			enemy = NewScopeCreep(x, y)
 		} else {
-			gs.Enemies = append(gs.Enemies, NewScopeCreep(x, y))
+			enemy = NewFlakyTest(x, y)
 		}
+		gs.Difficulty.toughen(enemy)
+		enemy.SightRange = gs.rollSightRange()
+		gs.Enemies = append(gs.Enemies, enemy)
 	}
 
+	// The final level is guarded by a boss
+	if gs.Level == gs.MaxLevel {
+		x, y := gs.randomFloorTile()
+		gs.Enemies = append(gs.Enemies, NewMonolith(x, y))
+	}
+	gs.spawnDebtEnemies()
+	gs.StackTrace = nil
+
 	// Spawn potions (scales with level)
+	var carried []*Entity
+	if gs.PotionBudget > 0 {
+		// Survival mode: potions left on the floor come along to the next level
+		carried = gs.Potions
+	}
+	spawnedBefore := gs.PotionsSpawned
 	gs.Potions = nil
-	numPotions := 2 + gs.Level + gs.RNG.Intn(2)
+	numPotions := gs.Difficulty.scalePotions(2 + gs.Level + gs.RNG.Intn(2))
+	if gs.PotionBudget > 0 {
+		// Survival mode: spread what's left of the run's budget over the remaining levels
+		remaining := gs.PotionBudget - gs.PotionsSpawned
+		levelsLeft := max(gs.MaxLevel-gs.Level+1, 1)
+		numPotions = max((remaining+levelsLeft-1)/levelsLeft, 0)
+	}
+	gs.PotionsSpawned += numPotions
 	for i := 0; i < numPotions; i++ {
 		x, y := gs.randomFloorTile()
-		gs.Potions = append(gs.Potions, NewPotion(x, y))
+		potion := NewPotion(x, y)
+		if gs.PotionTiers {
+			potion.Tier = gs.rollPotionTier()
+		}
+		gs.Potions = append(gs.Potions, potion)
 	}
 
-	
-	// Set merge conflict marker position (center of most central room)
-	gs.MergeMarkerX, gs.MergeMarkerY = findCentralRoomCenter(gs.Dungeon)
-	gs.MergeAffectedTiles = make(map[int]bool)
+	// Occasionally hide a revert on the level
+	gs.Reverts = nil
+	if gs.RNG.Float64() < RevertChance {
+		x, y := gs.randomFloorTile()
+		gs.Reverts = append(gs.Reverts, NewRevert(x, y))
+	}
+
+	// Very occasionally hide a hotfix too
+	gs.Hotfixes = nil
+	if gs.RNG.Float64() < HotfixChance {
+		x, y := gs.randomFloorTile()
+		gs.Hotfixes = append(gs.Hotfixes, NewHotfix(x, y))
+	}
+
+	// Gold only comes from enemies killed on this level
+	gs.GoldPiles = nil
+
+	// And sometimes a piece of armor
+	gs.Armor = nil
+	if gs.RNG.Float64() < ArmorChance {
+		x, y := gs.randomFloorTile()
+		gs.Armor = append(gs.Armor, NewArmor(x, y, armorDefense(gs.Level)))
+	}
+
+	// Or a torch to see further by
+	gs.Torches = nil
+	if gs.RNG.Float64() < TorchChance {
+		x, y := gs.randomFloorTile()
+		gs.Torches = append(gs.Torches, NewTorch(x, y))
+	}
+
+	// And now and then a weapon
+	gs.Weapons = nil
+	if gs.RNG.Float64() < WeaponChance {
+		x, y := gs.randomFloorTile()
+		gs.Weapons = append(gs.Weapons, NewWeapon(x, y, weaponForLevel(gs.Level)))
+	}
+
+	gs.spawnCodeSmells()
+
+	// Set merge conflict marker position (center of most central room, or
+	// the floor nearest it when erosion or a cave left the center solid)
+	centerX, centerY := findCentralRoomCenter(gs.Dungeon)
+	gs.MergeMarkerX, gs.MergeMarkerY = findNearestFloorTile(gs.Dungeon, centerX, centerY)
+	// With real conflicts found, each conflicted file gets the marker on its own level
+	gs.MergeConflict = gs.levelMergeConflict()
+	if len(gs.MergeConflicts) > 0 && gs.MergeConflict == nil {
+		gs.MergeMarkerX, gs.MergeMarkerY = -1, -1
+	}
+	gs.MergeAffectedTiles = make(map[[2]int]bool)
+	gs.MergeCooldownLeft = nil
+	gs.MergeChaseLeft = nil
+	gs.MergeQueueWave = nil
+	gs.MergeFocus = -1
+	// Carried potions are placed last so they don't change the level's layout
+	for _, potion := range carried {
+		potion.X, potion.Y = gs.randomFloorTile()
+		gs.Potions = append(gs.Potions, potion)
+	}
+	gs.ensureReachable()
+	gs.PristineTiles = gs.Dungeon.CloneTiles()
+	gs.saveCheckpoint(carried, spawnedBefore)
 	
 	gs.updateVisibility()
 	gs.SetMessage("")
@@ -162,26 +398,41 @@ func (gs *GameState) randomFloorTile() (int, int) {
 		x := room.X + gs.RNG.Intn(room.W)
 		y := room.Y + gs.RNG.Intn(room.H)
 
-		if gs.Dungeon.IsWalkable(x, y) {
-			// Check not on player or door
-			if gs.Player != nil && x == gs.Player.X && y == gs.Player.Y {
-				continue
-			}
-			if x == gs.DoorX && y == gs.DoorY {
-				continue
-			}
-			// Check not on merge conflict trap (if already placed)
-			if x == gs.MergeConflictX && y == gs.MergeConflictY {
-				continue
-			}
+		if gs.canPlaceAt(x, y) {
 			return x, y
 		}
 	}
-	return gs.Dungeon.Width / 2, gs.Dungeon.Height / 2
+	return findNearestFloorTile(gs.Dungeon, gs.Dungeon.Width/2, gs.Dungeon.Height/2)
+}
+
+// canPlaceAt reports whether a new entity may be spawned on (x, y)
+func (gs *GameState) canPlaceAt(x, y int) bool {
+	if !gs.Dungeon.IsWalkable(x, y) {
+		return false
+	}
+	// Check not on player or door
+	if gs.Player != nil && x == gs.Player.X && y == gs.Player.Y {
+		return false
+	}
+	if gs.Player2 != nil && x == gs.Player2.X && y == gs.Player2.Y {
+		return false
+	}
+	if x == gs.DoorX && y == gs.DoorY {
+		return false
+	}
+	// Check not on a portal
+	if gs.Dungeon.Tiles[y][x] == TilePortal {
+		return false
+	}
+	// Check not on merge conflict trap (if already placed)
+	if x == gs.MergeConflictX && y == gs.MergeConflictY {
+		return false
+	}
+	return true
 }
 
 func (gs *GameState) MovePlayer(dx, dy int) {
-	if gs.GameOver || gs.Victory {
+	if gs.GameOver || gs.Victory || !gs.Player.IsAlive() {
 		return
 	}
 
@@ -193,37 +444,57 @@ func (gs *GameState) MovePlayer(dx, dy int) {
 		return
 	}
 
+	// Don't slip diagonally between two wall corners
+	if gs.cornerBlocked(gs.Player.X, gs.Player.Y, dx, dy) {
+		return
+	}
+
+	// Players can't walk through each other, except to join their pair at the door
+	if gs.meetAtDoor(newX, newY) {
+		return
+	}
+
 	// Check for enemy at target position - bump to attack!
 	for _, enemy := range gs.Enemies {
 		if enemy.IsAlive() && enemy.X == newX && enemy.Y == newY {
-			// Attack the enemy we bumped into
-			enemy.TakeDamage(gs.Player.Damage)
-			if !enemy.IsAlive() {
-				gs.EnemiesKilled++
-				if enemy.Type == EntityBug {
-					gs.SetMessage("You squashed a bug!")
-				} else {
-					gs.SetMessage("You eliminated a scope creep!")
-				}
-			} else {
-				gs.SetMessage("You attack!")
-			}
-			// Enemy turn after player attacks
-			gs.moveEnemies()
-			gs.enemyAttacks()
-			gs.updateVisibility()
-			if !gs.Player.IsAlive() {
-				gs.GameOver = true
-				gs.SetMessage("You died!")
-			}
+			// Attack the enemy we bumped into, chaining through any lined up behind it
+			gs.squashChain(enemy, dx, dy)
+			gs.countTurn()
+			// The bump was the player's action, so skip auto-attack
+			gs.endTurn()
 			return
 		}
 	}
 
+	// In combat stance the player only attacks; stepping onto empty floor does nothing
+	if gs.CombatStance {
+		return
+	}
+
 	gs.Player.X = newX
 	gs.Player.Y = newY
 	gs.MoveCount++
+	gs.countTurn()
+
+	// Step through a pull request portal to its paired tile (player only)
+	if exitX, exitY, ok := gs.Dungeon.PortalExit(newX, newY); ok {
+		if gs.enemyAt(exitX, exitY) == nil && gs.livingPlayerAt(exitX, exitY) == nil {
+			gs.Player.X, gs.Player.Y = exitX, exitY
+			newX, newY = exitX, exitY
+			gs.SetMessage("You step through the pull request portal!")
+		} else {
+			gs.SetMessage("Something is blocking the other end of the portal.")
+		}
+	}
 
+	// Taking the door ends the move. Nothing else on this level happens on
+	// the descent step (no pickups, hazards, enemy turn or merge damage), so
+	// the player arrives with exactly the HP they left with. In pair
+	// programming mode the first to arrive waits for the other.
+	if newX == gs.DoorX && newY == gs.DoorY && !gs.waitAtDoor() {
+		gs.takeDoor()
+		return
+	}
 	
 	// Cycle merge conflict animation if active
 	if len(gs.MergeAffectedTiles) > 0 {
@@ -231,34 +502,78 @@ func (gs *GameState) MovePlayer(dx, dy int) {
 	}
 	
 	// Check for potion pickup
+	itemsBefore := gs.itemCount()
 	for i, potion := range gs.Potions {
-		if potion.X == newX && potion.Y == newY {
-			gs.Player.Heal(3)
-			gs.Potions = append(gs.Potions[:i], gs.Potions[i+1:]...)
-			gs.SetMessage("You drink a health potion! (+3 HP)")
+		if potion.X == newX && potion.Y == newY && !gs.ManualPickup {
+			if gs.pickUpPotion(potion) {
+				gs.Potions = append(gs.Potions[:i], gs.Potions[i+1:]...)
+			}
 			break
 		}
 	}
 
-	
+	// Check for revert pickup
+	for i, revert := range gs.Reverts {
+		if revert.X == newX && revert.Y == newY {
+			gs.Reverts = append(gs.Reverts[:i], gs.Reverts[i+1:]...)
+			gs.revertLevel()
+			break
+		}
+	}
+
+	// Check for hotfix pickup; it's kept for an emergency rather than used right away
+	for i, hotfix := range gs.Hotfixes {
+		if hotfix.X == newX && hotfix.Y == newY {
+			gs.Hotfixes = append(gs.Hotfixes[:i], gs.Hotfixes[i+1:]...)
+			gs.HotfixesHeld++
+			gs.SetMessage("You found a hotfix! Press H to deploy it in an emergency.")
+			break
+		}
+	}
+
+	gs.pickUpArmor(newX, newY)
+	gs.pickUpGold(newX, newY)
+	gs.pickUpTorch(newX, newY)
+	gs.pickUpWeapon(newX, newY)
+
+	// Finding an item in a hazed room clears the smell
+	if room := gs.smellyRoomAt(newX, newY); room != nil && gs.itemCount() < itemsBefore {
+		gs.clearCodeSmell(room)
+	}
+
+	// Reading the code under foot reveals a little more of the map
+	gs.readCode(newX, newY)
+
+	// Check for lint warnings
+	if gs.Dungeon.Tiles[newY][newX] == TileLint {
+		gs.stepOnLint()
+	}
+
+	// Check for the CI trap
+	if gs.Dungeon.Tiles[newY][newX] == TileCI {
+		gs.triggerCI(newX, newY)
+	}
+
 	// Check for merge conflict marker
 	if newX == gs.MergeMarkerX && newY == gs.MergeMarkerY {
 		gs.triggerMergeConflict()
 	}
 	
-	// Check for door
-	if newX == gs.DoorX && newY == gs.DoorY {
-		if gs.Level >= gs.MaxLevel {
-			gs.Victory = true
-			gs.SetMessage("You've escaped the dungeon! Victory!")
-		} else {
-			gs.Level++
-			gs.generateLevel()
-			gs.SetMessage("You descend deeper into the dungeon...")
-		}
+	gs.processTurn()
+}
+
+// Wait passes a turn without moving. Everything else carries on as if the
+// player had moved: enemies close in and attack, merge conflict fire burns
+// and time-based effects tick down.
+func (gs *GameState) Wait() {
+	if gs.GameOver || gs.Victory || !gs.Player.IsAlive() {
 		return
 	}
-
+	gs.MoveCount++
+	gs.countTurn()
+	if len(gs.MergeAffectedTiles) > 0 {
+		gs.MergeAnimationStep++
+	}
 	gs.processTurn()
 }
 
@@ -280,15 +595,71 @@ func (gs *GameState) distanceToMergeConflict() int {
 
 // isPlayerInMergeConflictArea checks if the player is within the merge conflict's visual area
 func (gs *GameState) isPlayerInMergeConflictArea() bool {
+	return gs.isInMergeConflictArea(gs.Player.X, gs.Player.Y)
+}
+
+// potionHeal is how much HP a potion restores the player: a flat amount, or
+// with PotionHealPercent a share of max HP (at least 1) that keeps up as max HP grows
+func (gs *GameState) potionHeal() int {
+	if gs.PotionHealPercent <= 0 {
+		return PotionHealAmount
+	}
+	return max(gs.Player.MaxHP*gs.PotionHealPercent/100, 1)
+}
+
+// takeDoor leaves the current level: on to the next one, victory after the
+// last, or deeper still in endless mode. Enemies left alive on the way down
+// become technical debt.
+func (gs *GameState) takeDoor() {
+	// Tutorial lessons are taken in order, each finished before the next
+	if gs.Tutorial && !gs.TutorialLessonDone {
+		gs.SetMessage("Finish this lesson before moving on to the next.")
+		return
+	}
+	gs.SaveExplored()
+	debt := 0
+	if gs.Level < gs.MaxLevel || gs.Endless {
+		debt = gs.accrueDebt()
+	}
+
+	if gs.Level >= gs.MaxLevel && gs.Endless {
+		// Endless mode: keep descending with escalating difficulty
+		gs.finishLevelStats()
+		gs.Level++
+		gs.EndlessDepth++
+		gs.generateLevel()
+		gs.SetMessage(fmt.Sprintf("Endless depth %d. It never ends... (score: %d)", gs.EndlessDepth, gs.EndlessScore()))
+	} else if gs.Level >= gs.MaxLevel {
+		gs.Victory = true
+		gs.SetMessage("You've escaped the dungeon! Victory!")
+	} else {
+		gs.finishLevelStats()
+		gs.Level++
+		gs.generateLevel()
+		if !gs.announceLevelFile("Descending into %s...") {
+			gs.SetMessage("You descend deeper into the dungeon...")
+		}
+	}
+	if debt > 0 {
+		gs.SetMessage(gs.debtMessage(debt))
+	}
+	// A shop waits at the top of every even level
+	if !gs.Victory && !gs.Tutorial && gs.Level%2 == 0 {
+		gs.OpenShop()
+	}
+}
+
+// isInMergeConflictArea checks if a tile is within the merge conflict's visual area
+func (gs *GameState) isInMergeConflictArea(x, y int) bool {
 	// Check core 5x3 area
-	dx := gs.Player.X - gs.MergeConflictX
-	dy := gs.Player.Y - gs.MergeConflictY
+	dx := x - gs.MergeConflictX
+	dy := y - gs.MergeConflictY
 	if dx >= -2 && dx <= 2 && dy >= -1 && dy <= 1 {
 		return true
 	}
 	// Check spread tiles
 	for _, tile := range gs.MergeConflictSpread {
-		if gs.Player.X == tile[0] && gs.Player.Y == tile[1] {
+		if x == tile[0] && y == tile[1] {
 			return true
 		}
 	}
@@ -310,21 +681,19 @@ func (gs *GameState) checkMergeConflict() {
 		// Rotate colors on each movement
 		gs.ColorRotation++
 		// Deal 1 damage per turn while on the trap center
-		if !gs.Invulnerable {
-			gs.Player.TakeDamage(1)
-			// Format merge conflict damage as "- X HP damage" in red
-			gs.Message = "- 1 HP damage"
-			gs.MessageStyle = tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true)
-			if !gs.Player.IsAlive() {
-				gs.KilledBy = "merge_conflict"
-			}
-		} else {
-			gs.SetMessage("The merge conflict burns around you, but your invulnerability protects you!")
-		}
+		gs.burnPlayer()
 	} else if gs.MergeConflictTriggered {
 		// Player moved off the center - keep animating fire even outside the area
 		gs.ColorRotation++
-		if gs.OnMergeConflict && !gs.isPlayerInMergeConflictArea() {
+		if gs.MergeFireChase {
+			gs.growMergeFireTowardPlayer()
+		}
+		inFire := gs.isPlayerInMergeConflictArea()
+		if gs.MergeFireDamage && inFire {
+			// The whole fire is dangerous, not just the trap center
+			gs.burnPlayer()
+		}
+		if gs.OnMergeConflict && !inFire {
 			// Player fully escaped the merge conflict area
 			gs.OnMergeConflict = false
 		}
@@ -333,11 +702,46 @@ func (gs *GameState) checkMergeConflict() {
 	}
 }
 
+// stepOnLint applies a lint warning: 1 non-lethal damage and a lost action
+func (gs *GameState) stepOnLint() {
+	if !gs.Invulnerable && gs.Player.HP > 1 {
+		gs.hurtPlayer(gs.Player, 1)
+	}
+	gs.Slowed = true
+	gs.SetAlert("Lint warning! You stop to fix it (-1 HP, slowed)", tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack).Bold(true))
+}
+
+// burnPlayer deals one turn of merge conflict fire damage to the player
+func (gs *GameState) burnPlayer() {
+	if gs.Invulnerable {
+		gs.SetMessage("The merge conflict burns around you, but your invulnerability protects you!")
+		return
+	}
+	dmg := mitigate(gs.Player, 1)
+	gs.hurtPlayer(gs.Player, dmg)
+	// Format merge conflict damage as "- X HP damage" in red
+	gs.SetAlert(fmt.Sprintf("- %d HP damage", dmg), tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true))
+	if !gs.Player.IsAlive() {
+		gs.KilledBy = "merge_conflict"
+	}
+}
+
 func (gs *GameState) processTurn() {
 	// Auto-attack adjacent enemies
-	gs.playerAutoAttack()
+	if !gs.NoAutoAttack {
+		gs.playerAutoAttack()
+	}
+	gs.endTurn()
+}
+
+// endTurn plays out the rest of a turn once the player has acted: hazards,
+// the enemies' turn and everything that counts down turn by turn. Attacking
+// ends the turn here too, so a fight doesn't stall torches, blindness,
+// regeneration or cooling merge fire.
+func (gs *GameState) endTurn() {
+	// Messages age once the whole turn has played out
+	defer gs.tickMessages()
 
-	
 	// Check merge conflict proximity and damage
 	gs.checkMergeConflict()
 	
@@ -347,8 +751,32 @@ func (gs *GameState) processTurn() {
 	// Enemies attack player
 	gs.enemyAttacks()
 
+	// A lint warning costs the player an extra action
+	if gs.Slowed {
+		gs.Slowed = false
+		gs.moveEnemies()
+		gs.enemyAttacks()
+	}
+
+	// Stack frames left by a fleeing boss burn and then fade
+	gs.updateStackTrace()
+
+	// Merge-affected tiles heal and chasing fire dies down once the player
+	// leaves the marker and the trap alone
+	gs.coolMergeFire()
+
+	// Resting away from enemies and fire slowly heals the player
+	gs.regenerate()
+
+	// Rooms whose enemies are all dead no longer smell
+	gs.clearDefeatedCodeSmells()
+
 	// Update visibility
 	gs.updateVisibility()
+	if gs.BlindTurns > 0 {
+		gs.BlindTurns--
+	}
+	gs.burnTorch()
 
 	
 	// Increment merge conflict movement counter if on trap (at end of turn)
@@ -357,11 +785,13 @@ func (gs *GameState) processTurn() {
 	}
 	
 	// Check player death
-	if !gs.Player.IsAlive() {
+	if gs.playersDown() {
 		gs.GameOver = true
-		gs.SetMessage("You died!")
+		gs.SetAlert("You died!", tcell.Style{})
 		return
 	}
+
+	gs.updateTutorial()
 	
 	// Show warning message if player is near merge conflict and no other message
 	distance := gs.distanceToMergeConflict()
@@ -371,19 +801,92 @@ func (gs *GameState) processTurn() {
 }
 
 func (gs *GameState) playerAutoAttack() {
+	levels := 0
 	for _, enemy := range gs.Enemies {
 		if enemy.IsAlive() && gs.Player.IsAdjacent(enemy) {
-			enemy.TakeDamage(gs.Player.Damage)
+			dmg, crit := gs.meleeDamage()
+			enemy.TakeDamage(dmg)
 			if !enemy.IsAlive() {
-				gs.EnemiesKilled++
-				if enemy.Type == EntityBug {
-					gs.SetMessage("You squashed a bug!")
-				} else {
-					gs.SetMessage("You eliminated a scope creep!")
-				}
+				msg, gained := gs.killEnemy(enemy)
+				levels += gained
+				gs.setAttackMessage(msg, crit)
+			} else if crit {
+				gs.SetAlert(CritMessage, critStyle)
 			}
 		}
 	}
+	gs.announceLevelUp(levels)
+}
+
+// killEnemy credits the player with an enemy they just killed: the kill is
+// counted, its XP awarded and its loot dropped. Returns the kill message,
+// noting what dropped, and how many levels the player gained.
+func (gs *GameState) killEnemy(enemy *Entity) (string, int) {
+	gs.creditKills(1)
+	levels := gs.gainXP(enemyXP(enemy))
+	return killMessage(enemy) + gs.dropLoot(enemy), levels
+}
+
+// killMessage returns the message shown when the player kills an enemy
+func killMessage(enemy *Entity) string {
+	switch enemy.Type {
+	case EntityBug:
+		return "You squashed a bug!"
+	case EntityFlakyTest:
+		return "You fixed a flaky test!"
+	case EntityBrowserTest:
+		return "You fixed a browser test!"
+	case EntityRebase:
+		return "You aborted a rebase!"
+	case EntityConflictingCommit:
+		return "You resolved a conflicting commit!"
+	case EntityMonolith:
+		return "You broke up the legacy monolith!"
+	case EntityMergedBug:
+		return "You untangled a merged bug!"
+	default:
+		return "You eliminated a scope creep!"
+	}
+}
+
+// squashChain bump-attacks target. Each kill lets the player advance into the
+// vacated tile and attack the next enemy in line, up to MaxSquashChain attacks.
+func (gs *GameState) squashChain(target *Entity, dx, dy int) {
+	chain := 1
+	levels := 0
+	var msg string
+	crits := false
+	for {
+		dmg, crit := gs.meleeDamage()
+		crits = crits || crit
+		target.TakeDamage(dmg)
+		if !target.IsAlive() {
+			var gained int
+			msg, gained = gs.killEnemy(target)
+			levels += gained
+		} else {
+			msg = "You attack!"
+			break
+		}
+
+		next := gs.enemyAt(target.X+dx, target.Y+dy)
+		// Charging down the line would leave the stance's chokepoint
+		if next == nil || chain >= MaxSquashChain || gs.CombatStance {
+			break
+		}
+		if gs.Dungeon.Tiles[target.Y][target.X] != TileFloor || gs.cornerBlocked(target.X, target.Y, dx, dy) {
+			break
+		}
+		gs.Player.X, gs.Player.Y = target.X, target.Y
+		target = next
+		chain++
+	}
+
+	if chain > 1 {
+		msg = fmt.Sprintf("Squashed %d commits! %s", chain, msg)
+	}
+	gs.setAttackMessage(msg, crits)
+	gs.announceLevelUp(levels)
 }
 
 func (gs *GameState) moveEnemies() {
@@ -392,34 +895,221 @@ func (gs *GameState) moveEnemies() {
 			continue
 		}
 
-		// Only move if player is visible (in line of sight)
-		if !gs.hasLineOfSight(enemy.X, enemy.Y, gs.Player.X, gs.Player.Y) {
+		// A badly hurt boss runs away, dropping stack frames behind it
+		if gs.updateBossFlight(enemy) {
+			continue
+		}
+
+		// An enemy that strayed too far from home heads back
+		if gs.leashEnemy(enemy) {
+			continue
+		}
+
+		// Only chase if player is visible (in line of sight), unless
+		// persistent enemies are enabled and this one has been alerted
+		target := gs.chaseTarget(enemy)
+		if target == nil {
+			if gs.PersistentEnemies && enemy.Alerted {
+				gs.stepTowardPlayer(enemy, gs.nearestPlayer(enemy))
+			}
+			continue
+		}
+		if !enemy.Alerted {
+			enemy.HomeX, enemy.HomeY = enemy.X, enemy.Y
+		}
+		enemy.Alerted = true
+
+		// Rebases don't walk; they jump to the player every few turns
+		if enemy.Type == EntityRebase {
+			gs.teleportRebase(enemy, target)
+			continue
+		}
+
+		// Flaky tests sometimes wander off instead of chasing
+		if enemy.Type == EntityFlakyTest && gs.RNG.Float32() < FlakyTestFlakiness {
+			gs.moveEnemyRandomly(enemy)
+			continue
+		}
+
+		// Browser tests hang back and shoot rather than closing in
+		if enemy.Type == EntityBrowserTest && gs.kiteBrowserTest(enemy, target) {
+			continue
+		}
+
+		// Route around walls rather than walking into them
+		if gs.AStarChase {
+			gs.stepTowardPlayer(enemy, target)
 			continue
 		}
 
 		// Simple chase AI - move toward player
 		dx, dy := 0, 0
-		if enemy.X < gs.Player.X {
+		if enemy.X < target.X {
 			dx = 1
-		} else if enemy.X > gs.Player.X {
+		} else if enemy.X > target.X {
 			dx = -1
 		}
-		if enemy.Y < gs.Player.Y {
+		if enemy.Y < target.Y {
 			dy = 1
-		} else if enemy.Y > gs.Player.Y {
+		} else if enemy.Y > target.Y {
 			dy = -1
 		}
 
 		// Try to move (prefer diagonal, then cardinal)
 		newX, newY := enemy.X+dx, enemy.Y+dy
-		if gs.canEnemyMoveTo(newX, newY, enemy) {
+		diagonal := dx != 0 && dy != 0
+		canDiagonal := !gs.NoDiagonals && !gs.cornerBlocked(enemy.X, enemy.Y, dx, dy)
+		if (!diagonal || canDiagonal) && gs.canEnemyMoveTo(newX, newY, enemy) {
 			enemy.X, enemy.Y = newX, newY
 		} else if dx != 0 && gs.canEnemyMoveTo(enemy.X+dx, enemy.Y, enemy) {
 			enemy.X += dx
 		} else if dy != 0 && gs.canEnemyMoveTo(enemy.X, enemy.Y+dy, enemy) {
 			enemy.Y += dy
+		} else if gs.enemyAt(newX, newY) != nil || gs.enemyAt(enemy.X+dx, enemy.Y) != nil || gs.enemyAt(enemy.X, enemy.Y+dy) != nil {
+			// Stuck behind an ally, so look for another way around
+			gs.flankPlayer(enemy, target)
 		}
 	}
+
+	gs.mergeBugs()
+}
+
+// mergeBugs gives each pair of bugs that ended the turn next to each other a
+// BugMergeChance of combining into a single merged bug. The merged bug takes
+// the first bug's place and the second is removed.
+func (gs *GameState) mergeBugs() {
+	if gs.BugMergeChance <= 0 {
+		return
+	}
+
+	merged := make(map[*Entity]bool)
+	for i, a := range gs.Enemies {
+		if a.Type != EntityBug || !a.IsAlive() || merged[a] {
+			continue
+		}
+		for _, b := range gs.Enemies[i+1:] {
+			if b.Type != EntityBug || !b.IsAlive() || merged[b] || !a.IsAdjacent(b) {
+				continue
+			}
+			if gs.RNG.Float64() >= gs.BugMergeChance {
+				continue
+			}
+			gs.Enemies[i] = NewMergedBug(a.X, a.Y, a, b)
+			merged[a], merged[b] = true, true
+			if gs.Visible[a.Y][a.X] {
+				gs.SetMessage("Two bugs merged into something worse!")
+			}
+			break
+		}
+	}
+	if len(merged) == 0 {
+		return
+	}
+
+	enemies := gs.Enemies[:0]
+	for _, e := range gs.Enemies {
+		if !merged[e] {
+			enemies = append(enemies, e)
+		}
+	}
+	gs.Enemies = enemies
+}
+
+// flankPlayer moves an enemy one step along a route to the target player that avoids
+// other enemies, so chasers spread through parallel corridors instead of queueing
+func (gs *GameState) flankPlayer(enemy, target *Entity) {
+	direct := gs.pathTo(enemy.X, enemy.Y, target.X, target.Y)
+	if len(direct) == 0 {
+		return
+	}
+
+	path := gs.pathAround(enemy.X, enemy.Y, target.X, target.Y, func(x, y int) bool {
+		other := gs.enemyAt(x, y)
+		return other != nil && other != enemy
+	})
+	if len(path) == 0 || len(path) > len(direct)+MaxFlankDetour {
+		return
+	}
+	if next := path[0]; gs.canEnemyMoveTo(next.X, next.Y, enemy) {
+		enemy.X, enemy.Y = next.X, next.Y
+	}
+}
+
+// stepTowardPlayer moves an enemy one step along the shortest path to the target player
+func (gs *GameState) stepTowardPlayer(enemy, target *Entity) {
+	path := gs.pathTo(enemy.X, enemy.Y, target.X, target.Y)
+	if len(path) == 0 {
+		return
+	}
+	if next := path[0]; gs.canEnemyMoveTo(next.X, next.Y, enemy) {
+		enemy.X, enemy.Y = next.X, next.Y
+	} else if gs.enemyAt(next.X, next.Y) != nil {
+		gs.flankPlayer(enemy, target)
+	}
+}
+
+// moveEnemyRandomly steps an enemy in a random direction it can legally move in
+func (gs *GameState) moveEnemyRandomly(enemy *Entity) {
+	directions := [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+	var options [][2]int
+	for _, dir := range directions {
+		if dir[0] != 0 && dir[1] != 0 {
+			if gs.NoDiagonals || gs.cornerBlocked(enemy.X, enemy.Y, dir[0], dir[1]) {
+				continue
+			}
+		}
+		if gs.canEnemyMoveTo(enemy.X+dir[0], enemy.Y+dir[1], enemy) {
+			options = append(options, dir)
+		}
+	}
+	if len(options) == 0 {
+		return
+	}
+
+	dir := options[gs.RNG.Intn(len(options))]
+	enemy.X += dir[0]
+	enemy.Y += dir[1]
+}
+
+// rollSightRange picks how far a newly spawned enemy can see: a few are
+// nearly blind, some are nearsighted and the rest see any distance
+func (gs *GameState) rollSightRange() int {
+	roll := gs.RNG.Float64()
+	if roll < BlindChance {
+		return BlindSightRange
+	}
+	if roll < BlindChance+NearsightedChance {
+		return NearsightedRange
+	}
+	return 0
+}
+
+// HuntingEnemies returns the living enemies that can currently see the player
+func (gs *GameState) HuntingEnemies() map[*Entity]bool {
+	hunting := make(map[*Entity]bool)
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() && gs.enemyCanSee(enemy) {
+			hunting[enemy] = true
+		}
+	}
+	return hunting
+}
+
+// enemyAt returns the living enemy at (x, y), or nil if there is none
+func (gs *GameState) enemyAt(x, y int) *Entity {
+	for _, e := range gs.Enemies {
+		if e.IsAlive() && e.X == x && e.Y == y {
+			return e
+		}
+	}
+	return nil
+}
+
+// cornerBlocked reports whether a diagonal step from (x, y) is ruled out for
+// squeezing between two wall corners, which --corner-cutting allows
+func (gs *GameState) cornerBlocked(x, y, dx, dy int) bool {
+	return !gs.CornerCutting && gs.Dungeon.IsCornerCut(x, y, dx, dy)
 }
 
 func (gs *GameState) canEnemyMoveTo(x, y int, self *Entity) bool {
@@ -429,6 +1119,9 @@ func (gs *GameState) canEnemyMoveTo(x, y int, self *Entity) bool {
 	if x == gs.Player.X && y == gs.Player.Y {
 		return false
 	}
+	if gs.livingPlayerAt(x, y) != nil {
+		return false
+	}
 	for _, e := range gs.Enemies {
 		if e != self && e.IsAlive() && e.X == x && e.Y == y {
 			return false
@@ -444,49 +1137,114 @@ func (gs *GameState) enemyAttacks() {
 	}
 
 	for _, enemy := range gs.Enemies {
-		if enemy.IsAlive() && gs.Player.IsAdjacent(enemy) {
-			gs.Player.TakeDamage(enemy.Damage)
-			// Format damage message with monster type and damage in red
-			if enemy.Type == EntityBug {
-				gs.Message = fmt.Sprintf("A bug attacked - %d HP damage", enemy.Damage)
-				if !gs.Player.IsAlive() {
-					gs.KilledBy = "bug"
-				}
-			} else {
-				gs.Message = fmt.Sprintf("A scope creep attacked - %d HP damage", enemy.Damage)
-				if !gs.Player.IsAlive() {
-					gs.KilledBy = "scope_creep"
-				}
+		if !enemy.IsAlive() || enemy.FleeTurns > 0 || enemy.Returning {
+			continue
+		}
+		if enemy.Type == EntityBrowserTest {
+			gs.browserTestShoots(enemy)
+			continue
+		}
+		// Each enemy attacks one adjacent player a turn
+		for _, player := range gs.Players() {
+			if !player.IsAlive() || !player.IsAdjacent(enemy) {
+				continue
 			}
-			gs.MessageStyle = tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true)
+			gs.enemyHits(enemy, player, fmt.Sprintf("A %s attacked", enemy.Name()))
+			break
+		}
+	}
+}
+
+// enemyHits deals an enemy's damage to a player, reporting it as what happened
+// followed by the damage taken
+func (gs *GameState) enemyHits(enemy, player *Entity, what string) {
+	dmg := mitigate(player, enemy.Damage)
+	gs.hurtPlayer(player, dmg)
+	// Format damage message with monster type and damage in red
+	gs.SetAlert(fmt.Sprintf("%s - %d HP damage", what, dmg),
+		tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack).Bold(true))
+	if !player.IsAlive() {
+		gs.KilledBy = enemy.KillerID()
+		if !gs.playersDown() {
+			gs.announcePlayerDown(enemy)
 		}
 	}
 }
 
+// hasLineOfSight reports whether nothing but open floor lies between two tiles
 func (gs *GameState) hasLineOfSight(x1, y1, x2, y2 int) bool {
-	dx := x2 - x1
-	dy := y2 - y1
+	return gs.lineOfSight(x1, y1, x2, y2, nil)
+}
 
-	steps := abs(dx)
-	if abs(dy) > steps {
-		steps = abs(dy)
+// enemyCanSee reports whether an enemy can see a living player (or, solo, the player)
+func (gs *GameState) enemyCanSee(enemy *Entity) bool {
+	if gs.Player2 == nil {
+		return gs.enemyCanSeePlayer(enemy, gs.Player)
 	}
+	return gs.chaseTarget(enemy) != nil
+}
 
-	if steps == 0 {
-		return true
+// enemyCanSeePlayer reports whether an enemy can see player: within its sight
+// range and with line of sight. With CrowdBlocksSight, other living enemies on
+// the sight line block the view too.
+func (gs *GameState) enemyCanSeePlayer(enemy, player *Entity) bool {
+	if enemy.SightRange > 0 && enemy.DistanceTo(player) > enemy.SightRange {
+		return false
 	}
 
-	xInc := float64(dx) / float64(steps)
-	yInc := float64(dy) / float64(steps)
+	var blocked func(x, y int) bool
+	if gs.CrowdBlocksSight {
+		blocked = func(x, y int) bool {
+			other := gs.enemyAt(x, y)
+			return other != nil && other != enemy
+		}
+	}
+	return gs.lineOfSight(enemy.X, enemy.Y, player.X, player.Y, blocked)
+}
+
+// lineOfSight walks the Bresenham line from (x1, y1) to (x2, y2), failing on
+// walls and on any tile between the ends that the optional blocked predicate
+// rejects. A diagonal step squeezing between two walls is blocked too. The
+// line is always walked from the same end, so sight works the same both ways.
+func (gs *GameState) lineOfSight(x1, y1, x2, y2 int, blocked func(x, y int) bool) bool {
+	if x2 < x1 || (x2 == x1 && y2 < y1) {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
 
-	x := float64(x1)
-	y := float64(y1)
+	dx := abs(x2 - x1)
+	dy := -abs(y2 - y1)
+	sx, sy := 1, 1
+	if x2 < x1 {
+		sx = -1
+	}
+	if y2 < y1 {
+		sy = -1
+	}
 
-	for i := 0; i < steps; i++ {
-		x += xInc
-		y += yInc
-		ix, iy := int(x+0.5), int(y+0.5)
-		if !gs.Dungeon.IsWalkable(ix, iy) {
+	x, y := x1, y1
+	err := dx + dy
+	for x != x2 || y != y2 {
+		px, py := x, y
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+
+		if x != px && y != py && !gs.Dungeon.IsWalkable(x, py) && !gs.Dungeon.IsWalkable(px, y) {
+			return false
+		}
+		if x == x2 && y == y2 {
+			break
+		}
+		if !gs.Dungeon.IsWalkable(x, y) {
+			return false
+		}
+		if blocked != nil && blocked(x, y) {
 			return false
 		}
 	}
@@ -502,38 +1260,22 @@ func (gs *GameState) updateVisibility() {
 		}
 	}
 
-	// Cast rays for fog of war
-	px, py := gs.Player.X, gs.Player.Y
-	for angle := 0; angle < 360; angle += 2 {
-		gs.castRay(px, py, angle)
-	}
-}
-
-func (gs *GameState) castRay(startX, startY, angle int) {
-	// Convert angle to radians
-	rad := float64(angle) * 3.14159265 / 180.0
-	dx := cos(rad)
-	dy := sin(rad)
-
-	x := float64(startX)
-	y := float64(startY)
-
-	for dist := 0; dist <= VisionRadius; dist++ {
-		ix, iy := int(x+0.5), int(y+0.5)
-
-		if ix < 0 || ix >= gs.Dungeon.Width || iy < 0 || iy >= gs.Dungeon.Height {
-			break
+	if gs.RevealMap {
+		for y := range gs.Visible {
+			for x := range gs.Visible[y] {
+				gs.Visible[y][x] = true
+				gs.Explored[y][x] = true
+			}
 		}
+		return
+	}
 
-		gs.Visible[iy][ix] = true
-		gs.Explored[iy][ix] = true
-
-		if gs.Dungeon.Tiles[iy][ix] == TileWall {
-			break
+	// Fog of war: light up what each player still in the game can see
+	for _, p := range gs.Players() {
+		if !p.IsAlive() && !gs.playersDown() {
+			continue
 		}
-
-		x += dx
-		y += dy
+		gs.computeFOV(p.X, p.Y, gs.visionRadiusAt(p.X, p.Y))
 	}
 }
 
@@ -544,33 +1286,55 @@ func abs(x int) int {
 	return x
 }
 
-func cos(rad float64) float64 {
-	// Taylor series approximation
-	rad = mod2pi(rad)
-	x2 := rad * rad
-	return 1 - x2/2 + x2*x2/24 - x2*x2*x2/720
+// Resize records the new terminal size. The current level keeps its size and
+// everything on it (merge conflict tiles, fire, visibility) stays in world
+// coordinates, so draw just re-centers it; the next level is sized to fit.
+func (gs *GameState) Resize(termWidth, termHeight int) {
+	gs.TermWidth = termWidth
+	gs.TermHeight = termHeight
 }
 
-func sin(rad float64) float64 {
-	rad = mod2pi(rad)
-	x2 := rad * rad
-	return rad - rad*x2/6 + rad*x2*x2/120
+// sortByPlayerDistance orders tiles nearest the player first, keeping the existing order for ties
+func (gs *GameState) sortByPlayerDistance(tiles [][2]int) {
+	sort.SliceStable(tiles, func(i, j int) bool {
+		di := max(abs(tiles[i][0]-gs.Player.X), abs(tiles[i][1]-gs.Player.Y))
+		dj := max(abs(tiles[j][0]-gs.Player.X), abs(tiles[j][1]-gs.Player.Y))
+		return di < dj
+	})
 }
 
-func mod2pi(x float64) float64 {
-	twoPi := 6.28318530718
-	for x > 3.14159265 {
-		x -= twoPi
+// growMergeFireTowardPlayer spreads a triggered merge conflict by one more
+// tile each turn, picking the walkable tile next to the fire that is closest
+// to the player, until the spread reaches MaxMergeFireSpread
+func (gs *GameState) growMergeFireTowardPlayer() {
+	if len(gs.MergeConflictSpread) >= MaxMergeFireSpread {
+		return
+	}
+
+	var frontier [][2]int
+	seen := make(map[[2]int]bool)
+	for y := 0; y < gs.Dungeon.Height; y++ {
+		for x := 0; x < gs.Dungeon.Width; x++ {
+			if !gs.isInMergeConflictArea(x, y) {
+				continue
+			}
+			for _, dir := range [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+				tile := [2]int{x + dir[0], y + dir[1]}
+				if seen[tile] || !gs.Dungeon.IsWalkable(tile[0], tile[1]) || gs.isInMergeConflictArea(tile[0], tile[1]) {
+					continue
+				}
+				seen[tile] = true
+				frontier = append(frontier, tile)
+			}
+		}
 	}
-	for x < -3.14159265 {
-		x += twoPi
+	if len(frontier) == 0 {
+		return
 	}
-	return x
-}
 
-func (gs *GameState) Resize(termWidth, termHeight int) {
-	gs.TermWidth = termWidth
-	gs.TermHeight = termHeight
+	gs.sortByPlayerDistance(frontier)
+	gs.MergeConflictSpread = append(gs.MergeConflictSpread, frontier[0])
+	gs.startChaseCooldown(frontier[0])
 }
 
 func (gs *GameState) generateMergeConflictSpread() {
@@ -629,6 +1393,11 @@ func (gs *GameState) generateMergeConflictSpread() {
 	gs.RNG.Shuffle(len(adjacentTiles), func(i, j int) {
 		adjacentTiles[i], adjacentTiles[j] = adjacentTiles[j], adjacentTiles[i]
 	})
+
+	// Chasing fire favors the tiles nearest the player (ties stay shuffled)
+	if gs.MergeFireChase {
+		gs.sortByPlayerDistance(adjacentTiles)
+	}
 	
 	numSpread := 7
 	if len(adjacentTiles) < numSpread {
@@ -665,13 +1434,75 @@ func (gs *GameState) CheckKonamiCode(key string) {
 	}
 }
 
+// revertLevel restores the level to how it was generated: damaged tiles come
+// back, merge conflict fire goes out and the player heals a little. Dead
+// enemies stay dead.
+func (gs *GameState) revertLevel() {
+	for y, row := range gs.PristineTiles {
+		copy(gs.Dungeon.Tiles[y], row)
+	}
+
+	gs.MergeAffectedTiles = make(map[[2]int]bool)
+	gs.MergeCooldownLeft = nil
+	gs.MergeChaseLeft = nil
+	gs.MergeConflictSpread = nil
+	gs.MergeConflictTriggered = false
+	gs.OnMergeConflict = false
+	gs.MergeConflictMovements = 0
+
+	gs.Player.Heal(RevertHealAmount)
+	gs.SetMessage(fmt.Sprintf("git revert! The level is pristine again. (+%d HP)", RevertHealAmount))
+}
+
+// ToggleStance switches combat stance on or off. In stance, directional keys
+// attack an adjacent enemy in that direction but never move the player.
+func (gs *GameState) ToggleStance() {
+	gs.CombatStance = !gs.CombatStance
+	if gs.CombatStance {
+		gs.SetAlert("Combat stance: holding position. Directions only attack.", tcell.Style{})
+	} else {
+		gs.SetAlert("You relax your stance and can move again.", tcell.Style{})
+	}
+}
+
+// UseHotfix deploys a held hotfix, killing every living enemy on the current
+// level. It doesn't take a turn. Returns false if no hotfix is held.
+func (gs *GameState) UseHotfix() bool {
+	if gs.HotfixesHeld == 0 {
+		gs.SetMessage("You don't have a hotfix to deploy.")
+		return false
+	}
+	gs.HotfixesHeld--
+
+	killed, levels := 0, 0
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() {
+			enemy.HP = 0
+			_, gained := gs.killEnemy(enemy)
+			levels += gained
+			killed++
+		}
+	}
+	gs.HotfixFlash = true
+
+	gs.SetAlert(fmt.Sprintf("HOTFIX DEPLOYED STRAIGHT TO PRODUCTION! %d enemies wiped out.", killed),
+		tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack).Bold(true))
+	gs.announceLevelUp(levels)
+	return true
+}
+
 // triggerMergeConflict handles the player stepping on a merge conflict marker
 func (gs *GameState) triggerMergeConflict() {
 	// Deal damage to player (unless invulnerable)
 	if !gs.Invulnerable {
-		gs.Player.TakeDamage(2)
+		gs.hurtPlayer(gs.Player, mitigate(gs.Player, 2))
+	}
+	gs.SetAlert("MERGE CONFLICT! The code tears apart around you!", tcell.Style{})
+
+	// In merge queue mode the conflict also spawns a wave of enemies (once per level)
+	if gs.MergeQueue && len(gs.MergeQueueWave) == 0 {
+		gs.spawnMergeQueueWave()
 	}
-	gs.SetMessage("MERGE CONFLICT! The code tears apart around you!")
 	
 	// Mark surrounding tiles as affected (3x3 area around the marker)
 	for dy := -1; dy <= 1; dy++ {
@@ -679,8 +1510,8 @@ func (gs *GameState) triggerMergeConflict() {
 			ax := gs.MergeMarkerX + dx
 			ay := gs.MergeMarkerY + dy
 			if ax >= 0 && ax < gs.Dungeon.Width && ay >= 0 && ay < gs.Dungeon.Height {
-				key := ay*gs.Dungeon.Width + ax
-				gs.MergeAffectedTiles[key] = true
+				gs.MergeAffectedTiles[[2]int{ax, ay}] = true
+				gs.startMergeCooldown(ax, ay)
 			}
 		}
 	}
@@ -688,12 +1519,148 @@ func (gs *GameState) triggerMergeConflict() {
 	// Check for player death
 	if !gs.Player.IsAlive() {
 		gs.GameOver = true
-		gs.SetMessage("You died in a merge conflict!")
+		gs.SetAlert("You died in a merge conflict!", tcell.Style{})
 	}
 }
 
 // IsMergeAffected checks if a tile is affected by a merge conflict
 func (gs *GameState) IsMergeAffected(x, y int) bool {
-	key := y*gs.Dungeon.Width + x
-	return gs.MergeAffectedTiles[key]
+	return gs.MergeAffectedTiles[[2]int{x, y}]
+}
+
+// spawnMergeQueueWave places conflicting commits on free tiles around the merge marker
+func (gs *GameState) spawnMergeQueueWave() {
+	for radius := 1; radius <= 3 && len(gs.MergeQueueWave) < MergeQueueWaveSize; radius++ {
+		var candidates [][2]int
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				if max(abs(dx), abs(dy)) != radius {
+					continue
+				}
+				x, y := gs.MergeMarkerX+dx, gs.MergeMarkerY+dy
+				if gs.canPlaceAt(x, y) && gs.enemyAt(x, y) == nil {
+					candidates = append(candidates, [2]int{x, y})
+				}
+			}
+		}
+
+		gs.RNG.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		for _, tile := range candidates {
+			if len(gs.MergeQueueWave) >= MergeQueueWaveSize {
+				break
+			}
+			commit := NewConflictingCommit(tile[0], tile[1])
+			gs.Enemies = append(gs.Enemies, commit)
+			gs.MergeQueueWave = append(gs.MergeQueueWave, commit)
+		}
+	}
+
+	if len(gs.MergeQueueWave) > 0 {
+		gs.SetMessage(fmt.Sprintf("MERGE CONFLICT! The merge queue spits out %d conflicting commits!", len(gs.MergeQueueWave)))
+	}
+}
+
+// EndlessScore is the running score shown in endless mode
+func (gs *GameState) EndlessScore() int {
+	return (gs.Level-1)*100 + gs.EnemiesKilled*10
+}
+
+// levelMergeConflict returns the conflicted file whose marker is on the
+// current level, or nil once every file has had a level
+func (gs *GameState) levelMergeConflict() *MergeConflictLocation {
+	if gs.Level < 1 || gs.Level > len(gs.MergeConflicts) {
+		return nil
+	}
+	return &gs.MergeConflicts[gs.Level-1]
+}
+
+// ConflictedFiles returns the names of the files with merge conflicts found in merge mode
+func (gs *GameState) ConflictedFiles() []string {
+	return conflictedFiles(gs.MergeConflicts)
+}
+
+// conflictedFiles returns the file name of each merge conflict
+func conflictedFiles(conflicts []MergeConflictLocation) []string {
+	files := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		files[i] = conflict.File
+	}
+	return files
+}
+
+// conflictCount describes how many conflicted files were found, e.g. "3 conflicted files detected"
+func conflictCount(n int) string {
+	if n == 1 {
+		return "1 conflicted file detected"
+	}
+	return fmt.Sprintf("%d conflicted files detected", n)
+}
+
+// MergeConflictPositions lists the merge conflicts on the current level, the
+// trap and the marker, room by room in the dungeon's room order, with any in
+// corridors last. Conflicts that aren't on the level's floor are left out.
+func (gs *GameState) MergeConflictPositions() [][2]int {
+	var positions [][2]int
+	for _, pos := range [][2]int{{gs.MergeConflictX, gs.MergeConflictY}, {gs.MergeMarkerX, gs.MergeMarkerY}} {
+		if gs.Dungeon.IsWalkable(pos[0], pos[1]) {
+			positions = append(positions, pos)
+		}
+	}
+
+	roomIndex := func(pos [2]int) int {
+		for i, room := range gs.Dungeon.Rooms {
+			if room.Contains(pos[0], pos[1]) {
+				return i
+			}
+		}
+		return len(gs.Dungeon.Rooms)
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return roomIndex(positions[i]) < roomIndex(positions[j])
+	})
+	return positions
+}
+
+// CycleMergeFocus highlights the next merge conflict on the level and points the player toward it.
+// It doesn't take a turn, so the pointer shows right away.
+func (gs *GameState) CycleMergeFocus() {
+	positions := gs.MergeConflictPositions()
+	if len(positions) == 0 {
+		gs.MergeFocus = -1
+		gs.SetAlert("No merge conflicts on this level", tcell.Style{})
+		return
+	}
+	gs.MergeFocus = (gs.MergeFocus + 1) % len(positions)
+
+	target := positions[gs.MergeFocus]
+	dx := target[0] - gs.Player.X
+	dy := target[1] - gs.Player.Y
+	distance := max(abs(dx), abs(dy))
+	if distance == 0 {
+		gs.SetAlert(fmt.Sprintf("Merge conflict %d/%d: right under your feet", gs.MergeFocus+1, len(positions)), tcell.Style{})
+		return
+	}
+	gs.SetAlert(fmt.Sprintf("Merge conflict %d/%d: %d tiles %s", gs.MergeFocus+1, len(positions), distance, compassDirection(dx, dy)), tcell.Style{})
+}
+
+// compassDirection names the compass direction of an offset (north is up the screen)
+func compassDirection(dx, dy int) string {
+	vertical := ""
+	if dy < 0 {
+		vertical = "north"
+	} else if dy > 0 {
+		vertical = "south"
+	}
+	horizontal := ""
+	if dx < 0 {
+		horizontal = "west"
+	} else if dx > 0 {
+		horizontal = "east"
+	}
+	if vertical == "" && horizontal == "" {
+		return "here"
+	}
+	return vertical + horizontal
 }
@@ -2,6 +2,8 @@ package game
 
 import (
 	"math/rand"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
@@ -194,6 +196,34 @@ func TestMoveCounter(t *testing.T) {
 	}
 }
 
+func TestWaitLetsEnemiesCloseIn(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 5, 5
+	bug := NewBug(9, 5)
+	gs.Enemies = []*Entity{bug}
+
+	for turn := 1; turn <= 3; turn++ {
+		gs.Wait()
+		if gs.Player.X != 5 || gs.Player.Y != 5 {
+			t.Fatalf("Waiting shouldn't move the player, now at (%d, %d)", gs.Player.X, gs.Player.Y)
+		}
+		if got, want := bug.DistanceTo(gs.Player), max(4-turn, 1); got != want {
+			t.Errorf("After waiting %d turns the bug is %d tiles away, want %d", turn, got, want)
+		}
+	}
+	if gs.MoveCount != 3 || gs.Turns != 3 {
+		t.Errorf("Waiting should count as a move and a turn, got MoveCount %d, Turns %d", gs.MoveCount, gs.Turns)
+	}
+
+	// Once it arrives the bug attacks whether the player moves or not
+	gs.NoAutoAttack = true
+	before := gs.Player.HP
+	gs.Wait()
+	if gs.Player.HP != before-bug.Damage {
+		t.Errorf("A bug next to a waiting player should attack, HP went from %d to %d", before, gs.Player.HP)
+	}
+}
+
 func TestUsernameInitialization(t *testing.T) {
 	// Create a game state with username
 	gs := &GameState{
@@ -498,3 +528,1356 @@ func TestMessageStyleClearing(t *testing.T) {
 	}
 }
 
+
+// newTestState builds a GameState on an open floor with no door or merge marker
+func newTestState(width, height int) *GameState {
+	dungeon := &Dungeon{
+		Width:  width,
+		Height: height,
+		Tiles:  make([][]Tile, height),
+	}
+	for y := range dungeon.Tiles {
+		dungeon.Tiles[y] = make([]Tile, width)
+		for x := range dungeon.Tiles[y] {
+			dungeon.Tiles[y][x] = TileFloor
+		}
+	}
+
+	gs := &GameState{
+		Level:              1,
+		MaxLevel:           5,
+		MessageTurns:       DefaultMessageTurns,
+		RNG:                rand.New(rand.NewSource(42)),
+		Dungeon:            dungeon,
+		Player:             NewPlayer(1, 1),
+		Enemies:            []*Entity{},
+		Potions:            []*Entity{},
+		Visible:            make([][]bool, height),
+		Explored:           make([][]bool, height),
+		DoorX:              -1,
+		DoorY:              -1,
+		MergeConflictX:     -1,
+		MergeConflictY:     -1,
+		MergeMarkerX:       -1,
+		MergeMarkerY:       -1,
+		MergeAffectedTiles: make(map[[2]int]bool),
+	}
+	for y := range gs.Visible {
+		gs.Visible[y] = make([]bool, width)
+		gs.Explored[y] = make([]bool, width)
+	}
+	return gs
+}
+
+func TestPortalTeleportsPlayer(t *testing.T) {
+	gs := newTestState(30, 10)
+	gs.Dungeon.Portals = [][2]int{{2, 1}, {25, 8}}
+	gs.Dungeon.Tiles[1][2] = TilePortal
+	gs.Dungeon.Tiles[8][25] = TilePortal
+	gs.updateVisibility()
+
+	if gs.Visible[8][25] {
+		t.Fatal("Paired portal should start out of sight")
+	}
+
+	// Step onto the first portal
+	gs.MovePlayer(1, 0)
+
+	if gs.Player.X != 25 || gs.Player.Y != 8 {
+		t.Errorf("Player should arrive at paired portal (25, 8), got (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+	if !gs.Visible[8][25] || !gs.Explored[8][25] {
+		t.Error("Visibility should be refreshed around the exit portal")
+	}
+	if gs.Visible[1][1] {
+		t.Error("Tiles around the entry portal should no longer be visible")
+	}
+}
+
+func TestPortalBlockedByEnemy(t *testing.T) {
+	gs := newTestState(30, 10)
+	gs.Dungeon.Portals = [][2]int{{2, 1}, {25, 8}}
+	gs.Dungeon.Tiles[1][2] = TilePortal
+	gs.Dungeon.Tiles[8][25] = TilePortal
+	gs.Enemies = []*Entity{NewScopeCreep(25, 8)}
+
+	gs.MovePlayer(1, 0)
+
+	if gs.Player.X != 2 || gs.Player.Y != 1 {
+		t.Errorf("Player should stay on the entry portal when the exit is occupied, got (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestPlacePortalsLinksTwoRooms(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	dungeon := GenerateDungeon(80, 40, rng, nil)
+	if !dungeon.PlacePortals(rng) {
+		t.Fatal("Expected portals to be placed")
+	}
+
+	a, b := dungeon.Portals[0], dungeon.Portals[1]
+	if a == b {
+		t.Fatal("Portals should be on different tiles")
+	}
+	for _, p := range dungeon.Portals {
+		if dungeon.Tiles[p[1]][p[0]] != TilePortal {
+			t.Errorf("Expected portal tile at (%d, %d)", p[0], p[1])
+		}
+	}
+
+	x, y, ok := dungeon.PortalExit(a[0], a[1])
+	if !ok || x != b[0] || y != b[1] {
+		t.Errorf("Portal at %v should lead to %v, got (%d, %d)", a, b, x, y)
+	}
+	if _, _, ok := dungeon.PortalExit(a[0]+100, a[1]); ok {
+		t.Error("Non-portal tile should not have an exit")
+	}
+}
+
+func TestNoDiagonalsEnemyMovesCardinally(t *testing.T) {
+	gs := newTestState(20, 20)
+	gs.NoDiagonals = true
+	gs.Player.X, gs.Player.Y = 10, 10
+	enemy := NewBug(5, 7)
+	gs.Enemies = []*Entity{enemy}
+
+	gs.moveEnemies()
+
+	if enemy.X != 6 || enemy.Y != 7 {
+		t.Errorf("Enemy should step cardinally to (6, 7), got (%d, %d)", enemy.X, enemy.Y)
+	}
+}
+
+func TestDiagonalCornerCutBlocked(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.X, gs.Player.Y = 5, 5
+
+	// Walls on both orthogonal neighbours of the up-right diagonal
+	gs.Dungeon.Tiles[4][5] = TileWall
+	gs.Dungeon.Tiles[5][6] = TileWall
+
+	gs.MovePlayer(1, -1)
+	if gs.Player.X != 5 || gs.Player.Y != 5 {
+		t.Errorf("Diagonal squeeze between walls should be rejected, player moved to (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+
+	// Opening one side makes the diagonal valid
+	gs.Dungeon.Tiles[4][5] = TileFloor
+	gs.MovePlayer(1, -1)
+	if gs.Player.X != 6 || gs.Player.Y != 4 {
+		t.Errorf("Diagonal with one open side should be allowed, player at (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestEnemyDiagonalCornerCutBlocked(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.X, gs.Player.Y = 8, 2
+	enemy := NewBug(5, 5)
+	gs.Enemies = []*Entity{enemy}
+
+	// Walls on both orthogonal neighbours of the enemy's up-right diagonal
+	gs.Dungeon.Tiles[4][5] = TileWall
+	gs.Dungeon.Tiles[5][6] = TileWall
+
+	gs.moveEnemies()
+	if enemy.X == 6 && enemy.Y == 4 {
+		t.Error("Enemy should not squeeze diagonally between two walls")
+	}
+}
+
+// checkerboardWalls walls off every tile whose coordinates add up to an odd
+// number, so every floor tile is boxed in on all four sides and only diagonal
+// steps between two wall corners lead anywhere
+func checkerboardWalls(gs *GameState) {
+	for y := range gs.Dungeon.Tiles {
+		for x := range gs.Dungeon.Tiles[y] {
+			if (x+y)%2 == 1 {
+				gs.Dungeon.Tiles[y][x] = TileWall
+			}
+		}
+	}
+}
+
+func TestCheckerboardBlocksDiagonalMoves(t *testing.T) {
+	for _, dir := range [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+		gs := newTestState(10, 10)
+		checkerboardWalls(gs)
+		gs.Player.X, gs.Player.Y = 4, 4
+		gs.MovePlayer(dir[0], dir[1])
+		if gs.Player.X != 4 || gs.Player.Y != 4 {
+			t.Errorf("Moving %v through two wall corners should be blocked, player moved to (%d, %d)", dir, gs.Player.X, gs.Player.Y)
+		}
+
+		gs.CornerCutting = true
+		gs.MovePlayer(dir[0], dir[1])
+		if gs.Player.X != 4+dir[0] || gs.Player.Y != 4+dir[1] {
+			t.Errorf("With corner cutting, moving %v should be allowed, player at (%d, %d)", dir, gs.Player.X, gs.Player.Y)
+		}
+	}
+}
+
+func TestCheckerboardBlocksEnemyDiagonalMoves(t *testing.T) {
+	for _, cornerCutting := range []bool{false, true} {
+		gs := newTestState(10, 10)
+		checkerboardWalls(gs)
+		gs.CornerCutting = cornerCutting
+		gs.Player.X, gs.Player.Y = 2, 2
+		// No line of sight squeezes through the corners either, so the enemy
+		// has to be hunting the player already to try to reach them
+		gs.PersistentEnemies = true
+		enemy := NewBug(6, 6)
+		enemy.Alerted = true
+		gs.Enemies = []*Entity{enemy}
+
+		gs.moveEnemies()
+		moved := enemy.X != 6 || enemy.Y != 6
+		if moved != cornerCutting {
+			t.Errorf("With corner cutting %v, enemy moved from (6, 6) to (%d, %d)", cornerCutting, enemy.X, enemy.Y)
+		}
+	}
+}
+
+func TestFlakyTestMovementIsSeeded(t *testing.T) {
+	gs := newTestState(21, 21)
+	gs.Player.X, gs.Player.Y = 10, 10
+	enemy := NewFlakyTest(10, 4)
+	gs.Enemies = []*Entity{enemy}
+
+	// Distance change per turn for seed 42: -1 toward, 0 sideways, +1 away
+	expected := []int{0, -1, -1, -1, 1, -1, -1, -1, 0, -1, -1, -1, -1, -1, -1, 0}
+
+	towards, away := 0, 0
+	for i, want := range expected {
+		enemy.X, enemy.Y = 10, 4
+		before := enemy.DistanceTo(gs.Player)
+		gs.moveEnemies()
+		got := enemy.DistanceTo(gs.Player) - before
+		if got != want {
+			t.Errorf("Turn %d: expected distance change %d, got %d", i, want, got)
+		}
+		if got < 0 {
+			towards++
+		} else if got > 0 {
+			away++
+		}
+	}
+
+	if towards == 0 || away == 0 {
+		t.Errorf("Flaky test should sometimes chase and sometimes flee, got %d toward and %d away", towards, away)
+	}
+}
+
+func TestLevelFileNameFollowsLevel(t *testing.T) {
+	codeFiles := []CodeFile{
+		{Path: "game/state.go", Lines: []string{"package game"}},
+		{Path: "game/scanner.go", Lines: []string{"package game"}},
+		{Path: "main.go", Lines: []string{"package main"}},
+	}
+	gs := NewGameState(codeFiles, 12345, 80, 40)
+
+	expected := []string{"state.go", "scanner.go", "main.go", "state.go", "scanner.go"}
+	for i, want := range expected {
+		gs.Level = i + 1
+		gs.generateLevel()
+		if gs.LevelFileName != want {
+			t.Errorf("Level %d: expected file %q, got %q", gs.Level, want, gs.LevelFileName)
+		}
+	}
+}
+
+func TestDescentMessageNamesLevelFile(t *testing.T) {
+	codeFiles := []CodeFile{
+		{Path: "game/state.go", Lines: []string{"package game"}},
+		{Path: "game/scanner.go", Lines: []string{"package game"}},
+	}
+	gs := NewGameState(codeFiles, 12345, 80, 40)
+
+	// Walk onto the door from an adjacent walkable tile
+	gs.Enemies = nil
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.MovePlayer(1, 0)
+
+	if gs.Level != 2 {
+		t.Fatalf("Expected to descend to level 2, got %d", gs.Level)
+	}
+	if gs.Message != "Descending into scanner.go..." {
+		t.Errorf("Expected descent banner for scanner.go, got %q", gs.Message)
+	}
+}
+
+func TestGenerateLevelUsesLevelCodeFile(t *testing.T) {
+	codeFiles := []CodeFile{
+		{Path: "game/state.go", Lines: []string{"package game"}},
+		{Path: "game/scanner.go", Lines: []string{"package game"}},
+		{Path: "main.go", Lines: []string{"package main"}},
+	}
+	gs := NewGameState(codeFiles, 12345, 80, 40)
+
+	for level := 1; level <= 4; level++ {
+		gs.Level = level
+		gs.generateLevel()
+		want := codeFiles[(level-1)%len(codeFiles)].Path
+		if gs.Dungeon.CodeFile == nil || gs.Dungeon.CodeFile.Path != want {
+			t.Errorf("Level %d: expected the dungeon to be built from %s, got %v", level, want, gs.Dungeon.CodeFile)
+		}
+	}
+}
+
+func TestLevelTitleFitsTerminal(t *testing.T) {
+	gs := newTestState(30, 10)
+	gs.TermWidth = 30
+	gs.LevelFileName = "a_really_long_generated_file_name.pb.go"
+
+	if !gs.announceLevelFile("Descending into %s...") {
+		t.Fatal("A level with a code file should be announced")
+	}
+	if len(gs.Message) != gs.TermWidth {
+		t.Errorf("Title should be cut to the %d column terminal, got %q", gs.TermWidth, gs.Message)
+	}
+	if !strings.HasSuffix(gs.Message, ".pb.go...") || !strings.Contains(gs.Message, "into ...") {
+		t.Errorf("Title should keep the end of the file name, got %q", gs.Message)
+	}
+	if gs.MessageStyle != levelTitleStyle {
+		t.Error("Title should be drawn in the level title style")
+	}
+
+	gs.LevelFileName = ""
+	if gs.announceLevelFile("Descending into %s...") {
+		t.Error("A level without a code file has nothing to announce")
+	}
+}
+
+func TestDescentSkipsEnemyTurn(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.DoorX, gs.DoorY = 2, 1
+	gs.Player.HP = 7
+	// Adjacent to both the player and the door, so it would hit either way
+	enemy := NewScopeCreep(2, 2)
+	gs.Enemies = []*Entity{enemy}
+
+	gs.MovePlayer(1, 0)
+
+	if gs.Level != 2 {
+		t.Fatalf("Expected to descend to level 2, got %d", gs.Level)
+	}
+	if gs.Player.HP != 7 {
+		t.Errorf("No enemy should act on the descent step, HP: %d, expected 7", gs.Player.HP)
+	}
+}
+
+func TestDescentIgnoresHazardsOnDoor(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.DoorX, gs.DoorY = 2, 1
+	gs.MergeMarkerX, gs.MergeMarkerY = 2, 1
+	gs.Dungeon.Tiles[1][2] = TileLint
+	initialHP := gs.Player.HP
+
+	gs.MovePlayer(1, 0)
+
+	if gs.Level != 2 {
+		t.Fatalf("Expected to descend to level 2, got %d", gs.Level)
+	}
+	if gs.Player.HP != initialHP {
+		t.Errorf("Hazards under the door should not apply on descent, HP: %d, expected: %d", gs.Player.HP, initialHP)
+	}
+	if gs.Slowed {
+		t.Error("The new level should not start with the player slowed")
+	}
+}
+
+func TestDescentPreservesHP(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	gs.Enemies = nil
+	gs.Player.HP = 4
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.MovePlayer(1, 0)
+
+	if gs.Level != 2 {
+		t.Fatalf("Expected to descend to level 2, got %d", gs.Level)
+	}
+	if gs.Player.HP != 4 || gs.Player.MaxHP != 20 {
+		t.Errorf("HP should carry over unchanged, got %d/%d, expected 4/20", gs.Player.HP, gs.Player.MaxHP)
+	}
+}
+
+func TestPersistentEnemyChasesAroundCorner(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.PersistentEnemies = true
+	addWallColumn(gs.Dungeon, 10, 0, 7)
+	gs.Player.X, gs.Player.Y = 8, 2
+	enemy := NewScopeCreep(3, 2)
+	gs.Enemies = []*Entity{enemy}
+
+	// Enemy spots the player and gives chase
+	gs.moveEnemies()
+	if !enemy.Alerted {
+		t.Fatal("Enemy with line of sight should become alerted")
+	}
+
+	// Player ducks behind the wall
+	gs.Player.X, gs.Player.Y = 13, 2
+	if gs.hasLineOfSight(enemy.X, enemy.Y, gs.Player.X, gs.Player.Y) {
+		t.Fatal("Wall should block line of sight")
+	}
+
+	before := len(gs.pathTo(enemy.X, enemy.Y, gs.Player.X, gs.Player.Y))
+	gs.moveEnemies()
+	after := len(gs.pathTo(enemy.X, enemy.Y, gs.Player.X, gs.Player.Y))
+	if after >= before {
+		t.Errorf("Alerted enemy should keep approaching, path length went from %d to %d", before, after)
+	}
+}
+
+func TestEnemyFreezesWithoutLineOfSightByDefault(t *testing.T) {
+	gs := newTestState(20, 10)
+	addWallColumn(gs.Dungeon, 10, 0, 7)
+	gs.Player.X, gs.Player.Y = 13, 2
+	enemy := NewScopeCreep(4, 2)
+	enemy.Alerted = true
+	gs.Enemies = []*Entity{enemy}
+
+	gs.moveEnemies()
+	if enemy.X != 4 || enemy.Y != 2 {
+		t.Errorf("Enemy without line of sight should not move, got (%d, %d)", enemy.X, enemy.Y)
+	}
+}
+
+func TestMergeQueueSpawnsWave(t *testing.T) {
+	gs := newTestState(20, 20)
+	gs.MergeQueue = true
+	gs.MergeMarkerX, gs.MergeMarkerY = 10, 10
+	gs.Player.X, gs.Player.Y = 10, 10
+
+	gs.triggerMergeConflict()
+
+	if len(gs.MergeQueueWave) != MergeQueueWaveSize {
+		t.Fatalf("Expected %d conflicting commits, got %d", MergeQueueWaveSize, len(gs.MergeQueueWave))
+	}
+	if len(gs.Enemies) != MergeQueueWaveSize {
+		t.Errorf("Wave should be added to enemies, got %d enemies", len(gs.Enemies))
+	}
+	for _, e := range gs.MergeQueueWave {
+		if e.Type != EntityConflictingCommit {
+			t.Errorf("Expected conflicting commit, got type %d", e.Type)
+		}
+		if !gs.Dungeon.IsWalkable(e.X, e.Y) {
+			t.Errorf("Commit spawned on unwalkable tile (%d, %d)", e.X, e.Y)
+		}
+		if e.X == gs.Player.X && e.Y == gs.Player.Y {
+			t.Error("Commit spawned on the player")
+		}
+		if abs(e.X-gs.MergeMarkerX) > 3 || abs(e.Y-gs.MergeMarkerY) > 3 {
+			t.Errorf("Commit spawned too far from the marker at (%d, %d)", e.X, e.Y)
+		}
+	}
+
+	// Triggering again on the same level doesn't spawn another wave
+	gs.triggerMergeConflict()
+	if len(gs.Enemies) != MergeQueueWaveSize {
+		t.Errorf("Merge queue should only spawn once per level, got %d enemies", len(gs.Enemies))
+	}
+}
+
+func TestMergeConflictNoWaveByDefault(t *testing.T) {
+	gs := newTestState(20, 20)
+	gs.MergeMarkerX, gs.MergeMarkerY = 10, 10
+	gs.Player.X, gs.Player.Y = 10, 10
+
+	gs.triggerMergeConflict()
+
+	if len(gs.Enemies) != 0 {
+		t.Errorf("Expected no enemies without merge queue mode, got %d", len(gs.Enemies))
+	}
+}
+
+func TestMergeFireDamageOnSpreadTile(t *testing.T) {
+	gs := newTestState(30, 30)
+	gs.MergeConflictX, gs.MergeConflictY = 10, 10
+	gs.MergeConflictTriggered = true
+	gs.MergeConflictSpread = [][2]int{{13, 10}}
+
+	// Standing on a spread tile is harmless by default
+	gs.Player.X, gs.Player.Y = 13, 10
+	initialHP := gs.Player.HP
+	gs.checkMergeConflict()
+	if gs.Player.HP != initialHP {
+		t.Fatalf("Spread tile should be cosmetic by default, HP went from %d to %d", initialHP, gs.Player.HP)
+	}
+
+	gs.MergeFireDamage = true
+	gs.checkMergeConflict()
+	if gs.Player.HP != initialHP-1 {
+		t.Errorf("Spread tile should burn with merge fire enabled, HP: %d, expected: %d", gs.Player.HP, initialHP-1)
+	}
+
+	// Outside the fire is still safe
+	gs.Player.X, gs.Player.Y = 20, 20
+	gs.checkMergeConflict()
+	if gs.Player.HP != initialHP-1 {
+		t.Errorf("Player outside the fire should not burn, HP: %d", gs.Player.HP)
+	}
+}
+
+func TestMergeFireDamageRespectsInvulnerability(t *testing.T) {
+	gs := newTestState(30, 30)
+	gs.MergeFireDamage = true
+	gs.Invulnerable = true
+	gs.MergeConflictX, gs.MergeConflictY = 10, 10
+	gs.MergeConflictTriggered = true
+	gs.Player.X, gs.Player.Y = 11, 11
+
+	initialHP := gs.Player.HP
+	gs.checkMergeConflict()
+	if gs.Player.HP != initialHP {
+		t.Errorf("Invulnerable player should not burn, HP: %d, expected: %d", gs.Player.HP, initialHP)
+	}
+}
+
+func TestPotionBudgetCapsRun(t *testing.T) {
+	const budget = 7
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.PotionBudget = budget
+	})
+
+	// Leftovers are carried forward, so count each potion once
+	seen := make(map[*Entity]bool)
+	for {
+		for _, potion := range gs.Potions {
+			seen[potion] = true
+		}
+		if gs.Level >= gs.MaxLevel {
+			break
+		}
+		gs.Level++
+		gs.generateLevel()
+	}
+
+	total := len(seen)
+	if total > budget {
+		t.Errorf("Spawned %d potions across the run, budget was %d", total, budget)
+	}
+	if gs.PotionsSpawned != total {
+		t.Errorf("PotionsSpawned should track the run total %d, got %d", total, gs.PotionsSpawned)
+	}
+}
+
+func TestPotionBudgetCarriesLeftoversForward(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.PotionBudget = 7
+		gs.PotionTiers = true
+	})
+	leftover := gs.Potions[0]
+	leftover.Tier = PotionHuge
+	left := len(gs.Potions)
+
+	gs.Level++
+	gs.generateLevel()
+	carried := false
+	for _, potion := range gs.Potions {
+		if potion == leftover {
+			carried = true
+			if !gs.Dungeon.IsWalkable(potion.X, potion.Y) {
+				t.Errorf("Carried potion placed on an unwalkable tile at %d,%d", potion.X, potion.Y)
+			}
+		}
+	}
+	if !carried || leftover.Tier != PotionHuge {
+		t.Errorf("Expected the leftover huge potion on the next level, carried %v tier %v", carried, leftover.Tier)
+	}
+	if len(gs.Potions) < left {
+		t.Errorf("Expected the %d leftover potions and any new ones, got %d", left, len(gs.Potions))
+	}
+}
+
+func TestPotionBudgetUnlimitedByDefault(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	if len(gs.Potions) < 3 {
+		t.Errorf("Expected the usual level 1 potions without a budget, got %d", len(gs.Potions))
+	}
+}
+
+func TestCycleMergeFocusPointsAtConflict(t *testing.T) {
+	gs := newTestState(20, 20)
+	gs.MergeFocus = -1
+	gs.Player.X, gs.Player.Y = 5, 5
+	gs.MergeConflictX, gs.MergeConflictY = 5, 2
+	gs.MergeMarkerX, gs.MergeMarkerY = 9, 9
+	gs.MessageTurns = 3
+
+	gs.CycleMergeFocus()
+	if gs.Message != "Merge conflict 1/2: 3 tiles north" {
+		t.Errorf("Unexpected focus message %q", gs.Message)
+	}
+
+	gs.CycleMergeFocus()
+	if gs.Message != "Merge conflict 2/2: 4 tiles southeast" {
+		t.Errorf("Unexpected focus message %q", gs.Message)
+	}
+
+	gs.CycleMergeFocus()
+	if gs.MergeFocus != 0 {
+		t.Errorf("Focus should wrap back to the first conflict, got %d", gs.MergeFocus)
+	}
+}
+
+func TestMergeConflictPositionsFollowLayout(t *testing.T) {
+	gs := newTestState(20, 20)
+	gs.Dungeon.Rooms = []*Room{{X: 10, Y: 10, W: 5, H: 5}, {X: 0, Y: 0, W: 8, H: 8}}
+	gs.MergeConflictX, gs.MergeConflictY = 3, 3
+	gs.MergeMarkerX, gs.MergeMarkerY = 12, 12
+
+	positions := gs.MergeConflictPositions()
+	if len(positions) != 2 || positions[0] != [2]int{12, 12} || positions[1] != [2]int{3, 3} {
+		t.Errorf("Expected the conflicts in room order, got %v", positions)
+	}
+
+	gs.Dungeon.Tiles[12][12] = TileWall
+	if positions := gs.MergeConflictPositions(); len(positions) != 1 || positions[0] != [2]int{3, 3} {
+		t.Errorf("Expected a conflict in a wall to be left out, got %v", positions)
+	}
+}
+
+func TestRandomFloorTileFallsBackToFloor(t *testing.T) {
+	gs := newTestState(20, 20)
+	for y := range gs.Dungeon.Tiles {
+		for x := range gs.Dungeon.Tiles[y] {
+			gs.Dungeon.Tiles[y][x] = TileWall
+		}
+	}
+	gs.Dungeon.Tiles[4][6] = TileFloor
+
+	if x, y := gs.randomFloorTile(); x != 6 || y != 4 {
+		t.Errorf("Without rooms expected the only floor tile 6,4, got %d,%d", x, y)
+	}
+}
+
+func TestEndlessModeContinuesPastMaxLevel(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) {
+		gs.Endless = true
+	})
+	gs.Level = gs.MaxLevel
+	gs.generateLevel()
+	enemiesAtMax := len(gs.Enemies)
+
+	// Walk onto the door of the former victory level
+	gs.Enemies = nil
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.MovePlayer(1, 0)
+
+	if gs.Victory {
+		t.Fatal("Endless mode should not end in victory")
+	}
+	if gs.Level != gs.MaxLevel+1 || gs.EndlessDepth != 1 {
+		t.Errorf("Expected level %d at endless depth 1, got level %d depth %d", gs.MaxLevel+1, gs.Level, gs.EndlessDepth)
+	}
+	if len(gs.Enemies) <= enemiesAtMax {
+		t.Errorf("Endless levels should escalate: %d enemies, was %d", len(gs.Enemies), enemiesAtMax)
+	}
+}
+
+func TestVictoryAtMaxLevelWithoutEndless(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	gs.Level = gs.MaxLevel
+	gs.generateLevel()
+
+	gs.Enemies = nil
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.MovePlayer(1, 0)
+
+	if !gs.Victory {
+		t.Error("Reaching the door on the final level should be a victory")
+	}
+}
+
+func TestCustomMaxLevel(t *testing.T) {
+	g, err := NewHeadless(WithSeed(12345), WithMaxLevel(2))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	gs := g.State()
+	if gs.MaxLevel != 2 {
+		t.Fatalf("Expected a 2 level run, got MaxLevel %d", gs.MaxLevel)
+	}
+
+	gs.takeDoor()
+	if gs.Victory || gs.Level != 2 {
+		t.Fatalf("The first door should lead to level 2, got level %d (victory %v)", gs.Level, gs.Victory)
+	}
+	if !slices.ContainsFunc(gs.Enemies, func(e *Entity) bool { return e.Type == EntityMonolith }) {
+		t.Error("The boss should guard the chosen final level")
+	}
+	gs.takeDoor()
+	if !gs.Victory {
+		t.Error("Leaving the chosen final level should be a victory")
+	}
+}
+
+func TestLintWarningDamagesAndSlowsOnce(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Dungeon.Tiles[1][2] = TileLint
+	enemy := NewBug(12, 1)
+	gs.Enemies = []*Entity{enemy}
+	initialHP := gs.Player.HP
+
+	// Step onto the lint: 1 damage and the enemy gets two moves
+	gs.MovePlayer(1, 0)
+	if gs.Player.HP != initialHP-1 {
+		t.Errorf("Lint should deal 1 damage, HP: %d, expected: %d", gs.Player.HP, initialHP-1)
+	}
+	if enemy.X != 10 {
+		t.Errorf("Slowed player should give the enemy an extra move, enemy at x=%d, expected 10", enemy.X)
+	}
+	if gs.Slowed {
+		t.Error("Slow effect should be consumed after one turn")
+	}
+
+	// Next step off the lint is a normal turn
+	gs.MovePlayer(0, 1)
+	if enemy.X != 9 {
+		t.Errorf("Enemy should only move once on a normal turn, enemy at x=%d, expected 9", enemy.X)
+	}
+}
+
+func TestLintWarningIsNonLethal(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Dungeon.Tiles[1][2] = TileLint
+	gs.Player.HP = 1
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.HP != 1 || gs.GameOver {
+		t.Errorf("Lint should never kill the player, HP: %d, game over: %v", gs.Player.HP, gs.GameOver)
+	}
+}
+
+func TestExploredMapPersistsPerSeedAndLevel(t *testing.T) {
+	dir := t.TempDir()
+	withDir := func(gs *GameState) { gs.ExploredDir = dir }
+
+	gs := NewGameState(nil, 777, 80, 40, withDir)
+	gs.Explored[1][2] = true
+	gs.Explored[3][4] = true
+	if err := gs.SaveExplored(); err != nil {
+		t.Fatalf("SaveExplored failed: %v", err)
+	}
+
+	// Same seed and level remembers the explored tiles
+	again := NewGameState(nil, 777, 80, 40, withDir)
+	if !again.Explored[1][2] || !again.Explored[3][4] {
+		t.Error("Expected stored explored tiles to be pre-populated for the same seed and level")
+	}
+
+	// A different seed starts from scratch
+	other := NewGameState(nil, 778, 80, 40, withDir)
+	if other.Explored[1][2] && !other.Visible[1][2] {
+		t.Error("Explored map should not carry over to a different seed")
+	}
+
+	// So does a different level of the same seed
+	next := NewGameState(nil, 777, 80, 40, withDir, func(gs *GameState) { gs.Level = 2 })
+	if next.Explored[1][2] && !next.Visible[1][2] {
+		t.Error("Explored map should not carry over to a different level")
+	}
+}
+
+func TestExploredMapIgnoresMismatchedSize(t *testing.T) {
+	dir := t.TempDir()
+	withDir := func(gs *GameState) { gs.ExploredDir = dir }
+
+	gs := NewGameState(nil, 777, 80, 40, withDir)
+	gs.Explored[1][2] = true
+	if err := gs.SaveExplored(); err != nil {
+		t.Fatalf("SaveExplored failed: %v", err)
+	}
+
+	resized := NewGameState(nil, 777, 100, 50, withDir)
+	if resized.Explored[1][2] && !resized.Visible[1][2] {
+		t.Error("Explored map saved for another dungeon size should be ignored")
+	}
+}
+
+func TestSquashChainAttacksNextEnemyInLine(t *testing.T) {
+	gs := newTestState(20, 10)
+	first := NewBug(2, 1)
+	second := NewBug(3, 1)
+	gs.Enemies = []*Entity{first, second}
+
+	gs.MovePlayer(1, 0)
+
+	if first.IsAlive() || second.IsAlive() {
+		t.Fatal("Killing the first bug should chain into the bug lined up behind it")
+	}
+	if gs.EnemiesKilled != 2 {
+		t.Errorf("Expected 2 kills, got %d", gs.EnemiesKilled)
+	}
+	if gs.Player.X != 2 || gs.Player.Y != 1 {
+		t.Errorf("Player should advance into the first vacated tile, got (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestSquashChainStopsWithoutEnemyInLine(t *testing.T) {
+	gs := newTestState(20, 10)
+	bug := NewBug(2, 1)
+	gs.Enemies = []*Entity{bug}
+
+	gs.MovePlayer(1, 0)
+
+	if bug.IsAlive() {
+		t.Fatal("Bump attack should kill the bug")
+	}
+	if gs.Player.X != 1 || gs.Player.Y != 1 {
+		t.Errorf("Player should stay put after a single kill, got (%d, %d)", gs.Player.X, gs.Player.Y)
+	}
+}
+
+func TestSquashChainIsCapped(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Invulnerable = true
+	for x := 2; x < 2+MaxSquashChain+2; x++ {
+		gs.Enemies = append(gs.Enemies, NewBug(x, 1))
+	}
+
+	gs.MovePlayer(1, 0)
+
+	if gs.EnemiesKilled != MaxSquashChain {
+		t.Errorf("Expected the chain to stop after %d kills, got %d", MaxSquashChain, gs.EnemiesKilled)
+	}
+	if survivor := gs.Enemies[MaxSquashChain]; !survivor.IsAlive() {
+		t.Error("The enemy past the chain limit should survive")
+	}
+}
+
+func TestHuntingEnemiesHaveLineOfSight(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 2, 5
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+
+	seeing := NewBug(6, 5)
+	hidden := NewBug(14, 5)
+	dead := NewBug(3, 3)
+	dead.HP = 0
+	gs.Enemies = []*Entity{seeing, hidden, dead}
+
+	hunting := gs.HuntingEnemies()
+	if !hunting[seeing] {
+		t.Error("Enemy with a clear view of the player should be hunting")
+	}
+	if hunting[hidden] {
+		t.Error("Enemy behind a wall should not be hunting")
+	}
+	if hunting[dead] {
+		t.Error("Dead enemies should not be hunting")
+	}
+	if len(hunting) != 1 {
+		t.Errorf("Expected 1 hunting enemy, got %d", len(hunting))
+	}
+}
+
+func TestRevertRestoresLevelWithoutResurrectingEnemies(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.PristineTiles = gs.Dungeon.CloneTiles()
+	gs.Dungeon.Tiles[5][5] = TileWall // damage done to the level since it was generated
+
+	gs.MergeConflictTriggered = true
+	gs.MergeConflictSpread = [][2]int{{8, 8}, {9, 8}}
+	gs.MergeAffectedTiles[[2]int{3, 3}] = true
+
+	dead := NewBug(10, 5)
+	dead.HP = 0
+	gs.Enemies = []*Entity{dead}
+	gs.Player.HP = 10
+	gs.Reverts = []*Entity{NewRevert(2, 1)}
+
+	gs.MovePlayer(1, 0)
+
+	if len(gs.Reverts) != 0 {
+		t.Error("Revert should be consumed on pickup")
+	}
+	if len(gs.MergeAffectedTiles) != 0 || len(gs.MergeConflictSpread) != 0 || gs.MergeConflictTriggered {
+		t.Error("Revert should extinguish the merge conflict fire")
+	}
+	if gs.Dungeon.Tiles[5][5] != TileFloor {
+		t.Error("Revert should restore tiles to their pristine state")
+	}
+	if gs.Player.HP != 10+RevertHealAmount {
+		t.Errorf("Expected HP %d after revert, got %d", 10+RevertHealAmount, gs.Player.HP)
+	}
+	if dead.IsAlive() {
+		t.Error("Revert should not resurrect dead enemies")
+	}
+}
+
+func TestCrowdBlocksSight(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 2, 5
+	front := NewScopeCreep(5, 5)
+	back := NewBug(8, 5)
+	gs.Enemies = []*Entity{front, back}
+
+	if !gs.enemyCanSee(back) {
+		t.Fatal("By default enemies should see through each other")
+	}
+
+	gs.CrowdBlocksSight = true
+	if gs.enemyCanSee(back) {
+		t.Error("With crowds blocking sight, the enemy behind another should not see the player")
+	}
+	if !gs.enemyCanSee(front) {
+		t.Error("The enemy at the front of the crowd should still see the player")
+	}
+
+	// Dead enemies don't block the view
+	front.HP = 0
+	if !gs.enemyCanSee(back) {
+		t.Error("A dead enemy should not block line of sight")
+	}
+}
+
+func TestStartLevel(t *testing.T) {
+	options := &gameOptions{}
+	WithStartLevel(3)(options)
+	gs := NewGameState(nil, 12345, 80, 40, options.configure)
+
+	if gs.Level != 3 {
+		t.Errorf("Expected to start at level 3, got %d", gs.Level)
+	}
+	if gs.MaxLevel != DefaultMaxLevel {
+		t.Errorf("Starting later should keep MaxLevel at %d, got %d", DefaultMaxLevel, gs.MaxLevel)
+	}
+	if want := 3 + 3*2; len(gs.Enemies) != want {
+		t.Errorf("Expected %d enemies on level 3, got %d", want, len(gs.Enemies))
+	}
+}
+
+func TestBlindEnemyOnlyChasesWhenAdjacent(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.X, gs.Player.Y = 2, 5
+	bug := NewBug(6, 5)
+	bug.SightRange = 1
+	gs.Enemies = []*Entity{bug}
+
+	gs.moveEnemies()
+	if bug.X != 6 || bug.Y != 5 {
+		t.Errorf("Blind enemy should not notice a player 4 tiles away, moved to (%d, %d)", bug.X, bug.Y)
+	}
+
+	bug.X = 3
+	if !gs.enemyCanSee(bug) {
+		t.Error("Blind enemy should sense an adjacent player")
+	}
+}
+
+func TestFarSightedEnemyChasesFromAfar(t *testing.T) {
+	gs := newTestState(30, 10)
+	gs.Player.X, gs.Player.Y = 2, 5
+	bug := NewBug(20, 5)
+	bug.SightRange = 25
+	gs.Enemies = []*Entity{bug}
+
+	gs.moveEnemies()
+	if bug.X != 19 {
+		t.Errorf("Far-sighted enemy should chase from 18 tiles away, got x=%d", bug.X)
+	}
+
+	// Range doesn't let it see through walls
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+	gs.moveEnemies()
+	if bug.X != 19 {
+		t.Errorf("Far-sighted enemy still needs line of sight, got x=%d", bug.X)
+	}
+}
+
+func TestSpawnedEnemiesGetSightRanges(t *testing.T) {
+	ranges := make(map[int]int)
+	for seed := int64(1); seed <= 10; seed++ {
+		gs := NewGameState(nil, seed, 80, 40)
+		for _, enemy := range gs.Enemies {
+			ranges[enemy.SightRange]++
+		}
+	}
+
+	for _, r := range []int{0, BlindSightRange, NearsightedRange} {
+		if ranges[r] == 0 {
+			t.Errorf("Expected some spawned enemies with sight range %d, got %v", r, ranges)
+		}
+	}
+}
+
+// averageDistance returns the mean Chebyshev distance from tiles to (x, y)
+func averageDistance(tiles [][2]int, x, y int) float64 {
+	total := 0
+	for _, tile := range tiles {
+		total += max(abs(tile[0]-x), abs(tile[1]-y))
+	}
+	return float64(total) / float64(len(tiles))
+}
+
+func TestMergeFireChaseSpreadsTowardPlayer(t *testing.T) {
+	chasing := newTestState(40, 20)
+	chasing.MergeFireChase = true
+	chasing.MergeConflictX, chasing.MergeConflictY = 20, 10
+	chasing.Player.X, chasing.Player.Y = 35, 10
+	chasing.generateMergeConflictSpread()
+
+	random := newTestState(40, 20)
+	random.MergeConflictX, random.MergeConflictY = 20, 10
+	random.Player.X, random.Player.Y = 35, 10
+	random.generateMergeConflictSpread()
+
+	chaseDist := averageDistance(chasing.MergeConflictSpread, 35, 10)
+	randomDist := averageDistance(random.MergeConflictSpread, 35, 10)
+	if chaseDist >= randomDist {
+		t.Errorf("Chasing spread should be closer to the player (%.2f) than random spread (%.2f)", chaseDist, randomDist)
+	}
+	for _, tile := range chasing.MergeConflictSpread {
+		if tile[0] < 22 {
+			t.Errorf("Chasing spread should hug the side facing the player, got tile %v", tile)
+		}
+	}
+}
+
+func TestMergeFireChaseGrowsEachTurn(t *testing.T) {
+	gs := newTestState(40, 20)
+	gs.MergeFireChase = true
+	gs.MergeConflictX, gs.MergeConflictY = 20, 10
+	gs.Player.X, gs.Player.Y = 35, 10
+	gs.Invulnerable = true
+	gs.generateMergeConflictSpread()
+	gs.MergeConflictTriggered = true
+
+	before := len(gs.MergeConflictSpread)
+	gs.checkMergeConflict()
+	if len(gs.MergeConflictSpread) != before+1 {
+		t.Fatalf("Chasing fire should grow by one tile per turn, went from %d to %d", before, len(gs.MergeConflictSpread))
+	}
+	if newest := gs.MergeConflictSpread[before]; newest[0] != 24 {
+		t.Errorf("New fire should grow toward the player, got %v", newest)
+	}
+
+	for i := 0; i < MaxMergeFireSpread*2; i++ {
+		gs.checkMergeConflict()
+	}
+	if len(gs.MergeConflictSpread) != MaxMergeFireSpread {
+		t.Errorf("Fire spread should be capped at %d tiles, got %d", MaxMergeFireSpread, len(gs.MergeConflictSpread))
+	}
+}
+
+func TestAdjacentBugsMerge(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.BugMergeChance = 1 // force the merge
+	gs.Player.X, gs.Player.Y = 1, 1
+	first := NewBug(10, 5)
+	second := NewBug(11, 5)
+	scope := NewScopeCreep(12, 5)
+	gs.Enemies = []*Entity{first, second, scope}
+
+	gs.mergeBugs()
+
+	if len(gs.Enemies) != 2 {
+		t.Fatalf("Expected the two bugs to merge into one enemy, got %d enemies", len(gs.Enemies))
+	}
+	merged := gs.Enemies[0]
+	if merged.Type != EntityMergedBug {
+		t.Fatalf("Expected a merged bug in the first bug's place, got type %d", merged.Type)
+	}
+	if merged.X != 10 || merged.Y != 5 {
+		t.Errorf("Merged bug should take the first bug's tile, got (%d, %d)", merged.X, merged.Y)
+	}
+	if merged.HP != 3 || merged.MaxHP != 3 || merged.Damage != 2 {
+		t.Errorf("Expected merged stats HP 3/3, damage 2, got HP %d/%d, damage %d", merged.HP, merged.MaxHP, merged.Damage)
+	}
+	if gs.Enemies[1] != scope {
+		t.Error("Other enemies should not be affected by a bug merge")
+	}
+}
+
+func TestBugMergeNeedsAdjacentBugs(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.BugMergeChance = 1
+	gs.Enemies = []*Entity{NewBug(5, 5), NewBug(8, 5), NewScopeCreep(9, 5)}
+
+	gs.mergeBugs()
+	if len(gs.Enemies) != 3 {
+		t.Errorf("Bugs that aren't adjacent should not merge, got %d enemies", len(gs.Enemies))
+	}
+
+	gs.BugMergeChance = 0
+	gs.Enemies = []*Entity{NewBug(5, 5), NewBug(6, 5)}
+	gs.mergeBugs()
+	if len(gs.Enemies) != 2 {
+		t.Error("Bugs should not merge when the chance is 0")
+	}
+}
+
+func TestMergedBugStatsAreCapped(t *testing.T) {
+	big := &Entity{HP: 5, Damage: 5}
+	merged := NewMergedBug(0, 0, big, big)
+	if merged.HP != MaxMergedBugHP || merged.Damage != MaxMergedBugDamage {
+		t.Errorf("Merged stats should be capped at HP %d, damage %d, got %d, %d", MaxMergedBugHP, MaxMergedBugDamage, merged.HP, merged.Damage)
+	}
+}
+
+func TestHotfixKillsAllLivingEnemies(t *testing.T) {
+	gs := newTestState(30, 10)
+	gs.Enemies = []*Entity{NewBug(5, 5), NewScopeCreep(10, 2), NewFlakyTest(20, 8), NewBug(3, 3)}
+	gs.Enemies[3].HP = 0 // Already dead, shouldn't count again
+	gs.EnemiesKilled = 2
+	gs.HotfixesHeld = 1
+
+	if !gs.UseHotfix() {
+		t.Fatal("Expected the held hotfix to deploy")
+	}
+	for _, enemy := range gs.Enemies {
+		if enemy.IsAlive() {
+			t.Errorf("%s at %d,%d survived the hotfix", enemy.Name(), enemy.X, enemy.Y)
+		}
+	}
+	if gs.EnemiesKilled != 5 {
+		t.Errorf("Expected 3 kills credited on top of 2, got %d", gs.EnemiesKilled)
+	}
+	if gs.HotfixesHeld != 0 || !gs.HotfixFlash {
+		t.Errorf("Expected the hotfix used up with a flash, held %d flash %v", gs.HotfixesHeld, gs.HotfixFlash)
+	}
+	if want := enemyXP(gs.Enemies[0]) + enemyXP(gs.Enemies[1]) + enemyXP(gs.Enemies[2]); gs.XP != want {
+		t.Errorf("Expected the hotfix kills to award %d XP, got %d", want, gs.XP)
+	}
+}
+
+func TestHotfixKillsDropLoot(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Level = 5
+	gs.RNG = rand.New(rand.NewSource(3)) // Same roll that drops loot in TestKillDropsLootWhereEnemyFell
+	gs.Enemies = []*Entity{NewBug(5, 5)}
+	gs.HotfixesHeld = 1
+
+	gs.UseHotfix()
+	if items := len(gs.Potions) + len(gs.Reverts) + len(gs.Armor) + len(gs.Hotfixes); items != 1 {
+		t.Errorf("Expected the hotfixed bug to drop its loot, got %d items", items)
+	}
+}
+
+func TestHotfixNeedsOneHeld(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Enemies = []*Entity{NewBug(5, 5)}
+
+	if gs.UseHotfix() {
+		t.Error("Hotfix shouldn't deploy when none is held")
+	}
+	if !gs.Enemies[0].IsAlive() || gs.EnemiesKilled != 0 {
+		t.Error("A failed hotfix shouldn't touch enemies")
+	}
+}
+
+func TestHotfixPickedUpNotUsed(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Hotfixes = []*Entity{NewHotfix(2, 1)}
+	enemy := NewBug(15, 8)
+	gs.Enemies = []*Entity{enemy}
+
+	gs.MovePlayer(1, 0)
+
+	if gs.HotfixesHeld != 1 || len(gs.Hotfixes) != 0 {
+		t.Errorf("Expected the hotfix to be held, held %d, %d left on the floor", gs.HotfixesHeld, len(gs.Hotfixes))
+	}
+	if !enemy.IsAlive() {
+		t.Error("Picking up a hotfix shouldn't deploy it")
+	}
+}
+
+func TestCombatStanceAttacksWithoutMoving(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.CombatStance = true
+	enemy := NewScopeCreep(2, 1)
+	gs.Enemies = []*Entity{enemy}
+
+	gs.MovePlayer(1, 0)
+	if enemy.HP != enemy.MaxHP-gs.Player.Damage {
+		t.Errorf("Expected the stance attack to hit, enemy HP %d/%d", enemy.HP, enemy.MaxHP)
+	}
+	if gs.Player.X != 1 || gs.Player.Y != 1 {
+		t.Errorf("Attacking from stance shouldn't move the player, now at %d,%d", gs.Player.X, gs.Player.Y)
+	}
+
+	// No enemy that way: nothing happens
+	gs.MovePlayer(0, 1)
+	if gs.Player.X != 1 || gs.Player.Y != 1 || gs.MoveCount != 0 {
+		t.Errorf("Stance shouldn't step onto empty floor, now at %d,%d after %d moves", gs.Player.X, gs.Player.Y, gs.MoveCount)
+	}
+}
+
+func TestCombatStanceDoesNotChargeDownTheLine(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.CombatStance = true
+	first := NewBug(2, 1)
+	second := NewBug(3, 1)
+	gs.Enemies = []*Entity{first, second}
+
+	gs.MovePlayer(1, 0)
+	if first.IsAlive() {
+		t.Error("Expected the adjacent bug to be squashed")
+	}
+	if gs.Player.X != 1 {
+		t.Errorf("Stance shouldn't squash-chain forward, player at x=%d", gs.Player.X)
+	}
+}
+
+func TestResizeKeepsMergeAffectedTiles(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.MergeMarkerX, gs.MergeMarkerY = 19, 4 // On the right edge, where y*width+x keys used to wrap
+	gs.triggerMergeConflict()
+
+	before := make(map[[2]int]bool)
+	for tile := range gs.MergeAffectedTiles {
+		before[tile] = true
+	}
+
+	for _, size := range [][2]int{{40, 20}, {12, 6}, {80, 40}} {
+		gs.Resize(size[0], size[1])
+		for y := 0; y < gs.Dungeon.Height; y++ {
+			for x := 0; x < gs.Dungeon.Width; x++ {
+				if gs.IsMergeAffected(x, y) != before[[2]int{x, y}] {
+					t.Errorf("after resizing to %dx%d, tile (%d,%d) affected = %v, want %v",
+						size[0], size[1], x, y, gs.IsMergeAffected(x, y), before[[2]int{x, y}])
+				}
+			}
+		}
+	}
+	if gs.IsMergeAffected(0, 4) || gs.IsMergeAffected(20, 4) {
+		t.Error("Tiles outside the conflict (or the map) shouldn't read as merge-affected")
+	}
+	if len(gs.Visible) != gs.Dungeon.Height || len(gs.Visible[0]) != gs.Dungeon.Width {
+		t.Error("Resizing the terminal shouldn't resize the current level's visibility")
+	}
+}
+
+func TestPotionHealPercentOfMaxHP(t *testing.T) {
+	tests := []struct {
+		maxHP, percent, want int
+	}{
+		{maxHP: 20, percent: 0, want: PotionHealAmount}, // Flat by default
+		{maxHP: 20, percent: 25, want: 5},
+		{maxHP: 40, percent: 25, want: 10},
+		{maxHP: 30, percent: 15, want: 4}, // Rounds down
+		{maxHP: 5, percent: 10, want: 1},  // Always heals something
+		{maxHP: 20, percent: 100, want: 20},
+	}
+	for _, tt := range tests {
+		gs := newTestState(10, 10)
+		gs.Player.MaxHP = tt.maxHP
+		gs.PotionHealPercent = tt.percent
+		if got := gs.potionHeal(); got != tt.want {
+			t.Errorf("potionHeal() with max HP %d at %d%% = %d, want %d", tt.maxHP, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestPercentPotionNeverExceedsMaxHP(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.MaxHP = 40
+	gs.Player.HP = 35
+	gs.PotionHealPercent = 50
+	gs.Potions = []*Entity{NewPotion(2, 1)}
+
+	gs.MovePlayer(1, 0)
+	gs.UseItem(0)
+	if gs.Player.HP != 40 {
+		t.Errorf("Healing should be capped at max HP 40, got %d", gs.Player.HP)
+	}
+	if gs.Message != "You drink a health potion! (+5 HP)" {
+		t.Errorf("Drink message should show the 5 HP actually healed, got %q", gs.Message)
+	}
+}
+
+func TestSameSeedSameStart(t *testing.T) {
+	a := NewGameState(nil, 12345, 80, 40)
+	b := NewGameState(nil, 12345, 80, 40)
+	if a.Player.X != b.Player.X || a.Player.Y != b.Player.Y {
+		t.Errorf("Same seed should start the player in the same place, got (%d, %d) and (%d, %d)",
+			a.Player.X, a.Player.Y, b.Player.X, b.Player.Y)
+	}
+	if len(a.Enemies) != len(b.Enemies) {
+		t.Errorf("Same seed should spawn the same number of enemies, got %d and %d", len(a.Enemies), len(b.Enemies))
+	}
+}
+
+func TestLineOfSightIsSymmetric(t *testing.T) {
+	for pattern := int64(0); pattern < 40; pattern++ {
+		gs := newTestState(10, 10)
+		rng := rand.New(rand.NewSource(pattern))
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				if rng.Float64() < 0.3 {
+					gs.Dungeon.Tiles[y][x] = TileWall
+				}
+			}
+		}
+
+		for a := 0; a < 100; a++ {
+			for b := a + 1; b < 100; b++ {
+				ax, ay, bx, by := a%10, a/10, b%10, b/10
+				if !gs.Dungeon.IsWalkable(ax, ay) || !gs.Dungeon.IsWalkable(bx, by) {
+					continue
+				}
+				if gs.hasLineOfSight(ax, ay, bx, by) != gs.hasLineOfSight(bx, by, ax, ay) {
+					t.Fatalf("Pattern %d: sight between (%d, %d) and (%d, %d) differs by direction", pattern, ax, ay, bx, by)
+				}
+			}
+		}
+	}
+}
+
+func TestLineOfSightDiagonalGap(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Dungeon.Tiles[4][5] = TileWall
+	gs.Dungeon.Tiles[5][4] = TileWall
+
+	if gs.hasLineOfSight(4, 4, 5, 5) || gs.hasLineOfSight(2, 2, 7, 7) {
+		t.Error("A diagonal gap between two walls should block sight")
+	}
+	if !gs.hasLineOfSight(4, 6, 7, 3) {
+		t.Error("The other diagonal has no walls in the way")
+	}
+}
+
+func TestLineOfSightStraightLines(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Dungeon.Tiles[3][5] = TileWall
+
+	tests := []struct {
+		x1, y1, x2, y2 int
+		want           bool
+	}{
+		{1, 3, 4, 3, true},
+		{1, 3, 8, 3, false},
+		{5, 0, 5, 2, true},
+		{5, 0, 5, 8, false},
+		{5, 8, 5, 0, false},
+		{0, 4, 9, 4, true},
+	}
+	for _, tt := range tests {
+		if got := gs.hasLineOfSight(tt.x1, tt.y1, tt.x2, tt.y2); got != tt.want {
+			t.Errorf("hasLineOfSight(%d, %d, %d, %d) = %v, want %v", tt.x1, tt.y1, tt.x2, tt.y2, got, tt.want)
+		}
+	}
+}
+
+func TestAttackingEndsTheTurn(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Player.Damage = 0 // The bug survives being bumped
+	gs.Invulnerable = true
+	gs.Enemies = []*Entity{NewBug(2, 1)}
+	gs.Player.HP = 10
+	gs.Player.RegenTurns = 2
+	gs.BlindTurns = 2
+	gs.TorchTurnsLeft = 5
+
+	gs.MovePlayer(1, 0)
+	if gs.Player.X != 1 {
+		t.Fatal("Bumping the bug should attack it, not move")
+	}
+	if gs.BlindTurns != 1 || gs.TorchTurnsLeft != 4 {
+		t.Errorf("An attack should take a turn off blindness and the torch, got %d and %d", gs.BlindTurns, gs.TorchTurnsLeft)
+	}
+	if gs.Player.RegenTurns != 0 {
+		t.Errorf("Fighting isn't resting, got %d rest turns", gs.Player.RegenTurns)
+	}
+}
@@ -0,0 +1,29 @@
+package game
+
+import "github.com/gdamore/tcell/v2"
+
+// DefaultEnemyStyle is how every enemy is drawn unless per-type colors are on
+var DefaultEnemyStyle = tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack)
+
+// enemyTypeStyles gives each enemy type its own color for --enemy-colors
+var enemyTypeStyles = map[EntityType]tcell.Style{
+	EntityBug:               tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack),
+	EntityScopeCreep:        tcell.StyleDefault.Foreground(tcell.ColorOrange).Background(tcell.ColorBlack),
+	EntityFlakyTest:         tcell.StyleDefault.Foreground(tcell.ColorMediumPurple).Background(tcell.ColorBlack),
+	EntityBrowserTest:       tcell.StyleDefault.Foreground(tcell.ColorDodgerBlue).Background(tcell.ColorBlack),
+	EntityRebase:            tcell.StyleDefault.Foreground(tcell.ColorTeal).Background(tcell.ColorBlack),
+	EntityConflictingCommit: tcell.StyleDefault.Foreground(tcell.ColorHotPink).Background(tcell.ColorBlack),
+	EntityMergedBug:         tcell.StyleDefault.Foreground(tcell.ColorCrimson).Background(tcell.ColorBlack).Bold(true),
+	EntityMonolith:          tcell.StyleDefault.Foreground(tcell.ColorSilver).Background(tcell.ColorBlack).Bold(true),
+}
+
+// enemyStyle returns the style to draw an enemy of the given type with,
+// falling back to DefaultEnemyStyle for types without a color of their own
+func enemyStyle(t EntityType, perType bool) tcell.Style {
+	if perType {
+		if style, ok := enemyTypeStyles[t]; ok {
+			return style
+		}
+	}
+	return DefaultEnemyStyle
+}
@@ -0,0 +1,25 @@
+package game
+
+import "testing"
+
+func TestEnemyStylePerType(t *testing.T) {
+	bug := enemyStyle(EntityBug, true)
+	creep := enemyStyle(EntityScopeCreep, true)
+	if bug == creep {
+		t.Error("Bugs and scope creeps should be drawn in different colors")
+	}
+	for _, enemyType := range []EntityType{EntityFlakyTest, EntityBrowserTest, EntityConflictingCommit, EntityMergedBug, EntityMonolith} {
+		if enemyStyle(enemyType, true) == DefaultEnemyStyle {
+			t.Errorf("Enemy type %d should have its own color", enemyType)
+		}
+	}
+}
+
+func TestEnemyStyleFallback(t *testing.T) {
+	if enemyStyle(EntityType(999), true) != DefaultEnemyStyle {
+		t.Error("Unknown types should fall back to the default enemy style")
+	}
+	if enemyStyle(EntityScopeCreep, false) != DefaultEnemyStyle {
+		t.Error("Without per-type colors every enemy should use the default style")
+	}
+}
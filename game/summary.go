@@ -0,0 +1,64 @@
+package game
+
+import "fmt"
+
+// LevelStats counts what happened on the current level, for the summary
+// shown on the way down
+type LevelStats struct {
+	Kills       int
+	PotionsUsed int
+	Turns       int
+	DamageTaken int
+}
+
+// creditKills counts enemies killed towards the run and the current level
+func (gs *GameState) creditKills(n int) {
+	gs.EnemiesKilled += n
+	gs.LevelStats.Kills += n
+}
+
+// countTurn counts a turn taken towards the run and the current level
+func (gs *GameState) countTurn() {
+	gs.Turns++
+	gs.LevelStats.Turns++
+}
+
+// hurtPlayer deals damage to a player, counting the HP actually lost towards
+// the run's and the level's damage taken
+func (gs *GameState) hurtPlayer(player *Entity, dmg int) {
+	before := player.HP
+	player.TakeDamage(dmg)
+	gs.DamageTaken += before - player.HP
+	gs.LevelStats.DamageTaken += before - player.HP
+}
+
+// finishLevelStats starts counting afresh for the next level, keeping the
+// finished level's stats as the summary to show if summaries are on
+func (gs *GameState) finishLevelStats() {
+	if gs.ShowLevelSummary {
+		stats := gs.LevelStats
+		gs.LevelSummary = &stats
+		gs.SummaryLevel = gs.Level
+	}
+	gs.LevelStats = LevelStats{}
+}
+
+// SummaryLines describes the level just finished, one line per stat
+func (gs *GameState) SummaryLines() []string {
+	s := gs.LevelSummary
+	return []string{
+		fmt.Sprintf("Level %d complete", gs.SummaryLevel),
+		"",
+		fmt.Sprintf("Enemies killed: %d", s.Kills),
+		fmt.Sprintf("Potions used:   %d", s.PotionsUsed),
+		fmt.Sprintf("Turns spent:    %d", s.Turns),
+		fmt.Sprintf("Damage taken:   %d", s.DamageTaken),
+		"",
+		"Press any key to continue",
+	}
+}
+
+// renderLevelSummary draws the finished level's stats as a box in the middle of the screen
+func (g *Game) renderLevelSummary(width, height int) {
+	g.renderBox(g.state.SummaryLines(), width, height)
+}
@@ -0,0 +1,63 @@
+package game
+
+import "testing"
+
+func TestLevelStatsAccumulateWithinLevel(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Enemies = []*Entity{NewBug(2, 1)}
+	gs.Potions = []*Entity{NewPotion(1, 2)}
+	gs.Player.HP = 10
+
+	gs.MovePlayer(1, 0) // Bump the bug to death
+	gs.MovePlayer(0, 1) // Step onto the potion
+	gs.UseItem(0)
+	gs.MovePlayer(1, 0)
+	gs.hurtPlayer(gs.Player, 4)
+
+	want := LevelStats{Kills: 1, PotionsUsed: 1, Turns: 4, DamageTaken: 4}
+	if gs.LevelStats != want {
+		t.Errorf("Expected level stats %+v, got %+v", want, gs.LevelStats)
+	}
+}
+
+func TestLevelStatsCountOnlyHPLost(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.HP = 2
+	gs.hurtPlayer(gs.Player, 5)
+	if gs.LevelStats.DamageTaken != 2 {
+		t.Errorf("Overkill damage shouldn't count, got %d", gs.LevelStats.DamageTaken)
+	}
+}
+
+func TestLevelStatsResetOnDescent(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40, func(gs *GameState) { gs.ShowLevelSummary = true })
+	gs.LevelStats = LevelStats{Kills: 3, PotionsUsed: 1, Turns: 40, DamageTaken: 7}
+	descend(gs)
+
+	if gs.LevelStats != (LevelStats{}) {
+		t.Errorf("Level stats should start afresh on a new level, got %+v", gs.LevelStats)
+	}
+	if gs.LevelSummary == nil {
+		t.Fatal("Expected a summary of the finished level")
+	}
+	// The step onto the door is the level's last turn
+	if want := (LevelStats{Kills: 3, PotionsUsed: 1, Turns: 41, DamageTaken: 7}); *gs.LevelSummary != want {
+		t.Errorf("Expected summary %+v, got %+v", want, *gs.LevelSummary)
+	}
+	if gs.SummaryLevel != 1 {
+		t.Errorf("Expected the summary to be for level 1, got %d", gs.SummaryLevel)
+	}
+}
+
+func TestLevelSummaryOffByDefault(t *testing.T) {
+	gs := NewGameState(nil, 12345, 80, 40)
+	gs.LevelStats.Kills = 2
+	descend(gs)
+
+	if gs.LevelSummary != nil {
+		t.Error("No summary should be shown without --level-summary")
+	}
+	if gs.LevelStats != (LevelStats{}) {
+		t.Errorf("Level stats should still reset on descent, got %+v", gs.LevelStats)
+	}
+}
@@ -0,0 +1,170 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme picks the colors the dungeon is drawn in
+type Theme int
+
+const (
+	ThemeDefault Theme = iota
+	ThemeHighContrast
+	ThemeColorblind
+)
+
+// palette holds the styles a theme draws the map with
+type palette struct {
+	wall, fogWall                 tcell.Style
+	conflictWall, conflictFogWall tcell.Style // Walls once a merge conflict has been triggered
+	code, fog, smell              tcell.Style
+	player, potion, door          tcell.Style
+	lint, ci, portal              tcell.Style
+	enemy                         tcell.Style // Every enemy, unless per-type colors are on
+	mergeAffected                 tcell.Style // Tiles torn apart by a merge conflict
+	ui                            tcell.Style
+	fire                          [3]tcell.Color // Merge conflict fire, rotated each turn
+}
+
+// onBlack is shorthand for a foreground color on the black map background
+func onBlack(fg tcell.Color) tcell.Style {
+	return tcell.StyleDefault.Foreground(fg).Background(tcell.ColorBlack)
+}
+
+var themes = map[Theme]palette{
+	ThemeDefault: {
+		wall:            onBlack(tcell.ColorWhite),
+		fogWall:         onBlack(tcell.Color240),
+		conflictWall:    onBlack(tcell.ColorRed),
+		conflictFogWall: onBlack(tcell.ColorOrange),
+		code:            onBlack(tcell.Color238),
+		fog:             onBlack(tcell.Color240),
+		smell:           onBlack(tcell.ColorOlive),
+		player:          onBlack(tcell.ColorWhite).Bold(true),
+		potion:          onBlack(tcell.ColorWhite),
+		door:            onBlack(tcell.ColorWhite).Bold(true),
+		lint:            onBlack(tcell.ColorYellow),
+		ci:              onBlack(tcell.ColorAqua).Bold(true),
+		portal:          onBlack(tcell.ColorFuchsia).Bold(true),
+		enemy:           DefaultEnemyStyle,
+		mergeAffected:   onBlack(tcell.ColorRed).Bold(true),
+		ui:              onBlack(tcell.ColorLightGreen),
+		fire:            [3]tcell.Color{tcell.ColorRed, tcell.ColorOrange, tcell.ColorYellow},
+	},
+	// Brighter walls and code, and enemies and the door in reverse video
+	ThemeHighContrast: {
+		wall:            onBlack(tcell.ColorWhite).Bold(true),
+		fogWall:         onBlack(tcell.ColorSilver),
+		conflictWall:    onBlack(tcell.ColorYellow).Bold(true),
+		conflictFogWall: onBlack(tcell.ColorOlive),
+		code:            onBlack(tcell.ColorSilver),
+		fog:             onBlack(tcell.ColorGray),
+		smell:           onBlack(tcell.ColorLime),
+		player:          onBlack(tcell.ColorWhite).Bold(true),
+		potion:          onBlack(tcell.ColorWhite).Bold(true),
+		door:            tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite).Bold(true),
+		lint:            onBlack(tcell.ColorYellow).Bold(true),
+		ci:              onBlack(tcell.ColorAqua).Bold(true),
+		portal:          onBlack(tcell.ColorFuchsia).Bold(true),
+		enemy:           tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorRed).Bold(true),
+		mergeAffected:   onBlack(tcell.ColorYellow).Bold(true),
+		ui:              onBlack(tcell.ColorWhite).Bold(true),
+		fire:            [3]tcell.Color{tcell.ColorRed, tcell.ColorYellow, tcell.ColorWhite},
+	},
+	// Blue for merge conflicts and yellow for enemies, which stay apart
+	// without telling red from green
+	ThemeColorblind: {
+		wall:            onBlack(tcell.ColorWhite),
+		fogWall:         onBlack(tcell.Color240),
+		conflictWall:    onBlack(tcell.ColorDeepSkyBlue),
+		conflictFogWall: onBlack(tcell.ColorSteelBlue),
+		code:            onBlack(tcell.Color238),
+		fog:             onBlack(tcell.Color240),
+		smell:           onBlack(tcell.ColorPurple),
+		player:          onBlack(tcell.ColorWhite).Bold(true),
+		potion:          onBlack(tcell.ColorWhite),
+		door:            onBlack(tcell.ColorWhite).Bold(true),
+		lint:            onBlack(tcell.ColorOrange),
+		ci:              onBlack(tcell.ColorAqua).Bold(true),
+		portal:          onBlack(tcell.ColorFuchsia).Bold(true),
+		enemy:           onBlack(tcell.ColorYellow).Bold(true),
+		mergeAffected:   onBlack(tcell.ColorDeepSkyBlue).Bold(true),
+		ui:              onBlack(tcell.ColorLightGreen),
+		fire:            [3]tcell.Color{tcell.ColorBlue, tcell.ColorDeepSkyBlue, tcell.ColorWhite},
+	},
+}
+
+// ParseTheme converts a --theme value into a Theme
+func ParseTheme(s string) (Theme, error) {
+	switch s {
+	case "default":
+		return ThemeDefault, nil
+	case "high-contrast":
+		return ThemeHighContrast, nil
+	case "colorblind":
+		return ThemeColorblind, nil
+	}
+	return ThemeDefault, fmt.Errorf("unknown theme %q (want default, high-contrast or colorblind)", s)
+}
+
+// String returns the theme's name as given to --theme
+func (t Theme) String() string {
+	switch t {
+	case ThemeHighContrast:
+		return "high-contrast"
+	case ThemeColorblind:
+		return "colorblind"
+	}
+	return "default"
+}
+
+// MarshalText saves the theme by name, as in the settings file
+func (t Theme) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText reads a theme saved by name
+func (t *Theme) UnmarshalText(text []byte) error {
+	theme, err := ParseTheme(string(text))
+	if err != nil {
+		return err
+	}
+	*t = theme
+	return nil
+}
+
+// palette returns the theme's styles
+func (t Theme) palette() palette {
+	return themes[t]
+}
+
+// resolveStyle returns the style a cell is put on screen with: the style it
+// was drawn in, or with --no-color the terminal's default colors keeping
+// only its bold, reverse and underline attributes
+func (g *Game) resolveStyle(style tcell.Style) tcell.Style {
+	if !g.noColor {
+		return style
+	}
+	_, _, attrs := style.Decompose()
+	resolved := tcell.StyleDefault.Attributes(attrs &^ tcell.AttrUnderline)
+	if attrs&tcell.AttrUnderline != 0 {
+		resolved = resolved.Underline(true)
+	}
+	return resolved
+}
+
+// wallStyle is how a wall is drawn, in or out of view and before or after
+// a merge conflict turns the level's walls
+func (p palette) wallStyle(visible, conflict bool) tcell.Style {
+	switch {
+	case conflict && visible:
+		return p.conflictWall
+	case conflict:
+		return p.conflictFogWall
+	case visible:
+		return p.wall
+	}
+	return p.fogWall
+}
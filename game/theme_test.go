@@ -0,0 +1,125 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestColorblindThemeSetsEnemiesApartFromWalls(t *testing.T) {
+	theme := ThemeColorblind.palette()
+	enemy, _, _ := theme.enemy.Decompose()
+	for _, conflict := range []bool{false, true} {
+		for _, visible := range []bool{false, true} {
+			wall, _, _ := theme.wallStyle(visible, conflict).Decompose()
+			if wall == enemy {
+				t.Errorf("Walls (visible %v, merge conflict %v) share the enemy color %v", visible, conflict, enemy)
+			}
+		}
+	}
+	for _, fire := range theme.fire {
+		if fire == enemy {
+			t.Errorf("Merge conflict fire shares the enemy color %v", enemy)
+		}
+	}
+	for _, hue := range []tcell.Color{tcell.ColorRed, tcell.ColorGreen} {
+		if enemy == hue {
+			t.Errorf("Colorblind enemies shouldn't be %v", hue)
+		}
+	}
+}
+
+func TestDefaultThemeKeepsClassicLook(t *testing.T) {
+	theme := ThemeDefault.palette()
+	if theme.enemy != DefaultEnemyStyle {
+		t.Error("The default theme should draw enemies in the default enemy style")
+	}
+	if wall, _, _ := theme.wallStyle(true, true).Decompose(); wall != tcell.ColorRed {
+		t.Errorf("Merge conflicts should still turn walls red by default, got %v", wall)
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	for _, theme := range []Theme{ThemeDefault, ThemeHighContrast, ThemeColorblind} {
+		got, err := ParseTheme(theme.String())
+		if err != nil || got != theme {
+			t.Errorf("ParseTheme(%q) = %v, %v", theme.String(), got, err)
+		}
+		if _, ok := themes[theme]; !ok {
+			t.Errorf("Theme %v has no palette", theme)
+		}
+	}
+	if _, err := ParseTheme("neon"); err == nil {
+		t.Error("Unknown themes should be rejected")
+	}
+}
+
+func TestNoColorResolvesEveryStyleToDefaultColors(t *testing.T) {
+	g := &Game{noColor: true}
+	var styles []tcell.Style
+	for _, p := range themes {
+		styles = append(styles, p.wall, p.fogWall, p.conflictWall, p.conflictFogWall, p.code, p.fog, p.smell,
+			p.player, p.potion, p.door, p.lint, p.ci, p.portal, p.enemy, p.mergeAffected, p.ui)
+	}
+	for enemyType := range enemyTypeStyles {
+		styles = append(styles, enemyStyle(enemyType, true))
+	}
+	for _, style := range styles {
+		got := g.resolveStyle(style)
+		fg, bg, _ := got.Decompose()
+		if fg != tcell.ColorDefault || bg != tcell.ColorDefault {
+			t.Errorf("With no color, style %v resolved to %v, want the default colors", style, got)
+		}
+		_, _, wantAttrs := style.Decompose()
+		if _, _, attrs := got.Decompose(); attrs != wantAttrs {
+			t.Errorf("With no color, style %v lost its attributes, got %v", style, got)
+		}
+	}
+
+	if (&Game{}).resolveStyle(DefaultEnemyStyle) != DefaultEnemyStyle {
+		t.Error("Styles should be kept as drawn when color is on")
+	}
+}
+
+func TestNoColorScreenHasNoColors(t *testing.T) {
+	g, err := NewHeadless(WithSeed(7), WithNoColor(true), WithMergeMode(true), WithMergeForce(true))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	g.Play(KeyEvents("llljjjhhh")...)
+	g.render()
+
+	width, height := g.screen.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			ch, _, style, _ := g.screen.GetContent(x, y)
+			if fg, bg, _ := style.Decompose(); fg != tcell.ColorDefault || bg != tcell.ColorDefault {
+				t.Fatalf("%q at (%d, %d) drawn in %v, want the default colors", ch, x, y, style)
+			}
+		}
+	}
+}
+
+func TestNoColorTellsEntitiesFromFloor(t *testing.T) {
+	gs := newTestState(10, 5)
+	gs.Dungeon.CodeFile = &CodeFile{Lines: []string{"func main() { return nil }"}}
+	bug := NewBug(3, 1)
+	gs.Enemies = []*Entity{bug}
+	gs.updateVisibility()
+	g := &Game{state: gs, noColor: true}
+	g.frame = newFrameBuffer(10, 8)
+	g.draw(10, 8)
+	offsetX, offsetY := g.mapOffset(10, 5)
+
+	if ch, _, _, _ := g.frame.GetContent(offsetX+2, offsetY+1); ch != '.' {
+		t.Errorf("Visible floor should be drawn as '.' without color, got %q", ch)
+	}
+	_, _, player, _ := g.frame.GetContent(offsetX+gs.Player.X, offsetY+gs.Player.Y)
+	if _, _, attrs := g.resolveStyle(player).Decompose(); attrs&tcell.AttrBold == 0 {
+		t.Error("The player should stay bold without color")
+	}
+	_, _, enemy, _ := g.frame.GetContent(offsetX+bug.X, offsetY+bug.Y)
+	if _, _, attrs := g.resolveStyle(enemy).Decompose(); attrs&tcell.AttrReverse == 0 {
+		t.Error("Enemies should be drawn in reverse video without color")
+	}
+}
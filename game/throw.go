@@ -0,0 +1,87 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Throwing: each level the player gets ThrowsPerLevel rubber ducks to hurl
+// at enemies down a corridor
+const (
+	ThrowsPerLevel  = 3
+	ThrowRange      = 8
+	ThrowFrameDelay = 30 * time.Millisecond // How long the duck is drawn on each tile it flies over
+	ThrowSymbol     = '*'
+)
+
+// ThrowAttack hurls a rubber duck in a direction. It flies until it hits an
+// enemy, which takes the player's damage, or a wall, or runs out of range.
+// The tiles it flew over are kept in ThrowPath for the animation. It takes a
+// turn. Returns false if there was nothing to throw.
+func (gs *GameState) ThrowAttack(dx, dy int) bool {
+	if gs.Throws == 0 {
+		gs.SetMessage("You're out of rubber ducks to throw.")
+		return false
+	}
+	gs.Throws--
+
+	gs.ThrowPath = nil
+	x, y := gs.Player.X, gs.Player.Y
+	var target *Entity
+	for range ThrowRange {
+		if !gs.Dungeon.IsWalkable(x+dx, y+dy) || gs.Dungeon.IsCornerCut(x, y, dx, dy) {
+			break
+		}
+		x, y = x+dx, y+dy
+		gs.ThrowPath = append(gs.ThrowPath, pathStep{x, y})
+		if target = gs.enemyAt(x, y); target != nil {
+			break
+		}
+	}
+
+	if target == nil {
+		gs.SetMessage("Your rubber duck hits nothing but the floor.")
+	} else {
+		target.TakeDamage(gs.PlayerDamage())
+		if target.IsAlive() {
+			gs.SetMessage(fmt.Sprintf("Your rubber duck hits the %s!", target.Name()))
+		} else {
+			msg, levels := gs.killEnemy(target)
+			gs.SetMessage(msg)
+			gs.announceLevelUp(levels)
+		}
+	}
+
+	gs.countTurn()
+	gs.processTurn()
+	return true
+}
+
+// handleAimKey throws in the direction of the key pressed after 't'. Any
+// other key calls the throw off.
+func (g *Game) handleAimKey(ev *tcell.EventKey) {
+	g.aiming = false
+	dx, dy := g.keys.direction(ev)
+	if (dx == 0 && dy == 0) || (g.state.NoDiagonals && dx != 0 && dy != 0) {
+		g.state.SetMessage("You put the rubber duck away.")
+		return
+	}
+	if g.state.ThrowAttack(dx, dy) {
+		g.animateThrow()
+	}
+}
+
+// animateThrow draws the duck flying over each tile of the last throw
+func (g *Game) animateThrow() {
+	for _, step := range g.state.ThrowPath {
+		g.projectile = &step
+		g.render()
+		if !g.options.headless {
+			g.screen.Show()
+			time.Sleep(ThrowFrameDelay)
+		}
+	}
+	g.projectile = nil
+}
@@ -0,0 +1,78 @@
+package game
+
+import "testing"
+
+func TestThrowHitsNearestEnemy(t *testing.T) {
+	gs := newTestState(12, 5)
+	gs.Throws = 1
+	near := NewScopeCreep(4, 1)
+	far := NewScopeCreep(6, 1)
+	gs.Enemies = []*Entity{far, near}
+
+	if !gs.ThrowAttack(1, 0) {
+		t.Fatal("Expected the throw to happen")
+	}
+	if near.HP != near.MaxHP-gs.PlayerDamage() || far.HP != far.MaxHP {
+		t.Errorf("Expected only the nearest enemy to be hit, got HP %d and %d", near.HP, far.HP)
+	}
+	if len(gs.ThrowPath) != 3 {
+		t.Errorf("Expected the duck to fly over 3 tiles, got %v", gs.ThrowPath)
+	}
+	if gs.Throws != 0 {
+		t.Errorf("Expected the throw to be used up, %d left", gs.Throws)
+	}
+}
+
+func TestThrowStopsAtWall(t *testing.T) {
+	gs := newTestState(12, 5)
+	gs.Throws = 1
+	addWallColumn(gs.Dungeon, 4, 0, 4)
+	bug := NewBug(6, 1)
+	gs.Enemies = []*Entity{bug}
+
+	gs.ThrowAttack(1, 0)
+	if !bug.IsAlive() {
+		t.Error("The wall should have stopped the duck")
+	}
+	if len(gs.ThrowPath) != 2 || gs.ThrowPath[1] != (pathStep{3, 1}) {
+		t.Errorf("Expected the duck to land against the wall at (3, 1), got %v", gs.ThrowPath)
+	}
+}
+
+func TestThrowKillCountsAndNeedsDucks(t *testing.T) {
+	gs := newTestState(12, 5)
+	gs.Throws = 1
+	bug := NewBug(5, 1)
+	gs.Enemies = []*Entity{bug}
+
+	gs.ThrowAttack(1, 0)
+	if bug.IsAlive() || gs.EnemiesKilled != 1 || gs.XP != 1 {
+		t.Errorf("Expected a thrown kill to count, alive=%v kills=%d XP=%d", bug.IsAlive(), gs.EnemiesKilled, gs.XP)
+	}
+	if gs.ThrowAttack(1, 0) {
+		t.Error("Shouldn't be able to throw without any ducks left")
+	}
+}
+
+func TestThrowKeyAimsThenThrows(t *testing.T) {
+	g, err := NewHeadless()
+	if err != nil {
+		t.Fatalf("NewHeadless failed: %v", err)
+	}
+	defer g.Close()
+	g.state = newTestState(20, 10)
+	g.state.Throws = 2
+	x, y := g.state.Player.X, g.state.Player.Y
+
+	g.handleKey(runeKey('t'))
+	g.handleKey(runeKey('l'))
+	if g.state.Throws != 1 || g.state.Player.X != x || g.state.Player.Y != y {
+		t.Errorf("Expected 't' then a direction to throw without moving, %d ducks left", g.state.Throws)
+	}
+
+	g.handleKey(runeKey('t'))
+	g.handleKey(runeKey('x'))
+	if g.state.Throws != 1 {
+		t.Error("Any other key should cancel the throw")
+	}
+}
@@ -0,0 +1,67 @@
+package game
+
+import "testing"
+
+// visibleCount is how many tiles are in view
+func visibleCount(gs *GameState) int {
+	count := 0
+	for _, row := range gs.Visible {
+		for _, visible := range row {
+			if visible {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestTorchWidensVision(t *testing.T) {
+	gs := newTestState(40, 40)
+	gs.Player.X, gs.Player.Y = 19, 20
+	gs.updateVisibility()
+	before := visibleCount(gs)
+
+	gs.Torches = []*Entity{NewTorch(20, 20)}
+	gs.MovePlayer(1, 0)
+	if gs.TorchTurnsLeft != TorchTurns-1 || len(gs.Torches) != 0 {
+		t.Fatalf("Expected the torch to be picked up and burning, %d turns left and %d torches on the floor", gs.TorchTurnsLeft, len(gs.Torches))
+	}
+	gs.updateVisibility()
+	if after := visibleCount(gs); after <= before {
+		t.Errorf("A lit torch should show more tiles: %d before, %d after", before, after)
+	}
+	if !gs.Visible[20][20+DefaultVisionRadius+TorchVisionBonus] {
+		t.Error("A lit torch should extend vision by TorchVisionBonus tiles")
+	}
+}
+
+func TestTorchBurnsOut(t *testing.T) {
+	gs := newTestState(40, 40)
+	gs.TorchTurnsLeft = 2
+	gs.Wait()
+	gs.Wait()
+	if gs.TorchTurnsLeft != 0 || gs.Message != "Your torch burns out." {
+		t.Fatalf("Expected the torch to burn out after 2 turns, %d left with message %q", gs.TorchTurnsLeft, gs.Message)
+	}
+	if got := gs.visionRadius(); got != DefaultVisionRadius {
+		t.Errorf("Vision should be back to %d once the torch is out, got %d", DefaultVisionRadius, got)
+	}
+}
+
+func TestVisionRadiusOption(t *testing.T) {
+	g, err := NewHeadless(WithSeed(7), WithVisionRadius(3))
+	if err != nil {
+		t.Fatalf("NewHeadless: %v", err)
+	}
+	gs := g.State()
+	if gs.VisionRadius != 3 {
+		t.Fatalf("Expected a vision radius of 3, got %d", gs.VisionRadius)
+	}
+	for y, row := range gs.Visible {
+		for x, visible := range row {
+			if visible && !inVisionRadius(x-gs.Player.X, y-gs.Player.Y, 3) {
+				t.Fatalf("(%d, %d) is in view but beyond a vision radius of 3", x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package game
+
+// Tutorial lessons, in the order they are taught. Each is a level of its own.
+const (
+	TutorialMove = iota
+	TutorialCombat
+	TutorialPotion
+	TutorialMerge
+)
+
+// tutorialLesson is one hand-authored tutorial level with its hints: prompt
+// until the player has done what it teaches, then done
+type tutorialLesson struct {
+	prompt string
+	done   string
+	// '@' player start, 'b' bug, '+' potion, '^' hidden merge conflict, '>' door
+	level []string
+}
+
+var tutorialLessons = []tutorialLesson{
+	TutorialMove: {
+		prompt: "TUTORIAL 1/4: Move with the arrow keys, WASD or hjkl (yubn for diagonals).",
+		done:   "TUTORIAL 1/4: That's it! Walk onto the door '>' for the next lesson.",
+		level: []string{
+			"########################",
+			"#......................#",
+			"#..@................>..#",
+			"#......................#",
+			"########################",
+		},
+	},
+	TutorialCombat: {
+		prompt: "TUTORIAL 2/4: A bug 'b'! Walk into it to attack. Adjacent enemies are hit automatically.",
+		done:   "TUTORIAL 2/4: Squashed! Walk onto the door '>' for the next lesson.",
+		level: []string{
+			"########################",
+			"#..........#...........#",
+			"#..@...........b....>..#",
+			"#..........#...........#",
+			"########################",
+		},
+	},
+	TutorialPotion: {
+		prompt: "TUTORIAL 3/4: Walk over the potion '+' to carry it, then press 1 to drink it when you're hurt.",
+		done:   "TUTORIAL 3/4: Got it! Walk onto the door '>' for the next lesson.",
+		level: []string{
+			"########################",
+			"#......................#",
+			"#..@.......+........>..#",
+			"#......................#",
+			"########################",
+		},
+	},
+	TutorialMerge: {
+		prompt: "TUTORIAL 4/4: Merge conflicts hide on the floor. Head east and watch for the warning.",
+		done:   "TUTORIAL 4/4: Tread carefully around that conflict and take the door '>' to finish.",
+		level: []string{
+			"##############################",
+			"#............................#",
+			"#............................#",
+			"#..@.............^.......>...#",
+			"#............................#",
+			"#............................#",
+			"##############################",
+		},
+	},
+}
+
+// loadTutorialLevel replaces level generation with the hand-authored map of
+// the lesson taught on the current level
+func (gs *GameState) loadTutorialLevel() {
+	lesson := min(gs.Level, len(tutorialLessons)) - 1
+	tutorialMap := tutorialLessons[lesson].level
+	height := len(tutorialMap)
+	width := len(tutorialMap[0])
+
+	d := &Dungeon{
+		Width:  width,
+		Height: height,
+		Tiles:  make([][]Tile, height),
+	}
+
+	gs.Enemies = nil
+	gs.Potions = nil
+	gs.MergeMarkerX, gs.MergeMarkerY = -1, -1
+	gs.MergeAffectedTiles = make(map[[2]int]bool)
+	gs.MergeConflictX, gs.MergeConflictY = -1, -1
+
+	for y, row := range tutorialMap {
+		d.Tiles[y] = make([]Tile, width)
+		for x, ch := range row {
+			d.Tiles[y][x] = TileFloor
+			switch ch {
+			case '#':
+				d.Tiles[y][x] = TileWall
+			case '@':
+				if gs.Player == nil {
+					gs.Player = gs.newPlayer(x, y)
+				} else {
+					gs.Player.X, gs.Player.Y = x, y
+				}
+			case 'b':
+				gs.Enemies = append(gs.Enemies, NewBug(x, y))
+			case '+':
+				gs.Potions = append(gs.Potions, NewPotion(x, y))
+			case '^':
+				gs.MergeConflictX, gs.MergeConflictY = x, y
+			case '>':
+				d.Tiles[y][x] = TileDoor
+				gs.DoorX, gs.DoorY = x, y
+			}
+		}
+	}
+
+	// Rooms are the open areas between the dividing walls
+	for x := 1; x < width-1; {
+		end := x
+		for end < width-1 && d.Tiles[1][end] != TileWall {
+			end++
+		}
+		d.Rooms = append(d.Rooms, &Room{X: x, Y: 1, W: end - x, H: height - 2})
+		x = end + 1
+	}
+
+	gs.Dungeon = d
+	gs.LevelFileName = ""
+	gs.Visible = make([][]bool, height)
+	gs.Explored = make([][]bool, height)
+	for y := 0; y < height; y++ {
+		gs.Visible[y] = make([]bool, width)
+		gs.Explored[y] = make([]bool, width)
+	}
+
+	gs.TutorialStep = lesson
+	gs.TutorialLessonDone = false
+	gs.updateVisibility()
+	gs.SetMessage("")
+}
+
+// TutorialPrompt returns the hint for the current tutorial lesson, or "" outside the tutorial
+func (gs *GameState) TutorialPrompt() string {
+	if !gs.Tutorial || gs.TutorialStep >= len(tutorialLessons) {
+		return ""
+	}
+	if gs.TutorialLessonDone {
+		return tutorialLessons[gs.TutorialStep].done
+	}
+	return tutorialLessons[gs.TutorialStep].prompt
+}
+
+// updateTutorial marks the current lesson done once the player has performed
+// the action it teaches, which opens the door to the next one
+func (gs *GameState) updateTutorial() {
+	if !gs.Tutorial || gs.TutorialLessonDone {
+		return
+	}
+
+	switch gs.TutorialStep {
+	case TutorialMove:
+		gs.TutorialLessonDone = gs.MoveCount > 0
+	case TutorialCombat:
+		gs.TutorialLessonDone = gs.EnemiesKilled > 0
+	case TutorialPotion:
+		gs.TutorialLessonDone = len(gs.Potions) == 0
+	case TutorialMerge:
+		gs.TutorialLessonDone = gs.distanceToMergeConflict() <= 2
+	}
+}
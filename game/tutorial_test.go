@@ -0,0 +1,97 @@
+package game
+
+import "testing"
+
+func newTutorialState() *GameState {
+	return NewGameState(nil, 42, 80, 40, func(gs *GameState) {
+		gs.Tutorial = true
+	})
+}
+
+func TestTutorialLoadsHandAuthoredLevel(t *testing.T) {
+	gs := newTutorialState()
+
+	level := tutorialLessons[TutorialMove].level
+	if gs.Dungeon.Width != len(level[0]) || gs.Dungeon.Height != len(level) {
+		t.Errorf("Expected tutorial map size %dx%d, got %dx%d", len(level[0]), len(level), gs.Dungeon.Width, gs.Dungeon.Height)
+	}
+	if gs.MaxLevel != len(tutorialLessons) {
+		t.Errorf("Expected a level per lesson, got %d levels", gs.MaxLevel)
+	}
+	if gs.TutorialStep != TutorialMove {
+		t.Errorf("Tutorial should start with the movement lesson, got step %d", gs.TutorialStep)
+	}
+	if gs.TutorialPrompt() != tutorialLessons[TutorialMove].prompt {
+		t.Errorf("Tutorial should show the movement prompt, got %q", gs.TutorialPrompt())
+	}
+}
+
+func TestTutorialAdvancesOnMove(t *testing.T) {
+	gs := newTutorialState()
+
+	gs.MovePlayer(0, 1)
+	if !gs.TutorialLessonDone || gs.TutorialPrompt() != tutorialLessons[TutorialMove].done {
+		t.Errorf("Moving should finish the movement lesson, got prompt %q", gs.TutorialPrompt())
+	}
+}
+
+func TestTutorialKillAdvancesCombatStep(t *testing.T) {
+	gs := newTutorialState()
+	gs.Level = TutorialCombat + 1
+	gs.generateLevel()
+	if gs.TutorialStep != TutorialCombat || len(gs.Enemies) != 1 {
+		t.Fatalf("Level 2 should be the combat lesson with a bug, got step %d with %d enemies", gs.TutorialStep, len(gs.Enemies))
+	}
+	bug := gs.Enemies[0]
+
+	// Moving without killing doesn't finish the lesson
+	gs.MovePlayer(0, 1)
+	if gs.TutorialLessonDone {
+		t.Fatal("Combat lesson should wait for a kill")
+	}
+
+	gs.Player.X, gs.Player.Y = bug.X-1, bug.Y
+	gs.MovePlayer(1, 0)
+	if bug.IsAlive() {
+		t.Fatal("Bump attack should kill the bug")
+	}
+	if !gs.TutorialLessonDone {
+		t.Error("A kill should finish the combat lesson")
+	}
+}
+
+func TestTutorialDoorWaitsForLesson(t *testing.T) {
+	gs := newTutorialState()
+	gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+	gs.TutorialLessonDone = false
+
+	gs.takeDoor()
+	if gs.Level != 1 {
+		t.Fatalf("The door shouldn't lead on before the lesson is done, now on level %d", gs.Level)
+	}
+
+	gs.TutorialLessonDone = true
+	gs.takeDoor()
+	if gs.Level != 2 || gs.TutorialStep != TutorialCombat || gs.TutorialLessonDone || gs.ShopOpen {
+		t.Errorf("Expected the combat lesson next with no shop, got level %d step %d done %v shop %v",
+			gs.Level, gs.TutorialStep, gs.TutorialLessonDone, gs.ShopOpen)
+	}
+}
+
+func TestTutorialTeachesLessonsInOrder(t *testing.T) {
+	gs := newTutorialState()
+	gs.Invulnerable = true
+
+	for lesson := range tutorialLessons {
+		if gs.TutorialStep != lesson {
+			t.Fatalf("Expected lesson %d, got %d", lesson, gs.TutorialStep)
+		}
+		gs.TutorialLessonDone = true
+		gs.Enemies = nil
+		gs.Player.X, gs.Player.Y = gs.DoorX-1, gs.DoorY
+		gs.MovePlayer(1, 0)
+	}
+	if !gs.Victory {
+		t.Error("Finishing the last lesson's door should finish the tutorial")
+	}
+}
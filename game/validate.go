@@ -0,0 +1,115 @@
+package game
+
+import "fmt"
+
+// ValidationFailure describes a generated level that broke an invariant
+type ValidationFailure struct {
+	Seed     int64
+	Level    int
+	Problems []string
+}
+
+func (f ValidationFailure) String() string {
+	return fmt.Sprintf("seed %d level %d: %v", f.Seed, f.Level, f.Problems)
+}
+
+// ValidateSeeds generates every level for each seed at the given terminal size
+// and returns the levels that break a generator invariant
+func ValidateSeeds(seeds []int64, termWidth, termHeight int, opts ...StateOption) []ValidationFailure {
+	var failures []ValidationFailure
+	for _, seed := range seeds {
+		gs := NewGameState(nil, seed, termWidth, termHeight, opts...)
+		for {
+			if problems := gs.ValidateLevel(); len(problems) > 0 {
+				failures = append(failures, ValidationFailure{Seed: seed, Level: gs.Level, Problems: problems})
+			}
+			if gs.Level >= gs.MaxLevel {
+				break
+			}
+			gs.Level++
+			gs.generateLevel()
+		}
+	}
+	return failures
+}
+
+// ValidateLevel checks the current level's invariants: the player starts on a
+// walkable tile, and every room, the door and the merge trap can be reached
+// from there. It returns a description of each problem found.
+func (gs *GameState) ValidateLevel() []string {
+	var problems []string
+	d := gs.Dungeon
+
+	if !d.IsWalkable(gs.Player.X, gs.Player.Y) {
+		problems = append(problems, fmt.Sprintf("player start (%d, %d) is not walkable", gs.Player.X, gs.Player.Y))
+		return problems
+	}
+
+	reachable := d.reachableFrom(gs.Player.X, gs.Player.Y)
+
+	for i, room := range d.Rooms {
+		x, y := room.Center()
+		if !reachable[[2]int{x, y}] {
+			problems = append(problems, fmt.Sprintf("room %d at (%d, %d) is unreachable", i, x, y))
+		}
+	}
+	if !reachable[[2]int{gs.DoorX, gs.DoorY}] {
+		problems = append(problems, fmt.Sprintf("door (%d, %d) is unreachable", gs.DoorX, gs.DoorY))
+	}
+	if !d.IsWalkable(gs.MergeConflictX, gs.MergeConflictY) {
+		problems = append(problems, fmt.Sprintf("merge trap (%d, %d) is not walkable", gs.MergeConflictX, gs.MergeConflictY))
+	} else if !reachable[[2]int{gs.MergeConflictX, gs.MergeConflictY}] {
+		problems = append(problems, fmt.Sprintf("merge trap (%d, %d) is unreachable", gs.MergeConflictX, gs.MergeConflictY))
+	}
+
+	return problems
+}
+
+// Reachable reports whether (toX, toY) can be walked to from (fromX, fromY)
+func (d *Dungeon) Reachable(fromX, fromY, toX, toY int) bool {
+	return d.reachableFrom(fromX, fromY)[[2]int{toX, toY}]
+}
+
+// ensureReachable is the last step of generating a level: it checks the door,
+// the merge trap and everything spawned can be walked to from the player's
+// start, and carves a corridor from the start to anything cut off. Corridors
+// between rooms should already join everything, so this is a safety net.
+func (gs *GameState) ensureReachable() {
+	targets := [][2]int{{gs.DoorX, gs.DoorY}, {gs.MergeConflictX, gs.MergeConflictY}}
+	for _, entities := range [][]*Entity{gs.Enemies, gs.Potions, gs.Reverts, gs.Hotfixes, gs.Armor, gs.Torches, gs.Weapons} {
+		for _, e := range entities {
+			targets = append(targets, [2]int{e.X, e.Y})
+		}
+	}
+	if gs.Player2 != nil {
+		targets = append(targets, [2]int{gs.Player2.X, gs.Player2.Y})
+	}
+
+	d := gs.Dungeon
+	reachable := d.reachableFrom(gs.Player.X, gs.Player.Y)
+	for _, t := range targets {
+		if reachable[t] || t[0] < 0 || t[1] < 0 {
+			continue
+		}
+		d.carvePath(gs.Player.X, gs.Player.Y, t[0], t[1])
+		reachable = d.reachableFrom(gs.Player.X, gs.Player.Y)
+	}
+}
+
+// reachableFrom flood-fills the walkable tiles connected to (x, y) by cardinal steps
+func (d *Dungeon) reachableFrom(x, y int) map[[2]int]bool {
+	seen := make(map[[2]int]bool)
+	stack := [][2]int{{x, y}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[p] || !d.IsWalkable(p[0], p[1]) {
+			continue
+		}
+		seen[p] = true
+		for _, dir := range [][2]int{{0, 1}, {0, -1}, {1, 0}, {-1, 0}} {
+			stack = append(stack, [2]int{p[0] + dir[0], p[1] + dir[1]})
+		}
+	}
+	return seen
+}
@@ -0,0 +1,87 @@
+package game
+
+import "testing"
+
+func TestValidateSeedsPassesGeneratedDungeons(t *testing.T) {
+	seeds := []int64{1, 42, 12345, 99999, 2024}
+	if failures := ValidateSeeds(seeds, 80, 24); len(failures) > 0 {
+		t.Errorf("Expected generated dungeons to be valid, got %v", failures)
+	}
+}
+
+func TestValidateLevelFlagsUnreachableDoor(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Dungeon.Rooms = []*Room{{X: 1, Y: 1, W: 5, H: 5}}
+	gs.MergeConflictX, gs.MergeConflictY = 3, 3
+	gs.DoorX, gs.DoorY = 15, 5
+
+	if problems := gs.ValidateLevel(); len(problems) != 0 {
+		t.Fatalf("Open level should be valid, got %v", problems)
+	}
+
+	// Wall the door off from the rest of the level
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+	problems := gs.ValidateLevel()
+	if len(problems) != 1 {
+		t.Fatalf("Expected one problem for the walled-off door, got %v", problems)
+	}
+}
+
+func TestValidateLevelFlagsBrokenDungeon(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.Dungeon.Rooms = []*Room{{X: 1, Y: 1, W: 5, H: 5}, {X: 12, Y: 2, W: 5, H: 5}}
+	gs.DoorX, gs.DoorY = 14, 4
+	gs.MergeConflictX, gs.MergeConflictY = 5, 5
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+	gs.Dungeon.Tiles[5][5] = TileWall
+
+	problems := gs.ValidateLevel()
+	// unreachable room, unreachable door and unwalkable merge trap
+	if len(problems) != 3 {
+		t.Errorf("Expected 3 problems, got %v", problems)
+	}
+
+	gs.Dungeon.Tiles[gs.Player.Y][gs.Player.X] = TileWall
+	if problems := gs.ValidateLevel(); len(problems) != 1 {
+		t.Errorf("A player starting in a wall should be reported on its own, got %v", problems)
+	}
+}
+
+func TestDoorAlwaysReachableFromStartRoom(t *testing.T) {
+	for _, size := range [][2]int{{MinDungeonWidth, MinDungeonHeight + 3}, {80, 24}} {
+		for seed := int64(1); seed <= 150; seed++ {
+			gs := NewGameState(nil, seed, size[0], size[1])
+			for {
+				x, y := gs.Dungeon.StartRoom().Center()
+				if !gs.Dungeon.Reachable(x, y, gs.DoorX, gs.DoorY) {
+					t.Errorf("%dx%d seed %d level %d: door (%d, %d) can't be reached from the start room",
+						size[0], size[1], seed, gs.Level, gs.DoorX, gs.DoorY)
+				}
+				if gs.Level >= gs.MaxLevel {
+					break
+				}
+				gs.Level++
+				gs.generateLevel()
+			}
+		}
+	}
+}
+
+func TestEnsureReachableCarvesToCutOffDoor(t *testing.T) {
+	gs := newTestState(20, 10)
+	gs.DoorX, gs.DoorY = 15, 5
+	gs.Dungeon.Tiles[5][15] = TileDoor
+	gs.Enemies = []*Entity{NewBug(17, 2)}
+	addWallColumn(gs.Dungeon, 10, 0, 9)
+
+	gs.ensureReachable()
+	if !gs.Dungeon.Reachable(gs.Player.X, gs.Player.Y, gs.DoorX, gs.DoorY) {
+		t.Error("A corridor should have been carved to the walled-off door")
+	}
+	if !gs.Dungeon.Reachable(gs.Player.X, gs.Player.Y, 17, 2) {
+		t.Error("The enemy behind the wall should be reachable too")
+	}
+	if gs.Dungeon.Tiles[5][15] != TileDoor {
+		t.Error("Carving a corridor to the door shouldn't remove it")
+	}
+}
@@ -0,0 +1,68 @@
+package game
+
+import "fmt"
+
+// WeaponChance is the chance a level holds a weapon
+const WeaponChance = 0.1
+
+// WeaponSymbol is how a weapon lying on the floor is drawn
+const WeaponSymbol = ')'
+
+// Weapon adds its Bonus to the damage of every attack the player makes
+type Weapon struct {
+	Name  string
+	Bonus int
+}
+
+// Weapons found in the dungeon, from the first levels' to the deepest
+var Weapons = []Weapon{
+	{Name: "Linter", Bonus: 2},
+	{Name: "Debugger", Bonus: 3},
+	{Name: "Static Analyzer", Bonus: 4},
+}
+
+// LevelsPerWeapon is how many levels each weapon in Weapons turns up on
+// before the next one takes over
+const LevelsPerWeapon = 2
+
+// weaponForLevel is the weapon found on a level; deeper levels have better ones
+func weaponForLevel(level int) Weapon {
+	return Weapons[min(max(level-1, 0)/LevelsPerWeapon, len(Weapons)-1)]
+}
+
+// NewWeapon creates a weapon lying on the floor
+func NewWeapon(x, y int, weapon Weapon) *Entity {
+	return &Entity{
+		Type:   EntityWeapon,
+		X:      x,
+		Y:      y,
+		Symbol: WeaponSymbol,
+		Weapon: &weapon,
+	}
+}
+
+// PlayerDamage is how much damage one of the player's attacks deals,
+// including the equipped weapon's bonus
+func (gs *GameState) PlayerDamage() int {
+	if gs.EquippedWeapon == nil {
+		return gs.Player.Damage
+	}
+	return gs.Player.Damage + gs.EquippedWeapon.Bonus
+}
+
+// pickUpWeapon equips a weapon the player walks over, dropping the one they
+// were holding in its place
+func (gs *GameState) pickUpWeapon(x, y int) {
+	for i, item := range gs.Weapons {
+		if item.X != x || item.Y != y {
+			continue
+		}
+		gs.Weapons = append(gs.Weapons[:i], gs.Weapons[i+1:]...)
+		if old := gs.EquippedWeapon; old != nil {
+			gs.Weapons = append(gs.Weapons, NewWeapon(x, y, *old))
+		}
+		gs.EquippedWeapon = item.Weapon
+		gs.SetMessage(fmt.Sprintf("You wield the %s! Attacks now do %d damage.", item.Weapon.Name, gs.PlayerDamage()))
+		return
+	}
+}
@@ -0,0 +1,52 @@
+package game
+
+import "testing"
+
+func TestWeaponAddsToDamage(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Weapons = []*Entity{NewWeapon(2, 1, Weapon{Name: "Linter", Bonus: 2})}
+	if got := gs.PlayerDamage(); got != 2 {
+		t.Fatalf("Bare hands should do the player's 2 damage, got %d", got)
+	}
+
+	gs.MovePlayer(1, 0)
+	if gs.EquippedWeapon == nil || gs.EquippedWeapon.Name != "Linter" || len(gs.Weapons) != 0 {
+		t.Fatalf("Walking over the Linter should equip it, got %+v with %d on the floor", gs.EquippedWeapon, len(gs.Weapons))
+	}
+	if got := gs.PlayerDamage(); got != 4 {
+		t.Errorf("The Linter should add 2 damage, got %d", got)
+	}
+
+	monolith := NewMonolith(3, 1)
+	hp := monolith.HP
+	gs.Enemies = []*Entity{monolith}
+	gs.MovePlayer(1, 0)
+	if dealt := hp - monolith.HP; dealt != 4 {
+		t.Errorf("An attack with the Linter should deal 4 damage, dealt %d", dealt)
+	}
+}
+
+func TestSwappingWeaponsDropsTheOldOne(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.EquippedWeapon = &Weapon{Name: "Linter", Bonus: 2}
+	gs.Weapons = []*Entity{NewWeapon(2, 1, Weapon{Name: "Debugger", Bonus: 3})}
+
+	gs.MovePlayer(1, 0)
+	if gs.EquippedWeapon.Name != "Debugger" || gs.PlayerDamage() != 5 {
+		t.Errorf("Expected the Debugger equipped for 5 damage, got %s for %d", gs.EquippedWeapon.Name, gs.PlayerDamage())
+	}
+	if len(gs.Weapons) != 1 || gs.Weapons[0].Name() != "Linter" || gs.Weapons[0].X != 2 || gs.Weapons[0].Y != 1 {
+		t.Errorf("The Linter should be left where the Debugger was, got %+v", gs.Weapons)
+	}
+}
+
+func TestDeeperLevelsHaveBetterWeapons(t *testing.T) {
+	for level := 2; level <= 10; level++ {
+		if weaponForLevel(level).Bonus < weaponForLevel(level-1).Bonus {
+			t.Errorf("Level %d's weapon is worse than level %d's", level, level-1)
+		}
+	}
+	if weaponForLevel(1).Name != "Linter" {
+		t.Errorf("The first levels should have the Linter, got %s", weaponForLevel(1).Name)
+	}
+}
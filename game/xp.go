@@ -0,0 +1,62 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Experience: every kill is worth XP by the kind of enemy (1 for a bug, 3 for
+// a scope creep), and every XPPerLevel XP levels the player up
+const (
+	XPPerLevel    = 10
+	LevelUpMaxHP  = 5 // Max HP gained per level, healed straight away
+	LevelUpDamage = 1 // Damage gained per level
+)
+
+// enemyXP is how much XP killing an enemy is worth. It goes by the enemy's
+// kind, matching its usual max HP, so enemies toughened by the difficulty or
+// tech debt aren't worth more.
+func enemyXP(enemy *Entity) int {
+	switch enemy.Type {
+	case EntityScopeCreep, EntityMergedBug:
+		return 3
+	case EntityFlakyTest, EntityBrowserTest, EntityRebase, EntityConflictingCommit:
+		return 2
+	case EntityMonolith:
+		return 10
+	}
+	return 1
+}
+
+// PlayerLevel is the player's experience level, starting at 1. It's separate
+// from the dungeon level.
+func (gs *GameState) PlayerLevel() int {
+	return 1 + gs.XP/XPPerLevel
+}
+
+// gainXP awards XP for a kill and levels up every living player for each
+// threshold crossed. Returns how many levels were gained.
+func (gs *GameState) gainXP(xp int) int {
+	before := gs.PlayerLevel()
+	gs.XP += xp
+	levels := gs.PlayerLevel() - before
+	for range levels {
+		for _, p := range gs.livingPlayers() {
+			p.MaxHP += LevelUpMaxHP
+			p.Heal(LevelUpMaxHP)
+			p.Damage += LevelUpDamage
+		}
+	}
+	return levels
+}
+
+// announceLevelUp tells the player they levelled up, if they did
+func (gs *GameState) announceLevelUp(levels int) {
+	if levels == 0 {
+		return
+	}
+	gs.SetAlert(fmt.Sprintf("LEVEL UP! You're now level %d (+%d max HP, +%d damage).",
+		gs.PlayerLevel(), levels*LevelUpMaxHP, levels*LevelUpDamage),
+		tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack).Bold(true))
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKillsAccrueXP(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Enemies = []*Entity{NewBug(2, 1), NewScopeCreep(1, 3)}
+
+	gs.MovePlayer(1, 0) // Bump the bug to death
+	if gs.XP != 1 {
+		t.Fatalf("A bug should be worth 1 XP, got %d", gs.XP)
+	}
+
+	// Auto-attack finishes off the scope creep next to the player
+	gs.MovePlayer(0, 1)
+	for turn := 0; turn < 3 && gs.Enemies[1].IsAlive(); turn++ {
+		gs.playerAutoAttack()
+	}
+	if gs.XP != 4 {
+		t.Errorf("A scope creep should be worth 3 XP, got %d total", gs.XP)
+	}
+}
+
+func TestXPIgnoresDifficultyToughening(t *testing.T) {
+	creep := NewScopeCreep(1, 1)
+	DifficultyHard.toughen(creep)
+	if xp := enemyXP(creep); xp != 3 {
+		t.Errorf("A hard scope creep should still be worth 3 XP, got %d", xp)
+	}
+}
+
+func TestLevelUpOncePerThreshold(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player.HP = 10
+	gs.XP = XPPerLevel - 2
+
+	if levels := gs.gainXP(1); levels != 0 || gs.PlayerLevel() != 1 {
+		t.Fatalf("Expected no level up short of the threshold, got %d levels", levels)
+	}
+	if levels := gs.gainXP(3); levels != 1 || gs.PlayerLevel() != 2 {
+		t.Fatalf("Expected one level up crossing the threshold, got %d levels (level %d)", levels, gs.PlayerLevel())
+	}
+	if gs.Player.MaxHP != 20+LevelUpMaxHP || gs.Player.HP != 10+LevelUpMaxHP || gs.Player.Damage != 2+LevelUpDamage {
+		t.Errorf("Unexpected stats after one level up: HP %d/%d, damage %d", gs.Player.HP, gs.Player.MaxHP, gs.Player.Damage)
+	}
+
+	if levels := gs.gainXP(1); levels != 0 || gs.Player.Damage != 2+LevelUpDamage {
+		t.Error("Further XP short of the next threshold shouldn't level up again")
+	}
+}
+
+func TestLevelUpSkipsFallenPlayer(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.Player2 = NewPlayer(5, 5)
+	gs.Player2.HP = 0
+	gs.XP = XPPerLevel - 1
+
+	if levels := gs.gainXP(1); levels != 1 {
+		t.Fatalf("Expected one level up, got %d", levels)
+	}
+	if gs.Player2.IsAlive() || gs.Player2.MaxHP != 20 || gs.Player2.Damage != 2 {
+		t.Errorf("A fallen player shouldn't level up, got HP %d/%d and damage %d", gs.Player2.HP, gs.Player2.MaxHP, gs.Player2.Damage)
+	}
+	if gs.Player.MaxHP != 20+LevelUpMaxHP || gs.Player.Damage != 2+LevelUpDamage {
+		t.Errorf("The surviving player should still level up, got %d max HP and %d damage", gs.Player.MaxHP, gs.Player.Damage)
+	}
+}
+
+func TestBumpKillAnnouncesLevelUp(t *testing.T) {
+	gs := newTestState(10, 10)
+	gs.XP = XPPerLevel - 1
+	gs.Enemies = []*Entity{NewBug(2, 1)}
+
+	gs.MovePlayer(1, 0)
+	if !strings.HasPrefix(gs.Message, "LEVEL UP!") {
+		t.Errorf("Expected a level up message, got %q", gs.Message)
+	}
+	if gs.Player.Damage != 2+LevelUpDamage {
+		t.Errorf("Expected damage %d after levelling up, got %d", 2+LevelUpDamage, gs.Player.Damage)
+	}
+}
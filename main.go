@@ -1,31 +1,397 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/leereilly/gh-dungeons/game"
 )
 
 func main() {
-	// Check for --merge flag
-	mergeMode := false
-	for _, arg := range os.Args[1:] {
-		if arg == "--merge" {
-			mergeMode = true
-			break
+	mergeMode := flag.Bool("merge", false, "show merge conflicts from the repository in the dungeon")
+	mergeForce := flag.Bool("merge-force", false, "with --merge, show the merge marker even if no merge conflict is found")
+	noDiagonals := flag.Bool("no-diagonals", false, "disable diagonal movement for the player and enemies")
+	cornerCutting := flag.Bool("corner-cutting", false, "let the player and enemies step diagonally between two wall corners")
+	persistentEnemies := flag.Bool("persistent-enemies", false, "alerted enemies keep hunting you after losing sight of you")
+	astar := flag.Bool("astar", false, "enemies that can see you find their way around walls with A* pathfinding instead of stepping straight at you")
+	levelSummary := flag.Bool("level-summary", false, "show a summary of each level's kills, potions, turns and damage when you descend")
+	decay := flag.Int("decay", 0, "forget explored areas once they've been out of sight for `N` turns (0 = never)")
+	vision := flag.Int("vision", game.DefaultVisionRadius, "how far you can see, in `tiles`")
+	codeSmells := flag.Bool("code-smells", false, "fill some rooms with a code smell haze that cuts your vision until you clean them up")
+	leash := flag.Int("leash", 0, "enemies that chase you more than `N` tiles from where they spotted you give up and go back (0 = never)")
+	mergeQueue := flag.Bool("merge-queue", false, "triggering the merge marker spawns a wave of conflicting commits")
+	mergeFire := flag.Bool("merge-fire", false, "every merge conflict fire tile burns you, not just the trap center")
+	ciTraps := flag.Bool("ci-traps", false, "hide CI traps that reshuffle the enemies and blind you for a turn on some levels")
+	crowdBlocksSight := flag.Bool("crowd-blocks-sight", false, "enemies can't see you through other enemies")
+	mergeCooldown := flag.Int("merge-cooldown", 0, "tiles torn apart by a merge conflict heal back to floor after `N` turns off the marker (0 = never)")
+	mergeFireChase := flag.Bool("merge-fire-chase", false, "merge conflict fire keeps spreading toward you")
+	rollback := flag.Bool("rollback", false, "once per run, roll back to the start of the level when you die")
+	coop := flag.Bool("coop", false, "pair programming: a second local player moves with WASD")
+	difficulty := flag.String("difficulty", "normal", "`level` of challenge: easy (more HP and potions, fewer enemies), normal or hard (more, tougher enemies and fewer potions)")
+	endless := flag.Bool("endless", false, "keep descending past the final level with escalating difficulty")
+	roomErosion := flag.Float64("room-erosion", 0, "chance (0-1) of roughening each wall tile around a room")
+	continueGame := flag.Bool("continue", false, "pick up the game you last saved with S")
+	tutorial := flag.Bool("tutorial", false, "learn the ropes in a few short hand-made tutorial levels")
+	rememberMap := flag.Bool("remember-map", false, "remember explored areas between runs of the same repository")
+	ascii := flag.Bool("ascii", false, "draw with plain ASCII only, for terminals or fonts without box-drawing characters")
+	fullClear := flag.Bool("full-clear", false, "redraw the whole screen every frame (if the diff-based refresh leaves artifacts)")
+	enemyColors := flag.Bool("enemy-colors", false, "draw each enemy type in its own color instead of all in red")
+	avatar := flag.String("avatar", "@", "single character to play as")
+	noColor := flag.Bool("no-color", os.Getenv("NO_COLOR") != "", "draw without colors, for terminals and logs that can't show them (default on when NO_COLOR is set)")
+	screenReader := flag.Bool("screen-reader", false, "describe your surroundings in lines of text each turn instead of drawing the map")
+	theme := flag.String("theme", "default", "color `theme`: default, high-contrast, or colorblind (merge conflicts in blue, enemies in yellow)")
+	color := flag.String("color", "white", "player color, as a name (e.g. green) or hex value (e.g. #ff8800)")
+	demo := flag.Bool("demo", false, "watch the computer play as an attract loop")
+	levels := flag.Int("levels", game.DefaultMaxLevel, "how many levels deep a run goes, with the boss on the last one")
+	startLevel := flag.Int("start-level", 1, "begin the run at level `N` (for practice)")
+	seed := flag.Int64("seed", 0, "play the run generated from `N` instead of the seed computed from the repository (shown in the status bar)")
+	levelCode := flag.String("level-code", "", "replay the level a shared `code` points to (press I in game to see one)")
+	noTTY := flag.Bool("no-tty", false, "run without a terminal: the computer plays one run and the final screen is printed")
+	dump := flag.Bool("dump", false, "print the first level as text and exit (works without a terminal)")
+	dumpLevel := flag.Bool("dump-level", false, "print the first level's map as plain ASCII, with no fog or status bar, and exit (for bug reports)")
+	scanOrder := flag.String("scan-order", "lines", "pick level code files by `order`: lines (longest first) or recent (recently modified first)")
+	rngName := flag.String("rng", "stdlib", "random `source` for dungeons: stdlib, or xorshift for identical dungeons on any Go version")
+	validate := flag.Int("validate", 0, "generate `N` dungeons from random seeds, check them for generator bugs and exit")
+	compare := flag.String("compare", "", "print the first level of `seedA` and seedB (the next argument) side by side and exit")
+	messageTurns := flag.Int("message-turns", game.DefaultMessageTurns, "keep each message up for at least `N` turns before showing the next")
+	potionBudget := flag.Int("potion-budget", 0, "limit the total potions available across the whole run (0 = unlimited)")
+	potionHealPercent := flag.Int("potion-heal-percent", 0, "potions heal `N` percent of max HP instead of a flat 3 HP (0 = flat)")
+	potionTiers := flag.Bool("potion-tiers", false, "potions come in big and huge sizes as well as small")
+	flag.Parse()
+
+	if *potionBudget < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --potion-budget must not be negative")
+		os.Exit(2)
+	}
+	if *mergeCooldown < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --merge-cooldown must not be negative")
+		os.Exit(2)
+	}
+	if *leash < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --leash must not be negative")
+		os.Exit(2)
+	}
+	if *potionHealPercent < 0 || *potionHealPercent > 100 {
+		fmt.Fprintln(os.Stderr, "Error: --potion-heal-percent must be between 0 and 100")
+		os.Exit(2)
+	}
+	if *decay < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --decay must not be negative")
+		os.Exit(2)
+	}
+	if *vision < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --vision must be at least 1")
+		os.Exit(2)
+	}
+	if *roomErosion < 0 || *roomErosion > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --room-erosion must be between 0 and 1")
+		os.Exit(2)
+	}
+	if *messageTurns < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --message-turns must be at least 1")
+		os.Exit(2)
+	}
+	if *levels < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --levels must be at least 1")
+		os.Exit(2)
+	}
+	if *startLevel < 1 || *startLevel > *levels {
+		fmt.Fprintf(os.Stderr, "Error: --start-level must be between 1 and %d\n", *levels)
+		os.Exit(2)
+	}
+
+	// An optional directory to build the dungeon from instead of the current one
+	sourceDir := ""
+	if *compare == "" {
+		if flag.NArg() > 1 {
+			fmt.Fprintf(os.Stderr, "Error: expected at most one source directory, got %d\n", flag.NArg())
+			os.Exit(2)
+		}
+		sourceDir = flag.Arg(0)
+		if sourceDir != "" {
+			if err := game.CheckSourceDir(sourceDir); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
+			}
 		}
 	}
 
-	g, err := game.New(game.WithMergeMode(mergeMode))
+	rngAlgorithm, err := game.ParseRNGAlgorithm(*rngName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --rng: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *validate > 0 {
+		os.Exit(runValidation(*validate, *roomErosion, rngAlgorithm))
+	}
+	if *compare != "" {
+		os.Exit(runCompare(*compare, flag.Arg(0), *roomErosion, rngAlgorithm))
+	}
+
+	order, err := game.ParseScanOrder(*scanOrder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --scan-order: %v\n", err)
+		os.Exit(2)
+	}
+	difficultyLevel, err := game.ParseDifficulty(*difficulty)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --difficulty: %v\n", err)
+		os.Exit(2)
+	}
+	avatarSymbol, err := game.ParseAvatar(*avatar)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --avatar: %v\n", err)
+		os.Exit(2)
+	}
+	themeChoice, err := game.ParseTheme(*theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --theme: %v\n", err)
+		os.Exit(2)
+	}
+	playerColor, err := game.ParseColor(*color)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --color: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Flags given explicitly, as opposed to left at their defaults
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+	seedSet := setFlags["seed"]
+	if seedSet && *levelCode != "" {
+		fmt.Fprintln(os.Stderr, "Error: --seed and --level-code both pick the seed; use one or the other")
+		os.Exit(2)
+	}
+
+	if *continueGame && (seedSet || *levelCode != "") {
+		fmt.Fprintln(os.Stderr, "Error: --continue resumes the saved run's seed; it can't be combined with --seed or --level-code")
+		os.Exit(2)
+	}
+	// The rest of what shapes a run is fixed once it has started
+	for _, name := range []string{"rng", "levels", "start-level", "tutorial", "coop", "rollback"} {
+		if *continueGame && setFlags[name] {
+			fmt.Fprintf(os.Stderr, "Error: --continue resumes the saved run as it started; it can't be combined with --%s\n", name)
+			os.Exit(2)
+		}
+	}
+
+	var code game.LevelCode
+	if *levelCode != "" {
+		code, err = game.ParseLevelCode(*levelCode)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --level-code: %v\n", err)
+			os.Exit(2)
+		}
+		if code.Level > code.MaxLevel && !*endless {
+			fmt.Fprintf(os.Stderr, "Error: --level-code: level %d is only reachable with --endless\n", code.Level)
+			os.Exit(2)
+		}
+	}
+
+	// Settings changed in game are kept between runs when there's somewhere to keep them
+	settingsPath := ""
+	if configDir, err := os.UserConfigDir(); err == nil {
+		settingsPath = filepath.Join(configDir, "gh-dungeons", "settings.json")
+	}
+
+	// Games saved with S go next to the settings, and --continue needs one to resume
+	savePath, err := game.SavePath()
+	if err != nil && *continueGame {
+		fmt.Fprintf(os.Stderr, "Error: --continue: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Keys rebound in keys.json, which is optional like the settings
+	keyMapPath, _ := game.KeyMapPath()
+
+	exploredDir := ""
+	if *rememberMap {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --remember-map: %v\n", err)
+			os.Exit(1)
+		}
+		exploredDir = filepath.Join(configDir, "gh-dungeons", "explored")
+	}
+
+	opts := []game.GameOption{
+		game.WithMergeMode(*mergeMode),
+		game.WithMergeForce(*mergeForce),
+		game.WithNoDiagonals(*noDiagonals),
+		game.WithCornerCutting(*cornerCutting),
+		game.WithVisionRadius(*vision),
+		game.WithMemoryDecay(*decay),
+		game.WithSourceDir(sourceDir),
+		game.WithPersistentEnemies(*persistentEnemies),
+		game.WithLeash(*leash),
+		game.WithCodeSmells(*codeSmells),
+		game.WithLevelSummary(*levelSummary),
+		game.WithAStarChase(*astar),
+		game.WithMergeQueue(*mergeQueue),
+		game.WithMergeFireDamage(*mergeFire),
+		game.WithDemoMode(*demo),
+		game.WithPotionBudget(*potionBudget),
+		game.WithPotionHealPercent(*potionHealPercent),
+		game.WithPotionTiers(*potionTiers),
+		game.WithDifficulty(difficultyLevel),
+		game.WithEndless(*endless),
+		game.WithRoomErosion(*roomErosion),
+		game.WithTutorial(*tutorial),
+		game.WithExploredDir(exploredDir),
+		game.WithFullClear(*fullClear),
+		game.WithASCII(*ascii),
+		game.WithCrowdBlocksSight(*crowdBlocksSight),
+		game.WithCITraps(*ciTraps),
+		game.WithAvatar(avatarSymbol),
+		game.WithPlayerColor(playerColor),
+		game.WithNoColor(*noColor),
+		game.WithScreenReader(*screenReader),
+		game.WithMaxLevel(*levels),
+		game.WithStartLevel(*startLevel),
+		game.WithHeadless(*noTTY || *dump || *dumpLevel),
+		game.WithScanOrder(order),
+		game.WithMergeFireChase(*mergeFireChase),
+		game.WithMergeCooldown(*mergeCooldown),
+		game.WithRNGAlgorithm(rngAlgorithm),
+		game.WithEnemyColors(*enemyColors),
+		game.WithSettingsFile(settingsPath),
+		game.WithSaveFile(savePath),
+		game.WithKeyMapFile(keyMapPath),
+		game.WithContinue(*continueGame),
+		game.WithHighScores(true),
+		game.WithRollback(*rollback),
+		game.WithCoop(*coop),
+		game.WithMessageTurns(*messageTurns),
+	}
+	// A theme saved from the settings menu is kept unless --theme is given
+	if setFlags["theme"] {
+		opts = append(opts, game.WithTheme(themeChoice))
+	}
+	// A resumed run keeps the options it was saved with, apart from those given this time
+	if *continueGame {
+		overrides := map[string]game.StateOption{
+			"corner-cutting":      func(gs *game.GameState) { gs.CornerCutting = *cornerCutting },
+			"persistent-enemies":  func(gs *game.GameState) { gs.PersistentEnemies = *persistentEnemies },
+			"merge-queue":         func(gs *game.GameState) { gs.MergeQueue = *mergeQueue },
+			"merge-fire":          func(gs *game.GameState) { gs.MergeFireDamage = *mergeFire },
+			"potion-budget":       func(gs *game.GameState) { gs.PotionBudget = *potionBudget },
+			"endless":             func(gs *game.GameState) { gs.Endless = *endless },
+			"room-erosion":        func(gs *game.GameState) { gs.RoomErosion = *roomErosion },
+			"remember-map":        func(gs *game.GameState) { gs.ExploredDir = exploredDir },
+			"crowd-blocks-sight":  func(gs *game.GameState) { gs.CrowdBlocksSight = *crowdBlocksSight },
+			"avatar":              func(gs *game.GameState) { gs.Avatar = avatarSymbol },
+			"merge-fire-chase":    func(gs *game.GameState) { gs.MergeFireChase = *mergeFireChase },
+			"potion-heal-percent": func(gs *game.GameState) { gs.PotionHealPercent = *potionHealPercent },
+			"potion-tiers":        func(gs *game.GameState) { gs.PotionTiers = *potionTiers },
+			"ci-traps":            func(gs *game.GameState) { gs.CITraps = *ciTraps },
+			"leash":               func(gs *game.GameState) { gs.LeashDistance = *leash },
+			"code-smells":         func(gs *game.GameState) { gs.CodeSmellsEnabled = *codeSmells },
+			"level-summary":       func(gs *game.GameState) { gs.ShowLevelSummary = *levelSummary },
+			"ascii":               func(gs *game.GameState) { gs.ASCII = *ascii },
+			"merge-cooldown":      func(gs *game.GameState) { gs.MergeCooldown = *mergeCooldown },
+			"astar":               func(gs *game.GameState) { gs.AStarChase = *astar },
+			"difficulty":          func(gs *game.GameState) { gs.Difficulty = difficultyLevel },
+			"decay":               func(gs *game.GameState) { gs.MemoryDecay = *decay },
+			"vision":              func(gs *game.GameState) { gs.VisionRadius = *vision },
+			"message-turns":       func(gs *game.GameState) { gs.MessageTurns = *messageTurns },
+		}
+		var given []game.StateOption
+		for name, override := range overrides {
+			if setFlags[name] {
+				given = append(given, override)
+			}
+		}
+		opts = append(opts, game.WithResumeOverrides(given...))
+	}
+	if *levelCode != "" {
+		opts = append(opts, game.WithLevelCode(code))
+	} else if seedSet {
+		opts = append(opts, game.WithSeed(*seed))
+	}
+
+	g, err := game.New(opts...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing game: %v\n", err)
+		if !*noTTY && !*dump && !*dumpLevel {
+			fmt.Fprintln(os.Stderr, "No terminal? Try --no-tty or --dump.")
+		}
 		os.Exit(1)
 	}
 	defer g.Close()
 
+	if *dump || *dumpLevel || *noTTY {
+		run := g.Dump
+		if *dumpLevel {
+			run = g.DumpLevel
+		} else if *noTTY {
+			run = g.RunHeadless
+		}
+		if err := run(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running game: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := g.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running game: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// runValidation checks n random seeds for generator invariant violations,
+// reporting each failing level, and returns the process exit code
+func runValidation(n int, roomErosion float64, rngAlgorithm game.RNGAlgorithm) int {
+	seeds := make([]int64, n)
+	for i := range seeds {
+		seeds[i] = rand.Int63()
+	}
+
+	failures := game.ValidateSeeds(seeds, 80, 24, func(gs *game.GameState) {
+		gs.RoomErosion = roomErosion
+		gs.RNGAlgorithm = rngAlgorithm
+	})
+	for _, f := range failures {
+		fmt.Println(f)
+	}
+	fmt.Printf("Validated %d seeds: %d failing levels\n", n, len(failures))
+	if len(failures) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runCompare prints the first level of two seeds side by side for comparing
+// generator changes, and returns the process exit code
+func runCompare(seedA, seedB string, roomErosion float64, rngAlgorithm game.RNGAlgorithm) int {
+	if seedB == "" {
+		fmt.Fprintln(os.Stderr, "Error: --compare needs two seeds, e.g. --compare 123 456")
+		return 2
+	}
+	var seeds [2]int64
+	for i, s := range []string{seedA, seedB} {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --compare: invalid seed %q\n", s)
+			return 2
+		}
+		seeds[i] = seed
+	}
+
+	err := game.CompareSeeds(os.Stdout, seeds[0], seeds[1], game.CompareWidth, game.CompareHeight, func(gs *game.GameState) {
+		gs.RoomErosion = roomErosion
+		gs.RNGAlgorithm = rngAlgorithm
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --compare: %v\n", err)
+		return 1
+	}
+	return 0
+}